@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -258,3 +259,18 @@ func QuotaName(origin string) string {
 	}
 	return fmt.Sprintf("\"%s\"", origin)
 }
+
+// QuotaString 转换为SQL字符串常量，单引号转义
+func QuotaString(origin string) string {
+	return "'" + strings.ReplaceAll(origin, "'", "''") + "'"
+}
+
+// SortedKeys 返回按字典序排列的map键，用于保证遍历顺序稳定
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
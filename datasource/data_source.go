@@ -84,9 +84,65 @@ func (ds *DS) GetTableUniqueKeys(ctx context.Context, dbName string, schemaName
 	return ds.Operator.GetTableUniqueKeys(ctx, dbName, schemaName, tables)
 }
 
-// ExecuteDDL 执行DDL
-func (ds *DS) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field) (ddlSQL string, err error) {
-	return ds.Operator.ExecuteDDL(ctx, dbName, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap)
+// GetTableIndexes 查询普通索引(不含主键及唯一约束)
+func (ds *DS) GetTableIndexes(ctx context.Context, dbName string, schemaName string, tables []string) (indexInfo map[string][]*dboperator.IndexInfo, err error) {
+	return ds.Operator.GetTableIndexes(ctx, dbName, schemaName, tables)
+}
+
+// CreateIndexes 创建索引，不支持的部分索引/函数索引将跳过并告警
+func (ds *DS) CreateIndexes(ctx context.Context, dbName, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (ddlSQL string, err error) {
+	return ds.Operator.CreateIndexes(ctx, dbName, schemaName, indexesMap)
+}
+
+// GetTableForeignKeys 查询外键
+func (ds *DS) GetTableForeignKeys(ctx context.Context, dbName string, schemaName string, tables []string) (foreignKeyInfo map[string][]*dboperator.ForeignKeyInfo, err error) {
+	return ds.Operator.GetTableForeignKeys(ctx, dbName, schemaName, tables)
+}
+
+// GetTableCheckConstraints 查询检查约束，表达式为源库写法
+func (ds *DS) GetTableCheckConstraints(ctx context.Context, dbName string, schemaName string, tables []string) (checkInfo map[string][]*dboperator.CheckInfo, err error) {
+	return ds.Operator.GetTableCheckConstraints(ctx, dbName, schemaName, tables)
+}
+
+// ExecuteDDL 执行DDL, tableCommentMap为表注释, foreignKeysMap为外键，表按外键依赖顺序创建，外键在全部表创建后追加,
+// checksMap为检查约束，表达式需已归一化，见 dboperator.NormalizeCheckExpression
+func (ds *DS) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (ddlSQL string, err error) {
+	return ds.Operator.ExecuteDDL(ctx, dbName, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap)
+}
+
+// ExecuteAlterations 按修改项修改已存在的表，修改项由 dboperator.PlanAlterations 生成，数据库不支持的修改跳过并告警
+func (ds *DS) ExecuteAlterations(ctx context.Context, dbName, schemaName string, alterations []*dboperator.TableAlteration) (ddlSQL string, err error) {
+	return ds.Operator.ExecuteAlterations(ctx, dbName, schemaName, alterations)
+}
+
+// RenderDDL 生成建表、注释及外键语句而不执行，参数同 ExecuteDDL
+func (ds *DS) RenderDDL(ctx context.Context, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (statements []string) {
+	return ds.Operator.RenderDDL(ctx, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap)
+}
+
+// RenderIndexes 生成创建索引语句而不执行
+func (ds *DS) RenderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (statements []string) {
+	return ds.Operator.RenderIndexes(ctx, schemaName, indexesMap)
+}
+
+// RenderAlterations 生成修改已存在表的语句而不执行
+func (ds *DS) RenderAlterations(ctx context.Context, schemaName string, alterations []*dboperator.TableAlteration) (statements []string) {
+	return ds.Operator.RenderAlterations(ctx, schemaName, alterations)
+}
+
+// RenderViews 生成创建视图语句而不执行
+func (ds *DS) RenderViews(ctx context.Context, schemaName string, views []*dboperator.ViewInfo) (statements []string) {
+	return ds.Operator.RenderViews(ctx, schemaName, views)
+}
+
+// GetViewsUnderSchema 获取模式下视图及物化视图，定义为源库写法
+func (ds *DS) GetViewsUnderSchema(ctx context.Context, dbName string, schemas []string) (schemaViewMap map[string][]*dboperator.ViewInfo, err error) {
+	return ds.Operator.GetViewsUnderSchema(ctx, dbName, schemas)
+}
+
+// CreateViews 按顺序创建视图，定义需已归一化，见 dboperator.NormalizeViewDefinition
+func (ds *DS) CreateViews(ctx context.Context, dbName, schemaName string, views []*dboperator.ViewInfo) (ddlSQL string, err error) {
+	return ds.Operator.CreateViews(ctx, dbName, schemaName, views)
 }
 
 // GetDataBySQL 执行自定义
@@ -99,6 +155,11 @@ func (ds *DS) GetTableData(ctx context.Context, dbName, schemaName, tableName st
 	return ds.Operator.GetTableData(ctx, dbName, schemaName, tableName, pageInfo)
 }
 
+// GetSchema 获取模式快照，包含模式下全部表的列、约束、索引及视图
+func (ds *DS) GetSchema(ctx context.Context, dbName, schemaName string) (schema *dboperator.Schema, err error) {
+	return ds.Operator.GetSchema(ctx, dbName, schemaName)
+}
+
 func LoadDS(dataSourceType dbx.DBType) (ds *DS, err error) {
 	var ok bool
 	ds, ok = dsMap[dataSourceType]
@@ -113,7 +174,7 @@ func RegisterDS(dataSourceType dbx.DBType, operator dboperator.IOperator) error
 	var ok bool
 	_, ok = dsMap[dataSourceType]
 	if ok {
-		return fmt.Errorf("db_type %s is already registered")
+		return fmt.Errorf("db_type %s is already registered", dataSourceType)
 	}
 	dsMap[dataSourceType] = &DS{
 		Operator: operator,
@@ -2,82 +2,174 @@ package datasource
 
 import (
 	"context"
+	"os"
+	"strings"
 
 	"github.com/jasonlabz/dbutil/dboperator"
 	"github.com/jasonlabz/dbutil/dbx"
 	"github.com/jasonlabz/dbutil/log"
 )
 
-func GenTable(ctx context.Context, source dbx.Config, target dbx.Config, sourceSchema, targetSchema string, tableNames []string) (string, error) {
+// GenOptions 生成表结构的可选配置
+type GenOptions struct {
+	TypeRules   *dboperator.TypeRuleSet // 类型映射规则，优先于各数据库内置的类型映射
+	ReportFile  string                  // 类型兼容性报告文件，非空时以json格式写入
+	MappingFile string                  // 名称对应关系文件，非空时以json格式写入
+	Rename      *dboperator.RenameSpec  // 表名、列名重命名规则，键、约束及索引随之改写
+	Filter      *dboperator.TableFilter // 表过滤条件，与 tableNames 同时配置时需同时满足
+	// Migrate 目标库已存在的表按差异生成 alter 语句修改，未开启时已存在的表保持不变(create if not exists)
+	Migrate bool
+	// AllowDestructive 迁移时执行删除列、删除键及收窄类型等可能丢失数据的修改，未开启时跳过并告警
+	AllowDestructive bool
+	// SourceSnapshot 源库模式快照文件，非空时以快照代替源库，不连接源库
+	SourceSnapshot string
+	// SourceDDL 源库的DDL脚本文件，非空时解析脚本代替源库，不连接源库，脚本的写法由源库 DBType 决定
+	SourceDDL string
+	// TargetSnapshot 目标库模式快照文件，仅 RenderTable 迁移时使用，作为目标库中已存在的表
+	TargetSnapshot string
+}
+
+// GenTable 在目标库创建源库模式下的表，同时返回各字段类型转换的兼容性报告，
+// 以及表、列、约束名称在目标库中的对应关系(重命名、目标库折叠大小写或缩短超长名称)。
+// options.SourceDDL 非空时以DDL脚本代替源库，source 仅需配置 DBType
+func GenTable(ctx context.Context, source dbx.Config, target dbx.Config, sourceSchema, targetSchema string, tableNames []string, options *GenOptions) (string, *dboperator.CompatibilityReport, *dboperator.NameMapping, error) {
 	logger := log.GetLogger(ctx)
-	sourceDBType := source.DBType
-	source.DBName = "source"
+	if options == nil {
+		options = &GenOptions{}
+	}
 	targetDBType := target.DBType
 	target.DBName = "target"
-
-	checkMap := map[string]bool{}
-	for _, name := range tableNames {
-		checkMap[name] = true
+	definitions, report, mapping, err := prepareTables(ctx, source, targetDBType, sourceSchema, tableNames, options)
+	if err != nil || definitions == nil {
+		return "", report, mapping, err
 	}
-	sourceDS, err := LoadDS(sourceDBType)
+
+	targetDS, err := LoadDS(targetDBType)
 	if err != nil {
 		logger.WithError(err).Error(err.Error())
-		return "", err
+		return "", nil, nil, err
 	}
-	err = sourceDS.Open(&source)
+	err = targetDS.Open(&target)
 	if err != nil {
 		logger.WithError(err).Error("数据库连接失败")
-		return "", err
-	}
-	tableMap, err := sourceDS.GetTablesUnderSchema(ctx, source.DBName, []string{sourceSchema})
-	if err != nil {
-		logger.WithError(err).Error("数据库查询失败")
-		return "", err
+		return "", nil, nil, err
 	}
 
-	tables := make([]string, 0)
-	for _, tableInfos := range tableMap {
-		for _, tableInfo := range tableInfos.TableInfoList {
-			tables = append(tables, tableInfo.TableName)
+	_ = targetDS.CreateSchema(ctx, target.DBName, targetSchema, "")
+
+	var alterSQL string
+	if options.Migrate {
+		current, schemaErr := targetDS.GetSchema(ctx, target.DBName, dboperator.GetIdentifierPolicy(targetDBType).Fold(targetSchema))
+		if schemaErr != nil {
+			logger.WithError(schemaErr).Error("GetSchema error")
+			return "", nil, nil, schemaErr
+		}
+		alterations := definitions.migrate(ctx, current, targetDBType, targetDS, options.AllowDestructive)
+		alterSQL, err = targetDS.ExecuteAlterations(ctx, target.DBName, targetSchema, alterations)
+		if err != nil {
+			logger.WithError(err).Error("migrate tables error")
+			return "", nil, nil, err
 		}
 	}
-	columnsUnderTables, getColumnErr := sourceDS.GetColumnsUnderTable(ctx, source.DBName, sourceSchema, tables)
-	if getColumnErr != nil {
-		logger.WithError(getColumnErr).Error("get table column error")
-		return "", err
+	var ddlSQL string
+	if len(definitions.fields) > 0 {
+		ddlSQL, err = targetDS.ExecuteDDL(ctx, target.DBName, targetSchema, definitions.primaryKeys, definitions.uniqueKeys,
+			definitions.fields, definitions.comments, definitions.foreignKeys, definitions.checks)
+		if err != nil {
+			logger.WithError(err).Error("execute ddl error")
+			return "", nil, nil, err
+		}
 	}
 
-	tablePrimeKeys, err := sourceDS.GetTablePrimeKeys(ctx, source.DBName, sourceSchema, tables)
+	indexSQL, err := targetDS.CreateIndexes(ctx, target.DBName, targetSchema, definitions.indexes)
 	if err != nil {
-		logger.WithError(err).Error("GetTablePrimeKeys error")
-		return "", err
+		logger.WithError(err).Error("create indexes error")
+		return "", nil, nil, err
 	}
+	return alterSQL + ddlSQL + indexSQL, report, mapping, nil
+}
 
-	tableUniqueKeys, err := sourceDS.GetTableUniqueKeys(ctx, source.DBName, sourceSchema, tables)
-	if err != nil {
-		logger.WithError(err).Error("GetTableUniqueKeys error")
-		return "", err
+// RenderTable 生成在 targetDBType 目标库创建源库模式下表的DDL脚本，不连接目标库，供预览或交由DBA审核后执行；
+// options.SourceSnapshot 或 options.SourceDDL 非空时以快照或DDL脚本代替源库，此时无需源库连接。迁移时以 options.TargetSnapshot 作为目标库现状，
+// 未配置时按目标库为空生成建表语句。兼容性报告及名称对应关系同 GenTable
+func RenderTable(ctx context.Context, source dbx.Config, targetDBType dbx.DBType, sourceSchema, targetSchema string, tableNames []string, options *GenOptions) (string, *dboperator.CompatibilityReport, *dboperator.NameMapping, error) {
+	logger := log.GetLogger(ctx)
+	if options == nil {
+		options = &GenOptions{}
+	}
+	definitions, report, mapping, err := prepareTables(ctx, source, targetDBType, sourceSchema, tableNames, options)
+	if err != nil || definitions == nil {
+		return "", report, mapping, err
 	}
-
 	targetDS, err := LoadDS(targetDBType)
 	if err != nil {
 		logger.WithError(err).Error(err.Error())
-		return "", err
+		return "", nil, nil, err
 	}
 
-	err = targetDS.Open(&target)
-	if err != nil {
-		logger.WithError(err).Error("数据库连接失败")
-		return "", err
+	statements := make([]string, 0)
+	if options.Migrate && options.TargetSnapshot == "" {
+		logger.Warn("no target snapshot for migration, render create statements for all tables")
+	} else if options.Migrate {
+		current, loadErr := dboperator.LoadSchema(options.TargetSnapshot)
+		if loadErr != nil {
+			logger.WithError(loadErr).Error("load schema snapshot error")
+			return "", nil, nil, loadErr
+		}
+		alterations := definitions.migrate(ctx, current, targetDBType, targetDS, options.AllowDestructive)
+		statements = append(statements, targetDS.RenderAlterations(ctx, targetSchema, alterations)...)
 	}
+	if len(definitions.fields) > 0 {
+		statements = append(statements, targetDS.RenderDDL(ctx, targetSchema, definitions.primaryKeys, definitions.uniqueKeys,
+			definitions.fields, definitions.comments, definitions.foreignKeys, definitions.checks)...)
+	}
+	statements = append(statements, targetDS.RenderIndexes(ctx, targetSchema, definitions.indexes)...)
+	return dboperator.Script(statements), report, mapping, nil
+}
 
-	_ = targetDS.CreateSchema(ctx, target.DBName, targetSchema, "")
+// prepareTables 读取源库的表并转换为目标库的表定义，写入兼容性报告及名称对应关系文件，已按重命名规则改写；
+// 没有满足条件的表时 definitions 为 nil
+func prepareTables(ctx context.Context, source dbx.Config, targetDBType dbx.DBType, sourceSchema string, tableNames []string,
+	options *GenOptions) (definitions *tableDefinitions, report *dboperator.CompatibilityReport, mapping *dboperator.NameMapping, err error) {
+	logger := log.GetLogger(ctx)
+	if options.TypeRules != nil {
+		if err = options.TypeRules.Compile(); err != nil {
+			logger.WithError(err).Error("invalid type rules")
+			return
+		}
+	}
+	if options.Rename != nil {
+		if err = options.Rename.Compile(); err != nil {
+			logger.WithError(err).Error("invalid rename spec")
+			return
+		}
+	}
+	src, err := loadSourceTables(ctx, source, sourceSchema, tableNames, options)
+	if err != nil {
+		return
+	}
+	sourceDBType := src.dbType
+	if len(src.tables) == 0 {
+		logger.Warn("no table under schema %s matches the filter", src.schemaName)
+		return nil, dboperator.NewCompatibilityReport(sourceDBType, targetDBType), dboperator.NewNameMapping(), nil
+	}
+	sourceDS, err := LoadDS(sourceDBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return
+	}
+	targetDS, err := LoadDS(targetDBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return
+	}
 
 	fieldsMap := make(map[string][]*dboperator.Field)
+	report = dboperator.NewCompatibilityReport(sourceDBType, targetDBType)
 
-	for _, info := range columnsUnderTables {
+	for _, info := range src.columns {
 		tableName := info.TableName
-		if len(checkMap) > 0 && !checkMap[tableName] {
+		if _, ok := src.comments[tableName]; !ok {
 			continue
 		}
 
@@ -87,15 +179,455 @@ func GenTable(ctx context.Context, source dbx.Config, target dbx.Config, sourceS
 			if field == nil {
 				continue
 			}
+			options.TypeRules.Apply(&dboperator.TypeRuleColumn{
+				SourceDBType: sourceDBType,
+				TargetDBType: targetDBType,
+				SchemaName:   src.schemaName,
+				TableName:    tableName,
+				ColumnName:   columnInfo.ColumnName,
+				DataType:     columnInfo.DataType,
+			}, field)
 			field.ColumnName = columnInfo.ColumnName
+			field.ISNullable = columnInfo.IsNullable
+			field.DefaultValue = dboperator.NormalizeDefaultValue(columnInfo.DefaultValue)
+			field.Comment = columnInfo.Comment
+			if columnInfo.IsAutoIncrement {
+				// 未指定小数位的定点数(如oracle的NUMBER)按整数处理
+				if field.Type == dboperator.FLOAT64 && field.IsFixedNumber && field.Scale <= 0 {
+					field.Type, field.IsFixedNumber = dboperator.INT64, false
+				}
+				if dboperator.IsIntegerField(field) {
+					field.IsAutoIncrement = true
+					field.AutoIncrementStart = max(columnInfo.NextValue, 1)
+					field.DefaultValue = ""
+				} else {
+					logger.Warn("skip auto increment of %s.%s, type %s is not integer", tableName, columnInfo.ColumnName, columnInfo.DataType)
+				}
+			}
+			if dropped := report.AnalyzeDefault(tableName, field); dropped != nil {
+				logger.Warn("drop default %s of %s.%s, %s", dropped.DefaultValue, tableName, columnInfo.ColumnName, dropped.Reason)
+			}
+			report.Analyze(targetDS, tableName, columnInfo.DataType, field)
+			// 列未指定字符集或与表相同时使用表的默认字符集、排序规则
+			field.Charset, field.Collation = columnInfo.Charset, columnInfo.Collation
+			if field.Charset == "" || strings.EqualFold(field.Charset, src.charsets[tableName]) {
+				field.Charset = src.charsets[tableName]
+				if field.Collation == "" {
+					field.Collation = src.collations[tableName]
+				}
+			}
+			report.AnalyzeCollation(tableName, field)
 			fields = append(fields, field)
 		}
 		fieldsMap[tableName] = fields
 	}
-	ddlSQL, err := targetDS.ExecuteDDL(ctx, target.DBName, targetSchema, tablePrimeKeys, tableUniqueKeys, fieldsMap)
+	if lossy, unsupported := report.Count(dboperator.CompatibilityLossy), report.Count(dboperator.CompatibilityUnsupported); lossy+unsupported > 0 {
+		logger.Warn("%d columns are lossy and %d columns are unsupported from %s to %s", lossy, unsupported, sourceDBType, targetDBType)
+	}
+	if lossy := report.CountCollations(dboperator.CompatibilityLossy); lossy > 0 {
+		logger.Warn("%d columns lose collation semantics from %s to %s", lossy, sourceDBType, targetDBType)
+	}
+	if options.ReportFile != "" {
+		err = report.WriteJSON(options.ReportFile)
+		if err != nil {
+			logger.WithError(err).Error("write compatibility report error")
+			return nil, nil, nil, err
+		}
+	}
+	foreignKeysMap := make(map[string][]*dboperator.ForeignKeyInfo)
+	for tableName, foreignKeys := range src.foreignKeys {
+		if _, ok := fieldsMap[tableName]; !ok {
+			continue
+		}
+		for _, foreignKey := range foreignKeys {
+			if _, ok := fieldsMap[foreignKey.RefTableName]; !ok || !strings.EqualFold(foreignKey.RefSchemaName, src.schemaName) {
+				logger.Warn("skip foreign key %s on %s, referenced table %s.%s is not generated",
+					foreignKey.ConstraintName, tableName, foreignKey.RefSchemaName, foreignKey.RefTableName)
+				continue
+			}
+			foreignKeysMap[tableName] = append(foreignKeysMap[tableName], foreignKey)
+		}
+	}
+	checksMap := make(map[string][]*dboperator.CheckInfo)
+	for tableName, checks := range src.checks {
+		fields, ok := fieldsMap[tableName]
+		if !ok {
+			continue
+		}
+		columns := make([]string, 0, len(fields))
+		for _, field := range fields {
+			columns = append(columns, field.ColumnName)
+		}
+		for _, check := range checks {
+			expression, normalizeErr := dboperator.NormalizeCheckExpression(check.Expression, columns)
+			if normalizeErr != nil {
+				logger.Warn("skip check constraint %s on %s, expression [%s] cannot be translated: %s",
+					check.ConstraintName, tableName, check.Expression, normalizeErr.Error())
+				continue
+			}
+			checksMap[tableName] = append(checksMap[tableName], &dboperator.CheckInfo{
+				ConstraintName: check.ConstraintName,
+				Expression:     expression,
+			})
+		}
+	}
+	indexesMap := make(map[string][]*dboperator.IndexInfo)
+	for tableName, indexes := range src.indexes {
+		if _, ok := fieldsMap[tableName]; ok {
+			indexesMap[tableName] = indexes
+		}
+	}
+	definitions = &tableDefinitions{
+		primaryKeys: src.primaryKeys,
+		uniqueKeys:  src.uniqueKeys,
+		fields:      fieldsMap,
+		comments:    src.comments,
+		foreignKeys: foreignKeysMap,
+		checks:      checksMap,
+		indexes:     indexesMap,
+	}
+	mapping = definitions.nameMapping(targetDBType, options.Rename)
+	if options.MappingFile != "" {
+		err = mapping.WriteJSON(options.MappingFile)
+		if err != nil {
+			logger.WithError(err).Error("write name mapping error")
+			return nil, nil, nil, err
+		}
+	}
+	if options.Rename != nil {
+		definitions, err = definitions.rename(ctx, options.Rename)
+		if err != nil {
+			logger.WithError(err).Error("rename tables error")
+			return nil, nil, nil, err
+		}
+	}
+	return
+}
+
+// SnapshotSchema 读取源库模式快照，可经 dboperator.Schema.WriteFile 保存为json或yaml
+func SnapshotSchema(ctx context.Context, source dbx.Config, schemaName string) (*dboperator.Schema, error) {
+	return inspectSchema(ctx, source, "source", schemaName)
+}
+
+// GenModel 由源库模式下的表生成带 gorm 标签的Go结构体源码，表的选取及 options.SourceSnapshot、options.SourceDDL、
+// options.Filter 同 GenTable，其余选项不生效
+func GenModel(ctx context.Context, source dbx.Config, sourceSchema string, tableNames []string, options *GenOptions, modelOptions *dboperator.ModelOptions) ([]byte, error) {
+	logger := log.GetLogger(ctx)
+	if options == nil {
+		options = &GenOptions{}
+	}
+	src, err := loadSourceTables(ctx, source, sourceSchema, tableNames, options)
+	if err != nil {
+		return nil, err
+	}
+	sourceDS, err := LoadDS(src.dbType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	models, err := dboperator.GenerateModels(src.schemaTables(), sourceDS, modelOptions)
+	if err != nil {
+		logger.WithError(err).Error("generate models error")
+		return nil, err
+	}
+	return models, nil
+}
+
+// ExportOptions 导出表结构定义的文件，为空的不导出
+type ExportOptions struct {
+	JSONSchemaFile string                   // JSON Schema(draft 2020-12)，.json 以json格式写入，其余以yaml格式写入
+	OpenAPIFile    string                   // OpenAPI 3.0 组件模式，格式同上
+	ProtoFile      string                   // proto3 消息
+	ProtoOptions   *dboperator.ProtoOptions // proto3 的包名等
+	MarkdownFile   string                   // Markdown 数据字典
+	HTMLFile       string                   // 自包含的 HTML 数据字典
+	DiagramFile    string                   // ER 图
+	DiagramFormat  dboperator.DiagramFormat // ER 图的格式，为空时按 DiagramFile 的扩展名推断
+}
+
+// ExportSchema 将源库模式下的表导出为 JSON Schema、OpenAPI 组件模式及 proto3 消息，类型、可空、长度及注释随之导出；
+// 或生成含列、键、索引的 Markdown、HTML 数据字典，外键链接到被引用表，以及 Mermaid、PlantUML、DOT 格式的 ER 图。
+// 表的选取及 options.SourceSnapshot、options.SourceDDL、options.Filter 同 GenTable，其余选项不生效
+func ExportSchema(ctx context.Context, source dbx.Config, sourceSchema string, tableNames []string, options *GenOptions, exportOptions *ExportOptions) error {
+	logger := log.GetLogger(ctx)
+	if options == nil {
+		options = &GenOptions{}
+	}
+	if exportOptions == nil {
+		exportOptions = &ExportOptions{}
+	}
+	src, err := loadSourceTables(ctx, source, sourceSchema, tableNames, options)
 	if err != nil {
-		logger.WithError(err).Error("execute ddl error")
+		return err
+	}
+	sourceDS, err := LoadDS(src.dbType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return err
+	}
+	tables := src.schemaTables()
+	if exportOptions.JSONSchemaFile != "" {
+		if err = dboperator.GenerateJSONSchema(tables, sourceDS).WriteFile(exportOptions.JSONSchemaFile); err != nil {
+			logger.WithError(err).Error("write json schema error: " + exportOptions.JSONSchemaFile)
+			return err
+		}
+	}
+	if exportOptions.OpenAPIFile != "" {
+		if err = dboperator.GenerateOpenAPI(tables, sourceDS, src.schemaName).WriteFile(exportOptions.OpenAPIFile); err != nil {
+			logger.WithError(err).Error("write openapi error: " + exportOptions.OpenAPIFile)
+			return err
+		}
+	}
+	if exportOptions.ProtoFile != "" {
+		if err = os.WriteFile(exportOptions.ProtoFile, dboperator.GenerateProto(tables, sourceDS, exportOptions.ProtoOptions), 0644); err != nil {
+			logger.WithError(err).Error("write proto error: " + exportOptions.ProtoFile)
+			return err
+		}
+	}
+	schema := &dboperator.Schema{Version: dboperator.SchemaVersion, DBType: src.dbType, Name: src.schemaName, Tables: tables}
+	if exportOptions.MarkdownFile != "" {
+		if err = os.WriteFile(exportOptions.MarkdownFile, dboperator.GenerateMarkdown(schema), 0644); err != nil {
+			logger.WithError(err).Error("write markdown error: " + exportOptions.MarkdownFile)
+			return err
+		}
+	}
+	if exportOptions.HTMLFile != "" {
+		if err = os.WriteFile(exportOptions.HTMLFile, dboperator.GenerateHTML(schema), 0644); err != nil {
+			logger.WithError(err).Error("write html error: " + exportOptions.HTMLFile)
+			return err
+		}
+	}
+	if exportOptions.DiagramFile != "" {
+		format := exportOptions.DiagramFormat
+		if format == "" {
+			format = dboperator.DiagramFormatOf(exportOptions.DiagramFile)
+		}
+		diagram, diagramErr := dboperator.GenerateDiagram(schema, format, options.Filter)
+		if diagramErr != nil {
+			logger.WithError(diagramErr).Error("generate diagram error")
+			return diagramErr
+		}
+		if err = os.WriteFile(exportOptions.DiagramFile, diagram, 0644); err != nil {
+			logger.WithError(err).Error("write diagram error: " + exportOptions.DiagramFile)
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffOptions 比较模式的可选配置
+type DiffOptions struct {
+	SourceSnapshot string // 源库模式快照文件，非空时不连接源库
+	TargetSnapshot string // 目标库模式快照文件，非空时不连接目标库
+}
+
+// DiffSchema 比较源库与目标库模式下的表、列、类型、可空、键及索引，两者可为不同数据库
+func DiffSchema(ctx context.Context, source dbx.Config, target dbx.Config, sourceSchema, targetSchema string, options *DiffOptions) (*dboperator.SchemaDiff, error) {
+	logger := log.GetLogger(ctx)
+	if options == nil {
+		options = &DiffOptions{}
+	}
+	from, err := loadSchema(ctx, source, "source", sourceSchema, options.SourceSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	to, err := loadSchema(ctx, target, "target", targetSchema, options.TargetSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	fromDS, err := LoadDS(from.DBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	toDS, err := LoadDS(to.DBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	return dboperator.DiffSchema(from, to, fromDS, toDS), nil
+}
+
+// loadSchema 快照文件非空时读取快照，否则连接数据库读取
+func loadSchema(ctx context.Context, config dbx.Config, dbName, schemaName, snapshotFile string) (*dboperator.Schema, error) {
+	if snapshotFile == "" {
+		return inspectSchema(ctx, config, dbName, schemaName)
+	}
+	schema, err := dboperator.LoadSchema(snapshotFile)
+	if err != nil {
+		log.GetLogger(ctx).WithError(err).Error("load schema snapshot error")
+		return nil, err
+	}
+	return schema, nil
+}
+
+func inspectSchema(ctx context.Context, config dbx.Config, dbName, schemaName string) (*dboperator.Schema, error) {
+	logger := log.GetLogger(ctx)
+	config.DBName = dbName
+	ds, err := LoadDS(config.DBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	err = ds.Open(&config)
+	if err != nil {
+		logger.WithError(err).Error("数据库连接失败")
+		return nil, err
+	}
+	schema, err := ds.GetSchema(ctx, config.DBName, schemaName)
+	if err != nil {
+		logger.WithError(err).Error("GetSchema error")
+		return nil, err
+	}
+	return schema, nil
+}
+
+// GenView 在目标库创建源库模式下的视图及物化视图，仅创建定义为通用SQL的视图，其余跳过并告警；
+// options.Rename 非空时按其改写视图引用的表名及列名
+func GenView(ctx context.Context, source dbx.Config, target dbx.Config, sourceSchema, targetSchema string, viewNames []string,
+	options *GenOptions) (string, error) {
+	logger := log.GetLogger(ctx)
+	if options == nil {
+		options = &GenOptions{}
+	}
+	views, err := prepareViews(ctx, source, sourceSchema, targetSchema, viewNames, options)
+	if err != nil || len(views) == 0 {
+		return "", err
+	}
+
+	targetDBType := target.DBType
+	target.DBName = "target"
+	targetDS, err := LoadDS(targetDBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return "", err
+	}
+	err = targetDS.Open(&target)
+	if err != nil {
+		logger.WithError(err).Error("数据库连接失败")
+		return "", err
+	}
+	ddlSQL, err := targetDS.CreateViews(ctx, target.DBName, targetSchema, views)
+	if err != nil {
+		logger.WithError(err).Error("create views error")
 		return "", err
 	}
 	return ddlSQL, nil
 }
+
+// RenderView 生成创建视图的脚本而不连接目标库，视图的选取及 options.SourceSnapshot、options.Rename 同 GenView
+func RenderView(ctx context.Context, source dbx.Config, targetDBType dbx.DBType, sourceSchema, targetSchema string, viewNames []string,
+	options *GenOptions) (string, error) {
+	logger := log.GetLogger(ctx)
+	if options == nil {
+		options = &GenOptions{}
+	}
+	views, err := prepareViews(ctx, source, sourceSchema, targetSchema, viewNames, options)
+	if err != nil || len(views) == 0 {
+		return "", err
+	}
+	targetDS, err := LoadDS(targetDBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return "", err
+	}
+	return dboperator.Script(targetDS.RenderViews(ctx, targetSchema, views)), nil
+}
+
+// prepareViews 读取源库模式下的视图并归一化定义，按依赖排序，被引用的视图在前；
+// options.SourceSnapshot 非空时读取快照而不连接源库
+func prepareViews(ctx context.Context, source dbx.Config, sourceSchema, targetSchema string, viewNames []string,
+	options *GenOptions) ([]*dboperator.ViewInfo, error) {
+	logger := log.GetLogger(ctx)
+	if options.Rename != nil {
+		if err := options.Rename.Compile(); err != nil {
+			logger.WithError(err).Error("invalid rename spec")
+			return nil, err
+		}
+	}
+	sourceDBType := source.DBType
+	sourceViews := make([]*dboperator.ViewInfo, 0)
+	if options.SourceSnapshot != "" {
+		schema, err := dboperator.LoadSchema(options.SourceSnapshot)
+		if err != nil {
+			logger.WithError(err).Error("load schema snapshot error")
+			return nil, err
+		}
+		if schema.DBType != "" {
+			sourceDBType = schema.DBType
+		}
+		for _, view := range schema.Views {
+			sourceViews = append(sourceViews, &dboperator.ViewInfo{ViewName: view.Name, Definition: view.Definition, IsMaterialized: view.Materialized})
+		}
+	} else {
+		source.DBName = "source"
+		sourceDS, err := LoadDS(sourceDBType)
+		if err != nil {
+			logger.WithError(err).Error(err.Error())
+			return nil, err
+		}
+		err = sourceDS.Open(&source)
+		if err != nil {
+			logger.WithError(err).Error("数据库连接失败")
+			return nil, err
+		}
+		viewMap, err := sourceDS.GetViewsUnderSchema(ctx, source.DBName, []string{sourceSchema})
+		if err != nil {
+			logger.WithError(err).Error("GetViewsUnderSchema error")
+			return nil, err
+		}
+		for _, views := range viewMap {
+			sourceViews = append(sourceViews, views...)
+		}
+	}
+
+	checkMap := map[string]bool{}
+	for _, name := range viewNames {
+		checkMap[name] = true
+	}
+	viewSet := make(map[string]bool)
+	for _, view := range sourceViews {
+		viewSet[view.ViewName] = true
+	}
+	viewInfoMap := make(map[string]*dboperator.ViewInfo)
+	dependsOn := make(map[string][]string)
+	names := make([]string, 0)
+	for _, view := range sourceViews {
+		if len(checkMap) > 0 && !checkMap[view.ViewName] {
+			continue
+		}
+		definition, refTables, normalizeErr := dboperator.NormalizeViewDefinition(view.Definition, sourceDBType, sourceSchema, targetSchema)
+		if normalizeErr != nil {
+			logger.Warn("skip view %s, definition is not portable: %s", view.ViewName, normalizeErr.Error())
+			continue
+		}
+		if options.Rename != nil {
+			// 引用的视图保持原名，仅改写表名
+			refOnlyTables := make([]string, 0, len(refTables))
+			for _, refTable := range refTables {
+				if !viewSet[refTable] {
+					refOnlyTables = append(refOnlyTables, refTable)
+				}
+			}
+			definition = options.Rename.RenameViewDefinition(definition, targetSchema, refOnlyTables)
+		}
+		viewInfoMap[view.ViewName] = &dboperator.ViewInfo{
+			ViewName:       view.ViewName,
+			Definition:     definition,
+			IsMaterialized: view.IsMaterialized,
+		}
+		dependsOn[view.ViewName] = refTables
+		names = append(names, view.ViewName)
+	}
+
+	// 视图间可能相互引用，被引用的视图先创建
+	sorted, cycles := dboperator.SortByDependency(names, dependsOn)
+	for _, cycle := range cycles {
+		logger.Warn("view cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+	views := make([]*dboperator.ViewInfo, 0, len(sorted))
+	for _, name := range sorted {
+		views = append(views, viewInfoMap[name])
+	}
+	return views, nil
+}
@@ -0,0 +1,85 @@
+package datasource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dboperator"
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestGenTableUncompiledFilter(t *testing.T) {
+	dir := t.TempDir()
+	ddlFile := filepath.Join(dir, "source.sql")
+	script := "CREATE TABLE users (id int NOT NULL PRIMARY KEY, name varchar(20));\n" +
+		"CREATE TABLE tmp_x (id int NOT NULL PRIMARY KEY);\n"
+	if err := os.WriteFile(ddlFile, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source := dbx.Config{DBType: dbx.DBTypeMySQL}
+	target := dbx.Config{DBType: dbx.DBTypeSQLite, DSN: filepath.Join(dir, "target.db")}
+	options := &GenOptions{SourceDDL: ddlFile, Filter: &dboperator.TableFilter{Exclude: []string{"tmp_*"}}}
+	ddlSQL, _, _, err := GenTable(context.Background(), source, target, "app", "main", nil, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ddlSQL, "users") || strings.Contains(ddlSQL, "tmp_x") {
+		t.Errorf("unexpected ddl:\n%s", ddlSQL)
+	}
+
+	options.Filter = &dboperator.TableFilter{Exclude: []string{"re:("}}
+	if _, _, _, err = GenTable(context.Background(), source, target, "app", "main", nil, options); err == nil {
+		t.Error("expected error for invalid filter")
+	}
+}
+
+func TestRenderTableUncompiledTypeRules(t *testing.T) {
+	dir := t.TempDir()
+	ddlFile := filepath.Join(dir, "source.sql")
+	if err := os.WriteFile(ddlFile, []byte("CREATE TABLE flags (id int NOT NULL PRIMARY KEY, flag tinyint(1));\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source := dbx.Config{DBType: dbx.DBTypeMySQL}
+	options := &GenOptions{SourceDDL: ddlFile, TypeRules: &dboperator.TypeRuleSet{Rules: []*dboperator.TypeRule{
+		{TypePattern: "tinyint", Column: "flag", TargetType: "smallint"},
+	}}}
+	ddlSQL, _, _, err := RenderTable(context.Background(), source, dbx.DBTypePostgres, "app", "app", nil, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ddlSQL, "flag smallint") {
+		t.Errorf("type rule not applied:\n%s", ddlSQL)
+	}
+
+	options.TypeRules.Rules[0].TypePattern = "("
+	if _, _, _, err = RenderTable(context.Background(), source, dbx.DBTypePostgres, "app", "app", nil, options); err == nil {
+		t.Error("expected error for invalid type rule")
+	}
+}
+
+func TestRenderViewFromSnapshot(t *testing.T) {
+	snapshotFile := filepath.Join(t.TempDir(), "source.json")
+	schema := &dboperator.Schema{Version: dboperator.SchemaVersion, DBType: dbx.DBTypeMySQL, Name: "legacy", Views: []*dboperator.View{
+		{Name: "top_users", Definition: "select id, NAME from active_users where id < 10"},
+		{Name: "active_users", Definition: "select ID, NAME from T_MEMBER where STATUS = 1"},
+	}}
+	if err := schema.WriteFile(snapshotFile); err != nil {
+		t.Fatal(err)
+	}
+	options := &GenOptions{SourceSnapshot: snapshotFile, Rename: &dboperator.RenameSpec{
+		Tables:  dboperator.RenameRules{Rules: []*dboperator.RenameRule{{Pattern: `^T_`, Replacement: ""}}, Case: dboperator.RenameCaseLower},
+		Columns: dboperator.RenameRules{Case: dboperator.RenameCaseLower},
+	}}
+	ddlSQL, err := RenderView(context.Background(), dbx.Config{}, dbx.DBTypePostgres, "legacy", "app", nil, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "create or replace view app.active_users as SELECT id, name FROM app.member WHERE status = 1;\n" +
+		"create or replace view app.top_users as SELECT id, name FROM app.active_users WHERE id < 10;\n"
+	if ddlSQL != expected {
+		t.Errorf("unexpected views:\n%s", ddlSQL)
+	}
+}
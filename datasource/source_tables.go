@@ -0,0 +1,256 @@
+package datasource
+
+import (
+	"context"
+
+	"github.com/jasonlabz/dbutil/core/utils"
+	"github.com/jasonlabz/dbutil/dboperator"
+	"github.com/jasonlabz/dbutil/dbx"
+	"github.com/jasonlabz/dbutil/log"
+)
+
+// sourceTables 源库模式下待生成的表及其列、键、约束、索引，取自源库或模式快照，均以表名为键
+type sourceTables struct {
+	dbType      dbx.DBType
+	schemaName  string
+	tables      []string
+	comments    map[string]string
+	charsets    map[string]string // 表的默认字符集
+	collations  map[string]string // 表的默认排序规则
+	columns     map[string]*dboperator.TableColInfo
+	primaryKeys map[string][]string
+	uniqueKeys  map[string]map[string][]string
+	indexes     map[string][]*dboperator.IndexInfo
+	foreignKeys map[string][]*dboperator.ForeignKeyInfo
+	checks      map[string][]*dboperator.CheckInfo
+}
+
+// loadSourceTables 读取源库模式下满足 tableNames 及过滤条件的表，options.SourceSnapshot 或 options.SourceDDL
+// 非空时读取快照或解析DDL脚本而不连接源库
+func loadSourceTables(ctx context.Context, source dbx.Config, sourceSchema string, tableNames []string, options *GenOptions) (*sourceTables, error) {
+	if options.Filter != nil {
+		if err := options.Filter.Compile(); err != nil {
+			log.GetLogger(ctx).WithError(err).Error("invalid table filter")
+			return nil, err
+		}
+	}
+	checkMap := map[string]bool{}
+	for _, name := range tableNames {
+		checkMap[name] = true
+	}
+	match := func(tableInfo *dboperator.TableInfo) bool {
+		return (len(checkMap) == 0 || checkMap[tableInfo.TableName]) && options.Filter.Match(tableInfo)
+	}
+	if options.SourceSnapshot != "" {
+		schema, err := dboperator.LoadSchema(options.SourceSnapshot)
+		if err != nil {
+			log.GetLogger(ctx).WithError(err).Error("load schema snapshot error")
+			return nil, err
+		}
+		return snapshotTables(schema, match), nil
+	}
+	if options.SourceDDL != "" {
+		schema, err := dboperator.LoadDDL(options.SourceDDL, source.DBType, sourceSchema)
+		if err != nil {
+			log.GetLogger(ctx).WithError(err).Error("parse ddl script error")
+			return nil, err
+		}
+		return snapshotTables(schema, match), nil
+	}
+	return queryTables(ctx, source, sourceSchema, match)
+}
+
+// queryTables 连接源库读取表
+func queryTables(ctx context.Context, source dbx.Config, sourceSchema string, match func(*dboperator.TableInfo) bool) (*sourceTables, error) {
+	logger := log.GetLogger(ctx)
+	source.DBName = "source"
+	sourceDS, err := LoadDS(source.DBType)
+	if err != nil {
+		logger.WithError(err).Error(err.Error())
+		return nil, err
+	}
+	err = sourceDS.Open(&source)
+	if err != nil {
+		logger.WithError(err).Error("数据库连接失败")
+		return nil, err
+	}
+	tableMap, err := sourceDS.GetTablesUnderSchema(ctx, source.DBName, []string{sourceSchema})
+	if err != nil {
+		logger.WithError(err).Error("数据库查询失败")
+		return nil, err
+	}
+
+	src := &sourceTables{dbType: source.DBType, schemaName: sourceSchema, comments: make(map[string]string),
+		charsets: make(map[string]string), collations: make(map[string]string)}
+	for _, tableInfos := range tableMap {
+		for _, tableInfo := range tableInfos.TableInfoList {
+			if !match(tableInfo) {
+				continue
+			}
+			src.tables = append(src.tables, tableInfo.TableName)
+			src.comments[tableInfo.TableName] = tableInfo.Comment
+			src.charsets[tableInfo.TableName] = tableInfo.Charset
+			src.collations[tableInfo.TableName] = tableInfo.Collation
+		}
+	}
+	if len(src.tables) == 0 {
+		return src, nil
+	}
+	src.columns, err = sourceDS.GetColumnsUnderTable(ctx, source.DBName, sourceSchema, src.tables)
+	if err != nil {
+		logger.WithError(err).Error("get table column error")
+		return nil, err
+	}
+	src.primaryKeys, err = sourceDS.GetTablePrimeKeys(ctx, source.DBName, sourceSchema, src.tables)
+	if err != nil {
+		logger.WithError(err).Error("GetTablePrimeKeys error")
+		return nil, err
+	}
+	src.uniqueKeys, err = sourceDS.GetTableUniqueKeys(ctx, source.DBName, sourceSchema, src.tables)
+	if err != nil {
+		logger.WithError(err).Error("GetTableUniqueKeys error")
+		return nil, err
+	}
+	src.indexes, err = sourceDS.GetTableIndexes(ctx, source.DBName, sourceSchema, src.tables)
+	if err != nil {
+		logger.WithError(err).Error("GetTableIndexes error")
+		return nil, err
+	}
+	src.foreignKeys, err = sourceDS.GetTableForeignKeys(ctx, source.DBName, sourceSchema, src.tables)
+	if err != nil {
+		logger.WithError(err).Error("GetTableForeignKeys error")
+		return nil, err
+	}
+	src.checks, err = sourceDS.GetTableCheckConstraints(ctx, source.DBName, sourceSchema, src.tables)
+	if err != nil {
+		logger.WithError(err).Error("GetTableCheckConstraints error")
+		return nil, err
+	}
+	return src, nil
+}
+
+// snapshotTables 由模式快照(或DDL脚本解析的快照)读取表，快照中没有行数，不按行数过滤
+func snapshotTables(schema *dboperator.Schema, match func(*dboperator.TableInfo) bool) *sourceTables {
+	src := &sourceTables{
+		dbType:      schema.DBType,
+		schemaName:  schema.Name,
+		comments:    make(map[string]string),
+		charsets:    make(map[string]string),
+		collations:  make(map[string]string),
+		columns:     make(map[string]*dboperator.TableColInfo),
+		primaryKeys: make(map[string][]string),
+		uniqueKeys:  make(map[string]map[string][]string),
+		indexes:     make(map[string][]*dboperator.IndexInfo),
+		foreignKeys: make(map[string][]*dboperator.ForeignKeyInfo),
+		checks:      make(map[string][]*dboperator.CheckInfo),
+	}
+	for _, table := range schema.Tables {
+		if !match(&dboperator.TableInfo{TableName: table.Name, Comment: table.Comment, RowCount: -1}) {
+			continue
+		}
+		src.tables = append(src.tables, table.Name)
+		src.comments[table.Name] = table.Comment
+		src.charsets[table.Name] = table.Charset
+		src.collations[table.Name] = table.Collation
+		colInfo := &dboperator.TableColInfo{TableName: table.Name}
+		for _, column := range table.Columns {
+			colInfo.ColumnInfoList = append(colInfo.ColumnInfoList, &dboperator.ColumnInfo{
+				ColumnName:      column.Name,
+				Comment:         column.Comment,
+				DataType:        column.DataType,
+				IsNullable:      column.Nullable,
+				DefaultValue:    column.Default,
+				OrdinalPosition: column.Position,
+				IsAutoIncrement: column.AutoIncrement,
+				NextValue:       column.NextValue,
+				Charset:         column.Charset,
+				Collation:       column.Collation,
+			})
+		}
+		src.columns[table.Name] = colInfo
+		for _, constraint := range table.Constraints {
+			switch constraint.Type {
+			case dboperator.ConstraintPrimaryKey:
+				src.primaryKeys[table.Name] = constraint.Columns
+			case dboperator.ConstraintUnique:
+				if src.uniqueKeys[table.Name] == nil {
+					src.uniqueKeys[table.Name] = make(map[string][]string)
+				}
+				src.uniqueKeys[table.Name][constraint.Name] = constraint.Columns
+			case dboperator.ConstraintForeignKey:
+				src.foreignKeys[table.Name] = append(src.foreignKeys[table.Name], &dboperator.ForeignKeyInfo{
+					ConstraintName: constraint.Name,
+					Columns:        constraint.Columns,
+					RefSchemaName:  constraint.RefSchema,
+					RefTableName:   constraint.RefTable,
+					RefColumns:     constraint.RefColumns,
+					OnDelete:       constraint.OnDelete,
+					OnUpdate:       constraint.OnUpdate,
+				})
+			case dboperator.ConstraintCheck:
+				src.checks[table.Name] = append(src.checks[table.Name], &dboperator.CheckInfo{ConstraintName: constraint.Name, Expression: constraint.Expression})
+			}
+		}
+		for _, index := range table.Indexes {
+			src.indexes[table.Name] = append(src.indexes[table.Name], &dboperator.IndexInfo{
+				IndexName: index.Name,
+				IsUnique:  index.Unique,
+				Columns:   index.Columns,
+				Filter:    index.Filter,
+			})
+		}
+	}
+	return src
+}
+
+// schemaTables 转为模式快照中的表，含列、注释、字符集、排序规则、键、检查约束及索引
+func (s *sourceTables) schemaTables() []*dboperator.Table {
+	tables := make([]*dboperator.Table, 0, len(s.tables))
+	for _, tableName := range s.tables {
+		table := &dboperator.Table{Name: tableName, Comment: s.comments[tableName], Charset: s.charsets[tableName], Collation: s.collations[tableName],
+			Columns: make([]*dboperator.Column, 0)}
+		if colInfo, ok := s.columns[tableName]; ok {
+			for _, columnInfo := range colInfo.ColumnInfoList {
+				table.Columns = append(table.Columns, &dboperator.Column{
+					Name:          columnInfo.ColumnName,
+					DataType:      columnInfo.DataType,
+					Nullable:      columnInfo.IsNullable,
+					Default:       columnInfo.DefaultValue,
+					Comment:       columnInfo.Comment,
+					Position:      columnInfo.OrdinalPosition,
+					AutoIncrement: columnInfo.IsAutoIncrement,
+					NextValue:     columnInfo.NextValue,
+					Charset:       columnInfo.Charset,
+					Collation:     columnInfo.Collation,
+				})
+			}
+		}
+		if primaryKey := s.primaryKeys[tableName]; len(primaryKey) > 0 {
+			table.Constraints = append(table.Constraints, &dboperator.Constraint{Type: dboperator.ConstraintPrimaryKey, Columns: primaryKey})
+		}
+		for _, constraintName := range utils.SortedKeys(s.uniqueKeys[tableName]) {
+			table.Constraints = append(table.Constraints, &dboperator.Constraint{Name: constraintName, Type: dboperator.ConstraintUnique,
+				Columns: s.uniqueKeys[tableName][constraintName]})
+		}
+		for _, foreignKey := range s.foreignKeys[tableName] {
+			table.Constraints = append(table.Constraints, &dboperator.Constraint{
+				Name:       foreignKey.ConstraintName,
+				Type:       dboperator.ConstraintForeignKey,
+				Columns:    foreignKey.Columns,
+				RefSchema:  foreignKey.RefSchemaName,
+				RefTable:   foreignKey.RefTableName,
+				RefColumns: foreignKey.RefColumns,
+				OnDelete:   foreignKey.OnDelete,
+				OnUpdate:   foreignKey.OnUpdate,
+			})
+		}
+		for _, check := range s.checks[tableName] {
+			table.Constraints = append(table.Constraints, &dboperator.Constraint{Name: check.ConstraintName, Type: dboperator.ConstraintCheck, Expression: check.Expression})
+		}
+		for _, index := range s.indexes[tableName] {
+			table.Indexes = append(table.Indexes, &dboperator.Index{Name: index.IndexName, Unique: index.IsUnique, Columns: index.Columns, Filter: index.Filter})
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
@@ -0,0 +1,197 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/core/utils"
+	"github.com/jasonlabz/dbutil/dboperator"
+	"github.com/jasonlabz/dbutil/dbx"
+	"github.com/jasonlabz/dbutil/log"
+)
+
+// tableDefinitions 待在目标库创建的表及其键、约束、索引，均以表名为键
+type tableDefinitions struct {
+	primaryKeys map[string][]string
+	uniqueKeys  map[string]map[string][]string
+	fields      map[string][]*dboperator.Field
+	comments    map[string]string
+	foreignKeys map[string][]*dboperator.ForeignKeyInfo
+	checks      map[string][]*dboperator.CheckInfo
+	indexes     map[string][]*dboperator.IndexInfo
+}
+
+// nameMapping 按重命名规则及目标库标识符规则计算待创建的表、列、约束及索引的名称，
+// 约束及索引名以重命名后的表名经 dboperator.AssignObjectNames 分配，与生成DDL时一致
+func (d *tableDefinitions) nameMapping(targetDBType dbx.DBType, spec *dboperator.RenameSpec) *dboperator.NameMapping {
+	identifier := dboperator.GetIdentifierPolicy(targetDBType)
+	assign := func(namesMap map[string][]string) dboperator.ObjectNames {
+		renamedMap := make(map[string][]string)
+		for tableName, names := range namesMap {
+			renamedMap[spec.TableName(tableName)] = names
+		}
+		return dboperator.AssignObjectNames(identifier, make(map[string]bool), renamedMap)
+	}
+	uniqueNames := assign(dboperator.UniqueKeyNames(d.uniqueKeys))
+	foreignKeyNames := assign(dboperator.ForeignKeyNames(d.foreignKeys))
+	checkNames := assign(dboperator.CheckNames(d.checks))
+	indexNames := assign(dboperator.IndexNames(d.indexes))
+
+	mapping := dboperator.NewNameMapping()
+	for tableName, fields := range d.fields {
+		newTableName := spec.TableName(tableName)
+		mapping.AddTable(tableName, identifier.Fold(newTableName))
+		for _, field := range fields {
+			mapping.AddColumn(tableName, field.ColumnName, identifier.Fold(spec.ColumnName(tableName, field.ColumnName)))
+		}
+		for uniqueName := range d.uniqueKeys[tableName] {
+			if uniqueName != "" {
+				mapping.AddConstraint(tableName, uniqueName, uniqueNames.Get(newTableName, uniqueName))
+			}
+		}
+		for _, foreignKey := range d.foreignKeys[tableName] {
+			mapping.AddConstraint(tableName, foreignKey.ConstraintName, foreignKeyNames.Get(newTableName, foreignKey.ConstraintName))
+		}
+		for _, check := range d.checks[tableName] {
+			mapping.AddConstraint(tableName, check.ConstraintName, checkNames.Get(newTableName, check.ConstraintName))
+		}
+		for _, index := range d.indexes[tableName] {
+			mapping.AddConstraint(tableName, index.IndexName, indexNames.Get(newTableName, index.IndexName))
+		}
+	}
+	return mapping
+}
+
+// rename 按重命名规则改写表名、列名，键、外键、检查约束及索引中的列名随之改写，返回副本。
+// 重命名后表名或同一表中的列名重复时返回错误
+func (d *tableDefinitions) rename(ctx context.Context, spec *dboperator.RenameSpec) (renamed *tableDefinitions, err error) {
+	logger := log.GetLogger(ctx)
+	renamed = &tableDefinitions{
+		primaryKeys: make(map[string][]string),
+		uniqueKeys:  make(map[string]map[string][]string),
+		fields:      make(map[string][]*dboperator.Field),
+		comments:    make(map[string]string),
+		foreignKeys: make(map[string][]*dboperator.ForeignKeyInfo),
+		checks:      make(map[string][]*dboperator.CheckInfo),
+		indexes:     make(map[string][]*dboperator.IndexInfo),
+	}
+	renameColumns := func(tableName string, columns []string) []string {
+		result := make([]string, 0, len(columns))
+		for _, column := range columns {
+			result = append(result, spec.ColumnName(tableName, column))
+		}
+		return result
+	}
+
+	sourceTables := make(map[string]string)
+	for tableName, fields := range d.fields {
+		newTableName := spec.TableName(tableName)
+		if sourceTable, ok := sourceTables[newTableName]; ok {
+			return nil, fmt.Errorf("tables %s and %s are both renamed to %s", sourceTable, tableName, newTableName)
+		}
+		sourceTables[newTableName] = tableName
+
+		columnRenamed := false
+		sourceColumns := make(map[string]string)
+		newFields := make([]*dboperator.Field, 0, len(fields))
+		for _, field := range fields {
+			newField := *field
+			newField.ColumnName = spec.ColumnName(tableName, field.ColumnName)
+			if sourceColumn, ok := sourceColumns[newField.ColumnName]; ok {
+				return nil, fmt.Errorf("columns %s and %s of %s are both renamed to %s", sourceColumn, field.ColumnName, tableName, newField.ColumnName)
+			}
+			sourceColumns[newField.ColumnName] = field.ColumnName
+			columnRenamed = columnRenamed || newField.ColumnName != field.ColumnName
+			newFields = append(newFields, &newField)
+		}
+		renamed.fields[newTableName] = newFields
+		renamed.comments[newTableName] = d.comments[tableName]
+
+		if keys, ok := d.primaryKeys[tableName]; ok {
+			renamed.primaryKeys[newTableName] = renameColumns(tableName, keys)
+		}
+		if uniqueKeys, ok := d.uniqueKeys[tableName]; ok {
+			renamed.uniqueKeys[newTableName] = make(map[string][]string)
+			for constraintName, columns := range uniqueKeys {
+				renamed.uniqueKeys[newTableName][constraintName] = renameColumns(tableName, columns)
+			}
+		}
+		for _, foreignKey := range d.foreignKeys[tableName] {
+			newForeignKey := *foreignKey
+			newForeignKey.Columns = renameColumns(tableName, foreignKey.Columns)
+			newForeignKey.RefTableName = spec.TableName(foreignKey.RefTableName)
+			newForeignKey.RefColumns = renameColumns(foreignKey.RefTableName, foreignKey.RefColumns)
+			renamed.foreignKeys[newTableName] = append(renamed.foreignKeys[newTableName], &newForeignKey)
+		}
+		for _, check := range d.checks[tableName] {
+			renamed.checks[newTableName] = append(renamed.checks[newTableName], &dboperator.CheckInfo{
+				ConstraintName: check.ConstraintName,
+				Expression:     spec.RenameExpression(tableName, check.Expression),
+			})
+		}
+		for _, index := range d.indexes[tableName] {
+			newIndex := *index
+			newIndex.Columns = make([]*dboperator.IndexColumn, 0, len(index.Columns))
+			for _, column := range index.Columns {
+				newColumn := *column
+				if column.ColumnName != "" {
+					newColumn.ColumnName = spec.ColumnName(tableName, column.ColumnName)
+				}
+				newIndex.Columns = append(newIndex.Columns, &newColumn)
+			}
+			// 函数索引表达式及过滤条件为源库写法，其中的列名不改写
+			if columnRenamed && (index.IsFunctional() || index.Filter != "") {
+				logger.Warn("index %s on %s has expressions or filter, renamed columns in them are not rewritten", index.IndexName, tableName)
+			}
+			renamed.indexes[newTableName] = append(renamed.indexes[newTableName], &newIndex)
+		}
+	}
+	return
+}
+
+// migrate 比较待创建的表与目标库现状 current，返回修改已存在的同名表的修改项，并将这些表从 d 中移除，剩余的表按新建处理。
+// 破坏性修改(删除列、删除键、收窄类型)仅在 allowDestructive 时保留，否则跳过并告警；已存在表的外键、检查约束不做修改，
+// 索引仅保留目标表中不存在同名索引的部分
+func (d *tableDefinitions) migrate(ctx context.Context, current *dboperator.Schema, targetDBType dbx.DBType, target dboperator.ITransfer,
+	allowDestructive bool) (alterations []*dboperator.TableAlteration) {
+	logger := log.GetLogger(ctx)
+	identifier := dboperator.GetIdentifierPolicy(targetDBType)
+	for _, tableName := range utils.SortedKeys(d.fields) {
+		targetName := identifier.Fold(tableName)
+		table := current.Table(targetName)
+		for i := 0; table == nil && i < len(current.Tables); i++ {
+			if strings.EqualFold(current.Tables[i].Name, targetName) {
+				table = current.Tables[i]
+			}
+		}
+		if table == nil {
+			continue
+		}
+		for _, alteration := range dboperator.PlanAlterations(table, d.fields[tableName], d.primaryKeys[tableName], d.uniqueKeys[tableName], targetDBType, target) {
+			if alteration.Destructive && !allowDestructive {
+				logger.Warn("skip destructive %s on %s (%s), allow destructive changes to apply it", alteration.Action, table.Name, alteration.Reason)
+				continue
+			}
+			alterations = append(alterations, alteration)
+		}
+		existIndexes := make(map[string]bool)
+		for _, index := range table.Indexes {
+			existIndexes[strings.ToLower(index.Name)] = true
+		}
+		indexes := make([]*dboperator.IndexInfo, 0)
+		for _, index := range d.indexes[tableName] {
+			if !existIndexes[strings.ToLower(identifier.Fold(index.IndexName))] {
+				indexes = append(indexes, index)
+			}
+		}
+		d.indexes[tableName] = indexes
+		delete(d.fields, tableName)
+		delete(d.primaryKeys, tableName)
+		delete(d.uniqueKeys, tableName)
+		delete(d.comments, tableName)
+		delete(d.foreignKeys, tableName)
+		delete(d.checks, tableName)
+	}
+	return
+}
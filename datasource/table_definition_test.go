@@ -0,0 +1,60 @@
+package datasource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dboperator"
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestNameMappingDuplicateConstraintNames(t *testing.T) {
+	ddlFile := filepath.Join(t.TempDir(), "source.sql")
+	err := os.WriteFile(ddlFile, []byte(`
+CREATE TABLE users (id int NOT NULL PRIMARY KEY);
+CREATE TABLE orders (
+  id int NOT NULL PRIMARY KEY,
+  code varchar(20),
+  user_id int,
+  CONSTRAINT uk_code UNIQUE (code),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE TABLE returns (
+  id int NOT NULL PRIMARY KEY,
+  code varchar(20),
+  user_id int,
+  CONSTRAINT uk_code UNIQUE (code),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddlSQL, _, mapping, err := RenderTable(context.Background(), dbx.Config{DBType: dbx.DBTypeMySQL}, dbx.DBTypeOracle, "app", "app", nil,
+		&GenOptions{SourceDDL: ddlFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 名称未变化的不记录
+	expected := map[string]map[string]string{
+		"orders":  {"uk_code": "", "fk_user": ""},
+		"returns": {"uk_code": "returns_uk_code", "fk_user": "returns_fk_user"},
+	}
+	identifier := dboperator.GetIdentifierPolicy(dbx.DBTypeOracle)
+	for tableName, constraints := range expected {
+		for name, targetName := range constraints {
+			if mapped := mapping.Constraints[tableName][name]; mapped != targetName {
+				t.Errorf("%s.%s mapped to %q, expected %q", tableName, name, mapped, targetName)
+			}
+			if targetName == "" {
+				targetName = name
+			}
+			if !strings.Contains(ddlSQL, "constraint "+identifier.Name(targetName)+" ") {
+				t.Errorf("constraint %s not found in:\n%s", targetName, ddlSQL)
+			}
+		}
+	}
+}
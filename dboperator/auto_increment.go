@@ -0,0 +1,119 @@
+package dboperator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/core/utils"
+)
+
+var (
+	// sequenceNextvalReg oracle/dm以序列作默认值的写法，如 "SCOTT"."SEQ_ID"."NEXTVAL"、seq_id.nextval
+	sequenceNextvalReg = regexp.MustCompile(`(?i)^(?:("[^"]+"|[\w$#]+)\s*\.\s*)?("[^"]+"|[\w$#]+)\s*\.\s*"?nextval"?$`)
+	// triggerAssignReg 触发器中以序列为列赋值，如 :new.id := seq_id.nextval
+	triggerAssignReg = regexp.MustCompile(`(?is):new\s*\.\s*("[^"]+"|[\w$#]+)\s*:=\s*(?:("[^"]+"|[\w$#]+)\s*\.\s*)?("[^"]+"|[\w$#]+)\s*\.\s*nextval\b`)
+	// triggerSelectReg 触发器中以 select ... into 为列赋值，如 select seq_id.nextval into :new.id from dual
+	triggerSelectReg = regexp.MustCompile(`(?is)\bselect\s+(?:("[^"]+"|[\w$#]+)\s*\.\s*)?("[^"]+"|[\w$#]+)\s*\.\s*nextval\s+into\s+:new\s*\.\s*("[^"]+"|[\w$#]+)`)
+)
+
+// SequenceColumn 以序列取值的列，序列作默认值或在 before insert 触发器中以序列赋值
+type SequenceColumn struct {
+	TableName    string
+	ColumnName   string
+	Owner        string // 序列所属用户
+	SequenceName string
+}
+
+// FillAutoIncrement 按查询结果标记各表的自增列，并记录自增列下一个值
+func FillAutoIncrement(tableColMap map[string]*TableColInfo, rows []*TableAutoIncrement) {
+	for _, row := range rows {
+		tableColInfo, ok := tableColMap[row.TableName]
+		if !ok {
+			continue
+		}
+		for _, column := range tableColInfo.ColumnInfoList {
+			if column.ColumnName != row.ColumnName {
+				continue
+			}
+			column.IsAutoIncrement = true
+			if row.NextValue > column.NextValue {
+				column.NextValue = row.NextValue
+			}
+		}
+	}
+}
+
+// ParseSequenceDefault 解析oracle/dm以序列作默认值的写法，返回序列所属用户(未限定时为空)及序列名，
+// 未加引号的名称按oracle/dm规则转为大写
+func ParseSequenceDefault(defaultValue string) (owner, sequenceName string, ok bool) {
+	matches := sequenceNextvalReg.FindStringSubmatch(strings.TrimSpace(defaultValue))
+	if len(matches) != 3 {
+		return
+	}
+	return unquoteUpperIdent(matches[1]), unquoteUpperIdent(matches[2]), true
+}
+
+// ParseSequenceTrigger 解析oracle/dm 12c之前以 before insert 触发器填充自增列的写法，返回赋值的列、序列所属用户(未限定时为空)及序列名
+func ParseSequenceTrigger(triggerBody string) (columnName, owner, sequenceName string, ok bool) {
+	if matches := triggerAssignReg.FindStringSubmatch(triggerBody); len(matches) == 4 {
+		return unquoteUpperIdent(matches[1]), unquoteUpperIdent(matches[2]), unquoteUpperIdent(matches[3]), true
+	}
+	if matches := triggerSelectReg.FindStringSubmatch(triggerBody); len(matches) == 4 {
+		return unquoteUpperIdent(matches[3]), unquoteUpperIdent(matches[1]), unquoteUpperIdent(matches[2]), true
+	}
+	return
+}
+
+// SequenceColumns 以序列作默认值的列及 triggers 中以序列赋值的列，同一列只取一次，未限定用户的序列属于 schemaName
+func SequenceColumns(schemaName string, tableColMap map[string]*TableColInfo, triggers []*TableTrigger) (columns []*SequenceColumn) {
+	found := make(map[[2]string]bool)
+	add := func(tableName, columnName, owner, sequenceName string) {
+		if found[[2]string{tableName, columnName}] {
+			return
+		}
+		found[[2]string{tableName, columnName}] = true
+		if owner == "" {
+			owner = schemaName
+		}
+		columns = append(columns, &SequenceColumn{TableName: tableName, ColumnName: columnName, Owner: owner, SequenceName: sequenceName})
+	}
+	for _, tableName := range utils.SortedKeys(tableColMap) {
+		for _, column := range tableColMap[tableName].ColumnInfoList {
+			if owner, sequenceName, ok := ParseSequenceDefault(column.DefaultValue); ok {
+				add(tableName, column.ColumnName, owner, sequenceName)
+			}
+		}
+	}
+	for _, trigger := range triggers {
+		tableColInfo, ok := tableColMap[trigger.TableName]
+		if !ok {
+			continue
+		}
+		columnName, owner, sequenceName, ok := ParseSequenceTrigger(trigger.TriggerBody)
+		if !ok {
+			continue
+		}
+		for _, column := range tableColInfo.ColumnInfoList {
+			if column.ColumnName == columnName {
+				add(trigger.TableName, column.ColumnName, owner, sequenceName)
+			}
+		}
+	}
+	return
+}
+
+func unquoteUpperIdent(identifier string) string {
+	if strings.HasPrefix(identifier, `"`) {
+		return strings.Trim(identifier, `"`)
+	}
+	return strings.ToUpper(identifier)
+}
+
+// IsIntegerField 是否整数类型，仅整数类型可在目标库生成自增列
+func IsIntegerField(field *Field) bool {
+	switch field.Type {
+	case INT8, INT16, INT32, INT64:
+		return true
+	}
+	return false
+}
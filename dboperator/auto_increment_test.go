@@ -0,0 +1,51 @@
+package dboperator
+
+import "testing"
+
+func TestParseSequenceDefault(t *testing.T) {
+	cases := []struct {
+		defaultValue string
+		owner        string
+		sequenceName string
+		ok           bool
+	}{
+		{`"SCOTT"."ISEQ$$_7301".nextval`, "SCOTT", "ISEQ$$_7301", true},
+		{"seq_user_id.NEXTVAL ", "", "SEQ_USER_ID", true},
+		{`scott."Seq".nextval`, "SCOTT", "Seq", true},
+		{"'nextval'", "", "", false},
+	}
+	for _, c := range cases {
+		owner, sequenceName, ok := ParseSequenceDefault(c.defaultValue)
+		if owner != c.owner || sequenceName != c.sequenceName || ok != c.ok {
+			t.Errorf("ParseSequenceDefault(%q) = %q, %q, %v", c.defaultValue, owner, sequenceName, ok)
+		}
+	}
+}
+
+func TestSequenceColumns(t *testing.T) {
+	tableColMap := map[string]*TableColInfo{
+		"USERS":  {TableName: "USERS", ColumnInfoList: []*ColumnInfo{{ColumnName: "ID"}, {ColumnName: "NAME"}}},
+		"ORDERS": {TableName: "ORDERS", ColumnInfoList: []*ColumnInfo{{ColumnName: "ID", DefaultValue: "seq_order.nextval"}}},
+		"ITEMS":  {TableName: "ITEMS", ColumnInfoList: []*ColumnInfo{{ColumnName: "ItemId"}}},
+	}
+	triggers := []*TableTrigger{
+		{TableName: "USERS", TriggerBody: "BEGIN\n  IF :NEW.id IS NULL THEN\n    :new.ID := app.seq_user.NEXTVAL;\n  END IF;\nEND;"},
+		{TableName: "ORDERS", TriggerBody: "begin :new.id := other_seq.nextval; end;"},
+		{TableName: "ITEMS", TriggerBody: `begin select "Seq_Item".nextval into :new."ItemId" from dual; end;`},
+		{TableName: "USERS", TriggerBody: "begin :new.name := upper(:new.name); end;"},
+	}
+	columns := SequenceColumns("APP", tableColMap, triggers)
+	expected := []SequenceColumn{
+		{TableName: "ORDERS", ColumnName: "ID", Owner: "APP", SequenceName: "SEQ_ORDER"},
+		{TableName: "USERS", ColumnName: "ID", Owner: "APP", SequenceName: "SEQ_USER"},
+		{TableName: "ITEMS", ColumnName: "ItemId", Owner: "APP", SequenceName: "Seq_Item"},
+	}
+	if len(columns) != len(expected) {
+		t.Fatalf("unexpected sequence columns %+v", columns)
+	}
+	for i, column := range columns {
+		if *column != expected[i] {
+			t.Errorf("sequence column %d = %+v, expected %+v", i, *column, expected[i])
+		}
+	}
+}
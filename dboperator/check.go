@@ -0,0 +1,179 @@
+package dboperator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// checkKeywords 各数据库通用的检查约束关键字
+var checkKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "IS": true, "NULL": true,
+	"LIKE": true, "BETWEEN": true, "ESCAPE": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+}
+
+// checkFunctions 各数据库写法一致的函数
+var checkFunctions = map[string]bool{
+	"UPPER": true, "LOWER": true, "ABS": true, "COALESCE": true,
+}
+
+// NormalizeCheckExpression 将源库检查约束表达式归一化为通用写法，列名按columns匹配并加双引号。
+// 仅支持比较、逻辑运算、IN、BETWEEN、LIKE 及少量通用函数，其余写法返回错误，由调用方告警
+func NormalizeCheckExpression(expression string, columns []string) (normalized string, err error) {
+	tokens, err := tokenizeSQL(strings.TrimSpace(expression))
+	if err != nil {
+		return
+	}
+	if len(tokens) > 0 && tokens[0].kind == sqlTokenWord && strings.EqualFold(tokens[0].value, "check") {
+		tokens = tokens[1:]
+	}
+	tokens = dropTypeCasts(tokens, checkKeywords)
+	tokens, err = rewriteCheckArrays(tokens)
+	if err != nil {
+		return
+	}
+
+	for i, token := range tokens {
+		switch token.kind {
+		case sqlTokenWord:
+			upper := strings.ToUpper(token.value)
+			if i+1 < len(tokens) && tokens[i+1].value == "(" && !checkKeywords[upper] {
+				if !checkFunctions[upper] {
+					err = fmt.Errorf("unsupported function %s", token.value)
+					return
+				}
+				tokens[i].value = upper
+				continue
+			}
+			if checkKeywords[upper] {
+				tokens[i].value = upper
+				continue
+			}
+			fallthrough
+		case sqlTokenIdent:
+			columnName, ok := matchColumn(token.value, columns)
+			if !ok {
+				err = fmt.Errorf("unknown identifier %s", token.value)
+				return
+			}
+			tokens[i] = sqlToken{kind: sqlTokenIdent, value: columnName}
+		case sqlTokenSymbol:
+			if token.value == "!=" {
+				tokens[i].value = "<>"
+			}
+			if token.value == "[" || token.value == "]" || token.value == "::" {
+				err = fmt.Errorf("unsupported symbol %s", token.value)
+				return
+			}
+		}
+	}
+	normalized = renderSQLTokens(unwrapCheckOperands(tokens), checkFunctions)
+	for {
+		trimmed := trimOuterParens(normalized)
+		if trimmed == normalized {
+			break
+		}
+		normalized = trimmed
+	}
+	if normalized == "" {
+		err = errors.New("empty expression")
+	}
+	return
+}
+
+// matchColumn 优先精确匹配列名，其次忽略大小写匹配
+func matchColumn(name string, columns []string) (string, bool) {
+	for _, column := range columns {
+		if column == name {
+			return column, true
+		}
+	}
+	for _, column := range columns {
+		if strings.EqualFold(column, name) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// rewriteCheckArrays 将postgresql的 = ANY (ARRAY[...]) 改写为 IN (...)，<> ALL (ARRAY[...]) 改写为 NOT IN (...)
+func rewriteCheckArrays(tokens []sqlToken) ([]sqlToken, error) {
+	result := make([]sqlToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		upper := strings.ToUpper(token.value)
+		if token.kind != sqlTokenWord || (upper != "ANY" && upper != "ALL") {
+			result = append(result, token)
+			continue
+		}
+		if len(result) == 0 || (upper == "ANY" && result[len(result)-1].value != "=") ||
+			(upper == "ALL" && result[len(result)-1].value != "<>" && result[len(result)-1].value != "!=") {
+			return nil, fmt.Errorf("unsupported %s expression", upper)
+		}
+		// 跳过 ARRAY[ 之前的括号，并找到与之配对的右括号
+		j := i + 1
+		opens := 0
+		for j < len(tokens) && tokens[j].value == "(" {
+			opens++
+			j++
+		}
+		if opens == 0 || j+1 >= len(tokens) || !strings.EqualFold(tokens[j].value, "array") || tokens[j+1].value != "[" {
+			return nil, fmt.Errorf("unsupported %s expression", upper)
+		}
+		j += 2
+		items := make([]sqlToken, 0)
+		for j < len(tokens) && tokens[j].value != "]" {
+			items = append(items, tokens[j])
+			j++
+		}
+		j++
+		for ; opens > 0 && j < len(tokens) && tokens[j].value == ")"; opens-- {
+			j++
+		}
+		if opens > 0 {
+			return nil, fmt.Errorf("unsupported %s expression", upper)
+		}
+		result = result[:len(result)-1]
+		if upper == "ALL" {
+			result = append(result, sqlToken{kind: sqlTokenWord, value: "NOT"})
+		}
+		result = append(result, sqlToken{kind: sqlTokenWord, value: "IN"}, sqlToken{kind: sqlTokenSymbol, value: "("})
+		result = append(result, items...)
+		result = append(result, sqlToken{kind: sqlTokenSymbol, value: ")"})
+		i = j - 1
+	}
+	return result, nil
+}
+
+// unwrapCheckOperands 去掉包裹单个列名或常量的括号，如 (status)、(0)，IN 列表及函数参数除外
+func unwrapCheckOperands(tokens []sqlToken) []sqlToken {
+	result := make([]sqlToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].value == "(" && i+2 < len(tokens) && tokens[i+2].value == ")" && tokens[i+1].kind != sqlTokenSymbol {
+			prev := ""
+			if len(result) > 0 {
+				prev = result[len(result)-1].value
+			}
+			if prev != "IN" && !checkFunctions[prev] {
+				result = append(result, tokens[i+1])
+				i += 2
+				continue
+			}
+		}
+		result = append(result, tokens[i])
+	}
+	return result
+}
+
+// GroupCheckConstraints 将检查约束按表汇总
+func GroupCheckConstraints(rows []*TableCheckConstraint) (checkInfo map[string][]*CheckInfo) {
+	checkInfo = make(map[string][]*CheckInfo)
+	for _, row := range rows {
+		checkInfo[row.TableName] = append(checkInfo[row.TableName], &CheckInfo{
+			ConstraintName: row.ConstraintName,
+			Expression:     row.Expression,
+		})
+	}
+	return
+}
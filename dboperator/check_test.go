@@ -0,0 +1,28 @@
+package dboperator
+
+import "testing"
+
+func TestNormalizeCheckExpression(t *testing.T) {
+	columns := []string{"status", "AGE", "name"}
+	cases := map[string]string{
+		`((status)::text = ANY ((ARRAY['a'::character varying, 'b'::character varying])::text[]))`: `"status" IN ('a', 'b')`,
+		`((status)::text <> ALL (ARRAY['x'::text]))`:                                               `"status" NOT IN ('x')`,
+		"(`status` in (_utf8mb4'a',_utf8mb4'b'))":                                                  `"status" IN ('a', 'b')`,
+		`([age]>(0) AND [name]<>N'')`:                                                              `"AGE" > 0 AND "name" <> ''`,
+		`age between 1 and 120`:                                                                    `"AGE" BETWEEN 1 AND 120`,
+		`CHECK (upper(name) like 'A%')`:                                                            `UPPER("name") LIKE 'A%'`,
+		`"STATUS" is not null`:                                                                     `"status" IS NOT NULL`,
+	}
+	for raw, expected := range cases {
+		actual, err := NormalizeCheckExpression(raw, columns)
+		if err != nil || actual != expected {
+			t.Errorf("NormalizeCheckExpression(%q) = %q, %v, expected %q", raw, actual, err, expected)
+		}
+	}
+
+	for _, raw := range []string{`length(name) > 0`, `unknown > 0`, `name ~ '^a'`, `active = true`} {
+		if actual, err := NormalizeCheckExpression(raw, columns); err == nil {
+			t.Errorf("NormalizeCheckExpression(%q) = %q, expected error", raw, actual)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package dboperator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// collationSemantics 排序规则的比较语义，迁移时按语义而非名称映射
+type collationSemantics struct {
+	binary            bool // 按字节或码点比较
+	caseInsensitive   bool // 忽略大小写
+	accentInsensitive bool // 忽略重音
+}
+
+func (s collationSemantics) String() string {
+	switch {
+	case s.binary:
+		return "binary"
+	case s.caseInsensitive && s.accentInsensitive:
+		return "case-insensitive, accent-insensitive"
+	case s.caseInsensitive:
+		return "case-insensitive"
+	case s.accentInsensitive:
+		return "accent-insensitive"
+	}
+	return "case-sensitive"
+}
+
+// defaultCollations 未指定排序规则时各数据库的默认语义，mysql 8 默认 utf8mb4_0900_ai_ci，sqlserver 默认 SQL_Latin1_General_CP1_CI_AS
+var defaultCollations = map[dbx.DBType]collationSemantics{
+	dbx.DBTypeMySQL:     {caseInsensitive: true, accentInsensitive: true},
+	dbx.DBTypeSqlserver: {caseInsensitive: true},
+	dbx.DBTypeOracle:    {binary: true},
+	dbx.DBTypeSQLite:    {binary: true},
+}
+
+// mysqlCharsets 各数据库的字符集名称对应的 mysql 字符集，未列出的以 utf8mb4 保存
+var mysqlCharsets = map[string]string{
+	"utf8": "utf8mb4", "utf8mb3": "utf8mb4", "utf8mb4": "utf8mb4", "utf-8": "utf8mb4", "al32utf8": "utf8mb4", "al16utf16": "utf8mb4",
+	"latin1": "latin1", "we8iso8859p1": "latin1", "iso_8859_1": "latin1", "iso-8859-1": "latin1",
+	"gbk": "gbk", "zhs16gbk": "gbk", "cp936": "gbk",
+	"gb18030": "gb18030", "zhs32gb18030": "gb18030",
+	"ascii": "ascii", "sql_ascii": "ascii", "us7ascii": "ascii",
+	"binary": "binary",
+}
+
+// parseCollation 由排序规则名称推断比较语义，名称为空时为 dbType 的默认语义。
+// 名称按 _ 或 - 分隔，bin/bin2/binary 为二进制，ci 为忽略大小写，ai 为忽略重音(oracle 同时忽略大小写)；
+// mysql 的 _ci 未注明 as 时忽略重音，postgresql 的 C/POSIX 为二进制、ICU 的 ks-level1/2 忽略大小写
+func parseCollation(dbType dbx.DBType, collation string) collationSemantics {
+	name := strings.ToLower(collation)
+	switch name {
+	case "":
+		return defaultCollations[dbType]
+	case "c", "posix", "ucs_basic", "pg_c_utf8", "binary", "rtrim", "using_nls_comp":
+		return collationSemantics{binary: true}
+	case "nocase":
+		return collationSemantics{caseInsensitive: true}
+	}
+	var semantics collationSemantics
+	accentSensitive := false
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		switch part {
+		case "bin", "bin2", "binary":
+			semantics.binary = true
+		case "ci":
+			semantics.caseInsensitive = true
+		case "ai":
+			semantics.accentInsensitive = true
+		case "as", "cs":
+			accentSensitive = true
+		case "level1":
+			semantics.caseInsensitive, semantics.accentInsensitive = true, true
+		case "level2":
+			semantics.caseInsensitive = true
+		}
+	}
+	switch {
+	case semantics.caseInsensitive || semantics.accentInsensitive:
+		// oracle 的 BINARY_CI、BINARY_AI 为忽略大小写的二进制比较，AI 同时忽略大小写
+		semantics.binary = false
+		if semantics.accentInsensitive && (dbType == dbx.DBTypeOracle || dbType == dbx.DBTypeDM) {
+			semantics.caseInsensitive = true
+		}
+	case semantics.binary:
+		return collationSemantics{binary: true}
+	}
+	if dbType == dbx.DBTypeMySQL && semantics.caseInsensitive && !accentSensitive {
+		semantics.accentInsensitive = true
+	}
+	return semantics
+}
+
+// MapCollation 将源库的字符集、排序规则映射为目标库中比较语义相同的写法，为空时使用目标库默认值。
+// 同类数据库原样保留；仅 mysql 支持列级字符集，其余数据库的字符集由数据库决定，sqlserver 使用 _UTF8 排序规则(需 2019 及以上)
+// 使 varchar 以 UTF-8 保存；非 Unicode 字符集以 utf8mb4 或 UTF-8 保存时 level 为 widened，
+// 目标库无对应语义时 level 为 lossy，reason 说明丢失的语义
+func MapCollation(sourceDBType, targetDBType dbx.DBType, charset, collation string) (targetCharset, targetCollation string, level Compatibility, reason string) {
+	if sourceDBType == targetDBType {
+		return charset, collation, CompatibilityExact, ""
+	}
+	semantics := parseCollation(sourceDBType, collation)
+	describe := semantics.String()
+	if collation != "" {
+		describe = fmt.Sprintf("%s (%s)", collation, describe)
+	}
+	level = CompatibilityExact
+	switch targetDBType {
+	case dbx.DBTypeMySQL:
+		targetCharset = mysqlCharsets[strings.ToLower(charset)]
+		if targetCharset == "" {
+			targetCharset = "utf8mb4"
+			if charset != "" {
+				level, reason = CompatibilityWidened, fmt.Sprintf("charset %s stored as utf8mb4", charset)
+			}
+		}
+		targetCollation = mysqlCollation(targetCharset, semantics)
+	case dbx.DBTypePostgres:
+		switch {
+		case semantics.binary:
+			targetCollation = "C"
+		case semantics.caseInsensitive || semantics.accentInsensitive:
+			return "", "", CompatibilityLossy, describe + " has no built-in equivalent, a nondeterministic ICU collation is required"
+		}
+	case dbx.DBTypeSqlserver:
+		// Latin1_General_100_* 不带 _UTF8 时 varchar 使用代码页 1252，无法保存其他字符
+		targetCollation = "Latin1_General_100_BIN2_UTF8"
+		if !semantics.binary {
+			targetCollation = "Latin1_General_100_CS_AS_SC_UTF8"
+			if semantics.caseInsensitive {
+				targetCollation = strings.Replace(targetCollation, "_CS", "_CI", 1)
+			}
+			if semantics.accentInsensitive {
+				targetCollation = strings.Replace(targetCollation, "_AS", "_AI", 1)
+			}
+		}
+		if charset != "" && mysqlCharsets[strings.ToLower(charset)] != "utf8mb4" {
+			level, reason = CompatibilityWidened, fmt.Sprintf("charset %s stored as UTF-8", charset)
+		}
+	case dbx.DBTypeSQLite:
+		if semantics.caseInsensitive {
+			targetCollation = "NOCASE"
+			level, reason = CompatibilityLossy, describe+" stored as NOCASE, which folds ascii letters only"
+		}
+		if semantics.accentInsensitive {
+			level, reason = CompatibilityLossy, describe+" has no equivalent, accents are compared"
+		}
+	default:
+		// oracle、达梦的列级排序规则需开启扩展数据类型，不生成
+		if semantics.caseInsensitive || semantics.accentInsensitive {
+			return "", "", CompatibilityLossy, fmt.Sprintf("%s has no equivalent in %s, values are compared case-sensitively", describe, targetDBType)
+		}
+	}
+	return
+}
+
+// mysqlCollation 字符集下与比较语义对应的 mysql 排序规则，区分大小写的均以 _bin 保存；
+// 忽略大小写而区分重音的 utf8mb4_0900_as_ci 需 mysql 8.0 及以上，5.7 无对应排序规则
+func mysqlCollation(charset string, semantics collationSemantics) string {
+	switch {
+	case charset == "binary":
+		return "binary"
+	case semantics.binary || !semantics.caseInsensitive:
+		return charset + "_bin"
+	case charset == "utf8mb4" && !semantics.accentInsensitive:
+		return "utf8mb4_0900_as_ci"
+	case charset == "gbk":
+		return "gbk_chinese_ci"
+	case charset == "gb18030":
+		return "gb18030_chinese_ci"
+	}
+	return charset + "_general_ci"
+}
+
+// IsCharacterField 是否可指定字符集、排序规则的字符类型
+func IsCharacterField(field *Field) bool {
+	switch field.Type {
+	case STRING, ENUM, SET:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package dboperator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestMapCollation(t *testing.T) {
+	cases := []struct {
+		source, target     dbx.DBType
+		charset, collation string
+		expectedCharset    string
+		expectedCollation  string
+		expectedLevel      Compatibility
+	}{
+		{dbx.DBTypeMySQL, dbx.DBTypePostgres, "utf8mb4", "utf8mb4_bin", "", "C", CompatibilityExact},
+		{dbx.DBTypeMySQL, dbx.DBTypeSqlserver, "utf8mb4", "utf8mb4_bin", "", "Latin1_General_100_BIN2_UTF8", CompatibilityExact},
+		{dbx.DBTypeMySQL, dbx.DBTypeSqlserver, "utf8mb4", "utf8mb4_0900_as_cs", "", "Latin1_General_100_CS_AS_SC_UTF8", CompatibilityExact},
+		{dbx.DBTypeMySQL, dbx.DBTypeSqlserver, "utf8mb4", "utf8mb4_general_ci", "", "Latin1_General_100_CI_AI_SC_UTF8", CompatibilityExact},
+		{dbx.DBTypeMySQL, dbx.DBTypeSqlserver, "latin1", "latin1_swedish_ci", "", "Latin1_General_100_CI_AI_SC_UTF8", CompatibilityWidened},
+		{dbx.DBTypeMySQL, dbx.DBTypePostgres, "utf8mb4", "utf8mb4_general_ci", "", "", CompatibilityLossy},
+		{dbx.DBTypeMySQL, dbx.DBTypeSQLite, "utf8mb4", "utf8mb4_0900_ai_ci", "", "NOCASE", CompatibilityLossy},
+		{dbx.DBTypeMySQL, dbx.DBTypeOracle, "utf8mb4", "utf8mb4_bin", "", "", CompatibilityExact},
+		{dbx.DBTypeMySQL, dbx.DBTypeOracle, "utf8mb4", "", "", "", CompatibilityLossy},
+		{dbx.DBTypeMySQL, dbx.DBTypeMySQL, "latin1", "latin1_swedish_ci", "latin1", "latin1_swedish_ci", CompatibilityExact},
+		{dbx.DBTypeSqlserver, dbx.DBTypeMySQL, "", "Chinese_PRC_CI_AS", "utf8mb4", "utf8mb4_0900_as_ci", CompatibilityExact},
+		{dbx.DBTypeSqlserver, dbx.DBTypePostgres, "", "Latin1_General_BIN2", "", "C", CompatibilityExact},
+		{dbx.DBTypeOracle, dbx.DBTypeSqlserver, "AL32UTF8", "BINARY_CI", "", "Latin1_General_100_CI_AS_SC_UTF8", CompatibilityExact},
+		{dbx.DBTypeOracle, dbx.DBTypeMySQL, "ZHS16GBK", "", "gbk", "gbk_bin", CompatibilityExact},
+		{dbx.DBTypePostgres, dbx.DBTypeMySQL, "UTF8", "", "utf8mb4", "utf8mb4_bin", CompatibilityExact},
+		{dbx.DBTypePostgres, dbx.DBTypeMySQL, "EUC_JP", "", "utf8mb4", "utf8mb4_bin", CompatibilityWidened},
+		{dbx.DBTypePostgres, dbx.DBTypeSQLite, "", "und-u-ks-level2", "", "NOCASE", CompatibilityLossy},
+	}
+	for _, c := range cases {
+		charset, collation, level, reason := MapCollation(c.source, c.target, c.charset, c.collation)
+		if charset != c.expectedCharset || collation != c.expectedCollation || level != c.expectedLevel {
+			t.Errorf("%s %s/%s to %s = %q %q %s (%s), expected %q %q %s", c.source, c.charset, c.collation, c.target,
+				charset, collation, level, reason, c.expectedCharset, c.expectedCollation, c.expectedLevel)
+		}
+	}
+}
+
+func TestAnalyzeCollation(t *testing.T) {
+	report := NewCompatibilityReport(dbx.DBTypeMySQL, dbx.DBTypePostgres)
+	name := &Field{ColumnName: "name", Type: STRING, Charset: "utf8mb4", Collation: "utf8mb4_bin"}
+	if column := report.AnalyzeCollation("users", name); column == nil || column.Level != CompatibilityExact {
+		t.Fatalf("unexpected collation %+v", column)
+	}
+	if name.Charset != "" || name.Collation != "C" {
+		t.Errorf("field charset %q, collation %q", name.Charset, name.Collation)
+	}
+	email := &Field{ColumnName: "email", Type: STRING, Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci"}
+	if column := report.AnalyzeCollation("users", email); column == nil || column.Reason == "" || email.Collation != "" {
+		t.Errorf("unexpected collation %+v, field collation %q", column, email.Collation)
+	}
+	id := &Field{ColumnName: "id", Type: INT64, Charset: "utf8mb4", Collation: "utf8mb4_bin"}
+	if report.AnalyzeCollation("users", id) != nil || id.Charset != "" || id.Collation != "" {
+		t.Errorf("unexpected collation for %+v", id)
+	}
+	if len(report.Collations) != 2 || report.CountCollations(CompatibilityLossy) != 1 {
+		t.Errorf("unexpected collations %+v", report.Collations)
+	}
+
+	// oracle 不读取列的排序规则，按默认的二进制比较映射
+	report = NewCompatibilityReport(dbx.DBTypeOracle, dbx.DBTypeMySQL)
+	code := &Field{ColumnName: "CODE", Type: STRING}
+	if column := report.AnalyzeCollation("ORDERS", code); column == nil || !strings.Contains(column.Reason, "collation unknown") {
+		t.Errorf("unexpected collation %+v", column)
+	}
+	if code.Charset != "utf8mb4" || code.Collation != "utf8mb4_bin" {
+		t.Errorf("field charset %q, collation %q", code.Charset, code.Collation)
+	}
+	report = NewCompatibilityReport(dbx.DBTypeOracle, dbx.DBTypeOracle)
+	if report.AnalyzeCollation("ORDERS", &Field{ColumnName: "CODE", Type: STRING}) != nil || len(report.Collations) != 0 {
+		t.Errorf("unexpected collations %+v", report.Collations)
+	}
+}
@@ -0,0 +1,431 @@
+package dboperator
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// Compatibility 字段类型转换的兼容程度
+type Compatibility string
+
+const (
+	CompatibilityExact       Compatibility = "exact"       // 类型一致，取值范围不变
+	CompatibilityWidened     Compatibility = "widened"     // 目标类型取值范围更大，数据可完整迁移
+	CompatibilityLossy       Compatibility = "lossy"       // 目标类型取值范围更小或丢失精度、时区等信息，迁移数据可能失败或被截断
+	CompatibilityUnsupported Compatibility = "unsupported" // 目标库无对应类型，数据无法直接迁移
+)
+
+// integerDigits 各整数类型可保存的十进制位数
+var integerDigits = map[FieldType]int{INT8: 3, INT16: 5, INT32: 10, INT64: 19}
+
+// integerBits 各整数类型的位数，用于比较整数类型的取值范围
+var integerBits = map[FieldType]int{INT8: 8, INT16: 16, INT32: 32, INT64: 64}
+
+// defaultDecimalPrecision 未指定精度的定点数在各数据库中的默认精度，未列出的数据库不限精度
+var defaultDecimalPrecision = map[dbx.DBType]int{
+	dbx.DBTypeMySQL:     10,
+	dbx.DBTypeSqlserver: 18,
+}
+
+// timeKinds 时间类型归一化，oracle/dm的date含时间部分
+var timeKinds = map[string]string{
+	"date":         "date",
+	"datetime":     "timestamp",
+	"timestamp":    "timestamp",
+	"timestamptz":  "timestamptz",
+	"timestampltz": "timestamptz",
+	"time":         "time",
+	"timetz":       "timetz",
+	"year":         "year",
+}
+
+// CompatibilityReport 源库到目标库的字段类型兼容性报告
+type CompatibilityReport struct {
+	SourceDBType dbx.DBType             `json:"source_db_type"`
+	TargetDBType dbx.DBType             `json:"target_db_type"`
+	Columns      []*ColumnCompatibility `json:"columns"`
+	// Collations 字符类型字段的字符集、排序规则映射，源库未提供字符集及排序规则的字段不记录
+	Collations []*CollationCompatibility `json:"collations,omitempty"`
+	// Defaults 无法移植到目标库而未生成的默认值
+	Defaults []*DefaultCompatibility `json:"defaults,omitempty"`
+}
+
+// ColumnCompatibility 单个字段的类型兼容性
+type ColumnCompatibility struct {
+	TableName  string        `json:"table_name"`
+	ColumnName string        `json:"column_name"`
+	SourceType string        `json:"source_type"` // 源库字段类型
+	TargetType string        `json:"target_type"` // 目标库字段类型
+	Level      Compatibility `json:"level"`
+	Reason     string        `json:"reason,omitempty"` // 非 exact 时的说明
+}
+
+// CollationCompatibility 单个字段的字符集、排序规则映射，见 MapCollation
+type CollationCompatibility struct {
+	TableName       string        `json:"table_name"`
+	ColumnName      string        `json:"column_name"`
+	SourceCharset   string        `json:"source_charset,omitempty"`
+	SourceCollation string        `json:"source_collation,omitempty"`
+	TargetCharset   string        `json:"target_charset,omitempty"`
+	TargetCollation string        `json:"target_collation,omitempty"` // 为空时使用目标库默认排序规则
+	Level           Compatibility `json:"level"`
+	Reason          string        `json:"reason,omitempty"`
+}
+
+// DefaultCompatibility 单个字段未生成的默认值，见 AnalyzeDefault
+type DefaultCompatibility struct {
+	TableName    string `json:"table_name"`
+	ColumnName   string `json:"column_name"`
+	DefaultValue string `json:"default_value"` // 源库默认值
+	Reason       string `json:"reason"`
+}
+
+// NewCompatibilityReport 创建源库到目标库的兼容性报告
+func NewCompatibilityReport(sourceDBType, targetDBType dbx.DBType) *CompatibilityReport {
+	return &CompatibilityReport{
+		SourceDBType: sourceDBType,
+		TargetDBType: targetDBType,
+		Columns:      make([]*ColumnCompatibility, 0),
+	}
+}
+
+// Analyze 将通用字段按目标库类型映射，再将目标库类型解析回通用字段，比较两者取值范围并记录。
+// sourceType 为源库字段类型，target 为目标库的类型转换
+func (r *CompatibilityReport) Analyze(target ITransfer, tableName, sourceType string, field *Field) *ColumnCompatibility {
+	column := &ColumnCompatibility{
+		TableName:  tableName,
+		ColumnName: field.ColumnName,
+		SourceType: sourceType,
+		TargetType: target.Trans2DataType(field),
+	}
+	if column.TargetType == "" {
+		column.Level, column.Reason = CompatibilityUnsupported, "no target type"
+	} else {
+		column.Level, column.Reason = CompareField(r.SourceDBType, r.TargetDBType, field, target.Trans2CommonField(column.TargetType))
+	}
+	r.Columns = append(r.Columns, column)
+	return column
+}
+
+// AnalyzeDefault 源库方言的默认值表达式仅在同类数据库间原样保留，否则清空 field 的默认值并记录，未清空时返回 nil
+func (r *CompatibilityReport) AnalyzeDefault(tableName string, field *Field) *DefaultCompatibility {
+	if r.SourceDBType == r.TargetDBType || IsPortableDefault(field.DefaultValue) {
+		return nil
+	}
+	column := &DefaultCompatibility{
+		TableName:    tableName,
+		ColumnName:   field.ColumnName,
+		DefaultValue: field.DefaultValue,
+		Reason:       fmt.Sprintf("expression is not portable from %s to %s, default dropped", r.SourceDBType, r.TargetDBType),
+	}
+	field.DefaultValue = ""
+	r.Defaults = append(r.Defaults, column)
+	return column
+}
+
+// AnalyzeCollation 将字符类型字段的字符集、排序规则映射为目标库写法并记录，field 的 Charset、Collation 改为目标库写法；
+// 源库未提供字符集及排序规则(oracle、达梦、sqlite 不读取)时按源库默认的比较语义映射，并在 reason 中注明排序规则未知；
+// 非字符类型，或同类数据库间未提供时清空并返回 nil
+func (r *CompatibilityReport) AnalyzeCollation(tableName string, field *Field) *CollationCompatibility {
+	unknown := field.Charset == "" && field.Collation == ""
+	if !IsCharacterField(field) || unknown && r.SourceDBType == r.TargetDBType {
+		field.Charset, field.Collation = "", ""
+		return nil
+	}
+	column := &CollationCompatibility{
+		TableName:       tableName,
+		ColumnName:      field.ColumnName,
+		SourceCharset:   field.Charset,
+		SourceCollation: field.Collation,
+	}
+	column.TargetCharset, column.TargetCollation, column.Level, column.Reason = MapCollation(r.SourceDBType, r.TargetDBType, field.Charset, field.Collation)
+	if unknown {
+		reason := fmt.Sprintf("collation unknown, assumed %s default (%s)", r.SourceDBType, parseCollation(r.SourceDBType, ""))
+		column.Reason = strings.TrimSuffix(reason+", "+column.Reason, ", ")
+	}
+	field.Charset, field.Collation = column.TargetCharset, column.TargetCollation
+	r.Collations = append(r.Collations, column)
+	return column
+}
+
+// Count 统计指定兼容程度的字段数
+func (r *CompatibilityReport) Count(level Compatibility) (count int) {
+	for _, column := range r.Columns {
+		if column.Level == level {
+			count++
+		}
+	}
+	return
+}
+
+// CountCollations 统计字符集、排序规则映射为指定兼容程度的字段数
+func (r *CompatibilityReport) CountCollations(level Compatibility) (count int) {
+	for _, column := range r.Collations {
+		if column.Level == level {
+			count++
+		}
+	}
+	return
+}
+
+// WriteJSON 将报告以json格式写入文件
+func (r *CompatibilityReport) WriteJSON(filePath string) (err error) {
+	content, err := sonic.ConfigStd.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return
+	}
+	return os.WriteFile(filePath, content, 0644)
+}
+
+// CompareField 比较源字段与目标库保存该字段的类型，target 为目标库类型解析出的通用字段
+func CompareField(sourceDBType, targetDBType dbx.DBType, source, target *Field) (level Compatibility, reason string) {
+	if target == nil {
+		return CompatibilityUnsupported, "target type cannot be resolved"
+	}
+	if source.Type == target.Type {
+		return compareSameType(sourceDBType, targetDBType, source, target)
+	}
+	switch {
+	case target.Type == STRING:
+		return compareToString(source, target)
+	case isNumericField(source) && isNumericField(target):
+		return compareNumber(sourceDBType, targetDBType, source, target)
+	case source.Type == ARRAY && target.Type == JSON:
+		return CompatibilityWidened, "array stored as json"
+	case source.Type == SET && target.Type == ENUM, source.Type == ENUM && target.Type == SET:
+		return CompatibilityLossy, fmt.Sprintf("%s stored as %s", source.Type, target.Type)
+	case source.Type == STRING:
+		return CompatibilityLossy, fmt.Sprintf("string stored as %s", target.Type)
+	}
+	return CompatibilityUnsupported, fmt.Sprintf("%s cannot be stored as %s", describeField(source), describeField(target))
+}
+
+func compareSameType(sourceDBType, targetDBType dbx.DBType, source, target *Field) (level Compatibility, reason string) {
+	switch source.Type {
+	case INT8, INT16, INT32, INT64, FLOAT32, FLOAT64:
+		return compareNumber(sourceDBType, targetDBType, source, target)
+	case STRING:
+		return compareString(source, target)
+	case TIME:
+		return compareTime(source, target)
+	case ENUM, SET:
+		for _, value := range source.EnumValues {
+			if len(target.EnumValues) > 0 && !slices.Contains(target.EnumValues, value) {
+				return CompatibilityLossy, fmt.Sprintf("value '%s' is not allowed", value)
+			}
+		}
+	case ARRAY:
+		if source.ElementType != nil && target.ElementType != nil {
+			level, reason = CompareField(sourceDBType, targetDBType, source.ElementType, target.ElementType)
+			if level != CompatibilityExact {
+				reason = "array element " + reason
+			}
+			return
+		}
+	case INTERVAL:
+		if source.IntervalType != target.IntervalType {
+			if target.IntervalType == "" {
+				return CompatibilityWidened, "interval range unrestricted"
+			}
+			return CompatibilityLossy, fmt.Sprintf("%s stored as %s", describeField(source), describeField(target))
+		}
+	case GEOMETRY:
+		if source.GeometryType != target.GeometryType {
+			if target.GeometryType == "" && source.GeometryType != "geography" {
+				return CompatibilityWidened, fmt.Sprintf("%s stored as geometry", source.GeometryType)
+			}
+			return CompatibilityLossy, fmt.Sprintf("%s stored as %s", describeField(source), describeField(target))
+		}
+	}
+	return CompatibilityExact, ""
+}
+
+// compareToString 以字符串保存其他类型，可完整保存字面值时为 widened
+func compareToString(source, target *Field) (level Compatibility, reason string) {
+	var length int
+	switch source.Type {
+	case GEOMETRY, BYTES, RUNES:
+		return CompatibilityUnsupported, fmt.Sprintf("%s cannot be stored as string", describeField(source))
+	case UUID:
+		length = 36
+	case ENUM, SET:
+		length = EnumLength(source)
+	case BOOL, INT8, INT16, INT32, INT64:
+		length = integerDigits[source.Type] + 1
+	case TIME:
+		length = 40
+	case JSON, ARRAY, XML, INTERVAL, FLOAT32, FLOAT64:
+		length = -1
+	}
+	if target.IsText || target.Length <= 0 || (length > 0 && target.Length >= length) {
+		return CompatibilityWidened, fmt.Sprintf("%s stored as %s", describeField(source), describeField(target))
+	}
+	return CompatibilityLossy, fmt.Sprintf("%s stored as %s", describeField(source), describeField(target))
+}
+
+func compareString(source, target *Field) (level Compatibility, reason string) {
+	sourceUnbounded := source.IsText || source.Length <= 0
+	targetUnbounded := target.IsText || target.Length <= 0
+	switch {
+	case sourceUnbounded && targetUnbounded:
+		return CompatibilityExact, ""
+	case targetUnbounded:
+		return CompatibilityWidened, fmt.Sprintf("%s stored as %s", describeField(source), describeField(target))
+	case sourceUnbounded || target.Length < source.Length:
+		return CompatibilityLossy, fmt.Sprintf("%s narrowed to %s", describeField(source), describeField(target))
+	case target.Length > source.Length:
+		return CompatibilityWidened, fmt.Sprintf("%s stored as %s", describeField(source), describeField(target))
+	}
+	return CompatibilityExact, ""
+}
+
+func compareTime(source, target *Field) (level Compatibility, reason string) {
+	sourceKind, targetKind := timeKinds[source.TimeType], timeKinds[target.TimeType]
+	if sourceKind == "" {
+		sourceKind = "timestamp"
+	}
+	if targetKind == "" {
+		targetKind = "timestamp"
+	}
+	switch {
+	case sourceKind == targetKind:
+		if source.Length > 0 && target.Length > 0 && target.Length < source.Length {
+			return CompatibilityLossy, fmt.Sprintf("fractional seconds narrowed from %d to %d", source.Length, target.Length)
+		}
+		return CompatibilityExact, ""
+	case sourceKind == "date" && (targetKind == "timestamp" || targetKind == "timestamptz"),
+		sourceKind == "timestamp" && targetKind == "timestamptz",
+		sourceKind == "time" && targetKind == "timetz":
+		return CompatibilityWidened, fmt.Sprintf("%s stored as %s", sourceKind, targetKind)
+	case sourceKind == "timestamptz" && targetKind == "timestamp", sourceKind == "timetz" && targetKind == "time":
+		return CompatibilityLossy, fmt.Sprintf("%s stored as %s, time zone is dropped", sourceKind, targetKind)
+	}
+	return CompatibilityLossy, fmt.Sprintf("%s stored as %s", sourceKind, targetKind)
+}
+
+func compareNumber(sourceDBType, targetDBType dbx.DBType, source, target *Field) (level Compatibility, reason string) {
+	sourceDigits, sourceScale, sourceExact := numberRange(sourceDBType, source)
+	targetDigits, targetScale, targetExact := numberRange(targetDBType, target)
+	describe := fmt.Sprintf("%s stored as %s", describeNumber(sourceDBType, source), describeNumber(targetDBType, target))
+	switch {
+	case sourceExact && !targetExact:
+		// 浮点数可精确保存15位以内的整数
+		if source.Type == INT8 || source.Type == INT16 || (source.Type == INT32 && target.Type == FLOAT64) {
+			return CompatibilityWidened, describe
+		}
+		return CompatibilityLossy, describe + ", precision may be lost"
+	case !sourceExact && targetExact:
+		return CompatibilityLossy, describe + ", floating point value is rounded"
+	case !sourceExact:
+		if source.Type == FLOAT64 && target.Type == FLOAT32 {
+			return CompatibilityLossy, describe
+		}
+		if source.Type != target.Type {
+			return CompatibilityWidened, describe
+		}
+		return CompatibilityExact, ""
+	}
+	if source.IsUnsigned != target.IsUnsigned && source.Type == target.Type {
+		return CompatibilityLossy, describe
+	}
+	if source.IsUnsigned && !target.IsUnsigned && integerBits[target.Type] > integerBits[source.Type] {
+		return CompatibilityWidened, describe
+	}
+	// 0 表示不限精度
+	switch {
+	case sourceDigits == targetDigits && sourceScale == targetScale:
+		if source.Type == target.Type {
+			return CompatibilityExact, ""
+		}
+		return CompatibilityWidened, describe
+	case targetDigits == 0 && targetScale >= sourceScale:
+		return CompatibilityWidened, describe
+	case sourceDigits == 0 || targetScale < sourceScale || (targetDigits > 0 && targetDigits < sourceDigits):
+		return CompatibilityLossy, describe
+	}
+	return CompatibilityWidened, describe
+}
+
+// numberRange 数值类型的整数位数及小数位数，整数位数为0时不限精度；exact 区分定点数及浮点数
+func numberRange(dbType dbx.DBType, field *Field) (digits, scale int, exact bool) {
+	switch field.Type {
+	case BOOL:
+		return 1, 0, true
+	case INT8, INT16, INT32, INT64:
+		digits = integerDigits[field.Type]
+		if field.IsUnsigned && field.Type == INT64 {
+			digits = 20
+		}
+		return digits, 0, true
+	case FLOAT64:
+		if !field.IsFixedNumber {
+			return 0, 0, false
+		}
+		precision, scale := field.Precision, max(field.Scale, 0)
+		if precision <= 0 {
+			precision = defaultDecimalPrecision[dbType]
+		}
+		if precision <= 0 {
+			return 0, scale, true
+		}
+		return max(precision-scale, 1), scale, true
+	}
+	return 0, 0, false
+}
+
+// describeNumber 未指定精度的定点数按数据库默认精度说明
+func describeNumber(dbType dbx.DBType, field *Field) string {
+	if precision := defaultDecimalPrecision[dbType]; field.Type == FLOAT64 && field.IsFixedNumber && field.Precision <= 0 && precision > 0 {
+		return fmt.Sprintf("decimal(%d,0)", precision)
+	}
+	return describeField(field)
+}
+
+func isNumericField(field *Field) bool {
+	switch field.Type {
+	case BOOL, INT8, INT16, INT32, INT64, FLOAT32, FLOAT64:
+		return true
+	}
+	return false
+}
+
+// describeField 字段类型的简要说明，用于报告
+func describeField(field *Field) string {
+	switch field.Type {
+	case STRING:
+		if field.IsText || field.Length <= 0 {
+			return "text"
+		}
+		return fmt.Sprintf("string(%d)", field.Length)
+	case FLOAT64:
+		if !field.IsFixedNumber {
+			return "double"
+		}
+		if field.Precision <= 0 {
+			return "decimal"
+		}
+		return fmt.Sprintf("decimal(%d,%d)", field.Precision, max(field.Scale, 0))
+	case TIME:
+		if field.TimeType != "" {
+			return field.TimeType
+		}
+	case INTERVAL:
+		if field.IntervalType != "" {
+			return "interval " + field.IntervalType
+		}
+	case GEOMETRY:
+		if field.GeometryType != "" {
+			return field.GeometryType
+		}
+	}
+	if field.IsUnsigned {
+		return string(field.Type) + " unsigned"
+	}
+	return string(field.Type)
+}
@@ -0,0 +1,56 @@
+package dboperator
+
+import (
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestCompareField(t *testing.T) {
+	cases := []struct {
+		name           string
+		source, target *Field
+		targetDBType   dbx.DBType
+		expected       Compatibility
+	}{
+		{"timestamptz to datetime", &Field{Type: TIME, TimeType: "timestamptz"}, &Field{Type: TIME, TimeType: "datetime"}, dbx.DBTypeMySQL, CompatibilityLossy},
+		{"date to timestamp", &Field{Type: TIME, TimeType: "date"}, &Field{Type: TIME, TimeType: "timestamp"}, dbx.DBTypePostgres, CompatibilityWidened},
+		{"numeric to decimal", &Field{Type: FLOAT64, IsFixedNumber: true}, &Field{Type: FLOAT64, IsFixedNumber: true}, dbx.DBTypeSqlserver, CompatibilityLossy},
+		{"numeric to number", &Field{Type: FLOAT64, IsFixedNumber: true}, &Field{Type: FLOAT64, IsFixedNumber: true}, dbx.DBTypeOracle, CompatibilityExact},
+		{"text to varchar2", &Field{Type: STRING, IsText: true}, &Field{Type: STRING, Length: 500}, dbx.DBTypeOracle, CompatibilityLossy},
+		{"varchar to text", &Field{Type: STRING, Length: 20}, &Field{Type: STRING, IsText: true}, dbx.DBTypePostgres, CompatibilityWidened},
+		{"int to bigint", &Field{Type: INT32}, &Field{Type: INT64}, dbx.DBTypePostgres, CompatibilityWidened},
+		{"bigint to double", &Field{Type: INT64}, &Field{Type: FLOAT64}, dbx.DBTypePostgres, CompatibilityLossy},
+		{"bigint unsigned to decimal", &Field{Type: INT64, IsUnsigned: true}, &Field{Type: FLOAT64, IsFixedNumber: true, Precision: 20}, dbx.DBTypePostgres, CompatibilityWidened},
+		{"decimal to int", &Field{Type: FLOAT64, IsFixedNumber: true, Precision: 10, Scale: 2}, &Field{Type: INT64}, dbx.DBTypePostgres, CompatibilityLossy},
+		{"uuid to char", &Field{Type: UUID}, &Field{Type: STRING, Length: 36}, dbx.DBTypeMySQL, CompatibilityWidened},
+		{"geometry to clob", &Field{Type: GEOMETRY}, &Field{Type: STRING, IsText: true}, dbx.DBTypeDM, CompatibilityUnsupported},
+		{"enum missing value", &Field{Type: ENUM, EnumValues: []string{"a", "b"}}, &Field{Type: ENUM, EnumValues: []string{"a"}}, dbx.DBTypeMySQL, CompatibilityLossy},
+	}
+	for _, c := range cases {
+		if level, reason := CompareField(dbx.DBTypePostgres, c.targetDBType, c.source, c.target); level != c.expected {
+			t.Errorf("%s: CompareField = %s (%s), expected %s", c.name, level, reason, c.expected)
+		}
+	}
+}
+
+func TestAnalyzeDefault(t *testing.T) {
+	report := NewCompatibilityReport(dbx.DBTypeSqlserver, dbx.DBTypePostgres)
+	id := &Field{ColumnName: "id", Type: UUID, DefaultValue: "newid()"}
+	if dropped := report.AnalyzeDefault("users", id); dropped == nil || dropped.DefaultValue != "newid()" || id.DefaultValue != "" {
+		t.Errorf("unexpected default %+v, field default %q", dropped, id.DefaultValue)
+	}
+	created := &Field{ColumnName: "created_at", Type: TIME, DefaultValue: DefaultCurrentTimestamp}
+	if report.AnalyzeDefault("users", created) != nil || created.DefaultValue != DefaultCurrentTimestamp {
+		t.Errorf("unexpected dropped default %q", created.DefaultValue)
+	}
+	if len(report.Defaults) != 1 {
+		t.Errorf("unexpected defaults %+v", report.Defaults)
+	}
+
+	same := NewCompatibilityReport(dbx.DBTypeSqlserver, dbx.DBTypeSqlserver)
+	id.DefaultValue = "newid()"
+	if same.AnalyzeDefault("users", id) != nil || id.DefaultValue != "newid()" {
+		t.Errorf("default dropped between same dialects")
+	}
+}
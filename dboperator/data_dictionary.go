@@ -0,0 +1,341 @@
+package dboperator
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// docSpan 单元格中的一段文本，anchor 非空时链接到该锚点
+type docSpan struct {
+	text   string
+	anchor string
+}
+
+// docCell 数据字典表格的单元格
+type docCell []docSpan
+
+// docSection 表下的一节，如列、约束、索引
+type docSection struct {
+	title  string
+	header []string
+	rows   [][]docCell
+}
+
+// docTable 数据字典中的一张表
+type docTable struct {
+	name     string
+	anchor   string
+	comment  string
+	sections []*docSection
+}
+
+// dataDictionary Markdown 与 HTML 共用的数据字典内容，表按名称排序
+type dataDictionary struct {
+	title    string
+	summary  string
+	contents *docSection
+	tables   []*docTable
+}
+
+// GenerateMarkdown 生成模式的 Markdown 数据字典：目录及各表的列、约束、索引和引用该表的外键，
+// 外键链接到字典中被引用的表，锚点以 HTML 写入以免依赖渲染器生成的标题锚点
+func GenerateMarkdown(schema *Schema) []byte {
+	dictionary := newDataDictionary(schema)
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "# %s\n\n%s\n\n## Tables\n\n", markdownText(dictionary.title), markdownText(dictionary.summary))
+	writeMarkdownSection(&buffer, dictionary.contents)
+	for _, table := range dictionary.tables {
+		fmt.Fprintf(&buffer, "<a id=\"%s\"></a>\n\n## %s\n\n", table.anchor, markdownText(table.name))
+		if table.comment != "" {
+			fmt.Fprintf(&buffer, "%s\n\n", markdownText(table.comment))
+		}
+		for _, section := range table.sections {
+			fmt.Fprintf(&buffer, "### %s\n\n", section.title)
+			writeMarkdownSection(&buffer, section)
+		}
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n"))
+}
+
+func writeMarkdownSection(buffer *bytes.Buffer, section *docSection) {
+	buffer.WriteString("| " + strings.Join(section.header, " | ") + " |\n|")
+	buffer.WriteString(strings.Repeat(" --- |", len(section.header)) + "\n")
+	for _, row := range section.rows {
+		buffer.WriteString("|")
+		for _, cell := range row {
+			buffer.WriteString(" ")
+			for _, span := range cell {
+				if span.anchor == "" {
+					buffer.WriteString(markdownText(span.text))
+					continue
+				}
+				fmt.Fprintf(buffer, "[%s](#%s)", strings.NewReplacer("[", `\[`, "]", `\]`).Replace(markdownText(span.text)), span.anchor)
+			}
+			buffer.WriteString(" |")
+		}
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("\n")
+}
+
+// markdownText 转义表格分隔符及尖括号，换行改为 <br>
+func markdownText(text string) string {
+	return strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;", "\r\n", "<br>", "\n", "<br>").Replace(text)
+}
+
+// htmlStyle 内联样式，生成的 HTML 不依赖外部资源
+const htmlStyle = `body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:0 auto;max-width:1200px;padding:0 24px 48px;color:#24292f}
+table{border-collapse:collapse;margin:8px 0 16px;width:100%}th,td{border:1px solid #d0d7de;padding:4px 8px;text-align:left;vertical-align:top}
+th{background:#f6f8fa}tr:nth-child(even) td{background:#fbfbfc}h2{border-bottom:1px solid #d0d7de;padding-bottom:4px;margin-top:32px}
+a{color:#0969da;text-decoration:none}a:hover{text-decoration:underline}.comment{color:#57606a}`
+
+// GenerateHTML 生成自包含的 HTML 数据字典，样式内联、不引用外部资源，内容同 GenerateMarkdown
+func GenerateHTML(schema *Schema) []byte {
+	dictionary := newDataDictionary(schema)
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n",
+		html.EscapeString(dictionary.title), htmlStyle)
+	fmt.Fprintf(&buffer, "<h1>%s</h1>\n<p>%s</p>\n<nav>\n<h2>Tables</h2>\n", html.EscapeString(dictionary.title), html.EscapeString(dictionary.summary))
+	writeHTMLSection(&buffer, dictionary.contents)
+	buffer.WriteString("</nav>\n")
+	for _, table := range dictionary.tables {
+		fmt.Fprintf(&buffer, "<section id=\"%s\">\n<h2>%s</h2>\n", table.anchor, html.EscapeString(table.name))
+		if table.comment != "" {
+			fmt.Fprintf(&buffer, "<p class=\"comment\">%s</p>\n", htmlText(table.comment))
+		}
+		for _, section := range table.sections {
+			fmt.Fprintf(&buffer, "<h3>%s</h3>\n", section.title)
+			writeHTMLSection(&buffer, section)
+		}
+		buffer.WriteString("</section>\n")
+	}
+	buffer.WriteString("</body>\n</html>\n")
+	return buffer.Bytes()
+}
+
+func writeHTMLSection(buffer *bytes.Buffer, section *docSection) {
+	buffer.WriteString("<table>\n<tr>")
+	for _, header := range section.header {
+		fmt.Fprintf(buffer, "<th>%s</th>", header)
+	}
+	buffer.WriteString("</tr>\n")
+	for _, row := range section.rows {
+		buffer.WriteString("<tr>")
+		for _, cell := range row {
+			buffer.WriteString("<td>")
+			for _, span := range cell {
+				if span.anchor == "" {
+					buffer.WriteString(htmlText(span.text))
+					continue
+				}
+				fmt.Fprintf(buffer, "<a href=\"#%s\">%s</a>", span.anchor, htmlText(span.text))
+			}
+			buffer.WriteString("</td>")
+		}
+		buffer.WriteString("</tr>\n")
+	}
+	buffer.WriteString("</table>\n")
+}
+
+// htmlText 转义文本，换行改为 <br>
+func htmlText(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(html.EscapeString(text), "\r\n", "<br>"), "\n", "<br>")
+}
+
+func newDataDictionary(schema *Schema) *dataDictionary {
+	tables := append([]*Table(nil), schema.Tables...)
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	anchors := make(map[string]string, len(tables))
+	usedAnchors := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		anchors[table.Name] = uniqueAnchor("table-"+anchorName(table.Name), usedAnchors)
+	}
+	// 被引用的表在字典中时链接到该表，其他模式的表不链接
+	refAnchor := func(constraint *Constraint) string {
+		if constraint.RefSchema != "" && schema.Name != "" && constraint.RefSchema != schema.Name {
+			return ""
+		}
+		return anchors[constraint.RefTable]
+	}
+	referencedRows := make(map[string][][]docCell)
+	for _, table := range tables {
+		for _, constraint := range table.ConstraintsOf(ConstraintForeignKey) {
+			if refAnchor(constraint) == "" {
+				continue
+			}
+			referencedRows[constraint.RefTable] = append(referencedRows[constraint.RefTable], []docCell{
+				{{text: table.Name, anchor: anchors[table.Name]}},
+				{{text: strings.Join(constraint.Columns, ", ")}},
+				{{text: strings.Join(constraint.RefColumns, ", ")}},
+				{{text: constraint.Name}},
+			})
+		}
+	}
+
+	dictionary := &dataDictionary{
+		title:    "Data dictionary: " + schema.Name,
+		summary:  fmt.Sprintf("%s, %d tables", schema.DBType, len(tables)),
+		contents: &docSection{header: []string{"Table", "Columns", "Comment"}},
+	}
+	if schema.Name == "" {
+		dictionary.title = "Data dictionary"
+	}
+	for _, table := range tables {
+		dictionary.contents.rows = append(dictionary.contents.rows, []docCell{
+			{{text: table.Name, anchor: anchors[table.Name]}},
+			{{text: strconv.Itoa(len(table.Columns))}},
+			{{text: table.Comment}},
+		})
+		document := &docTable{name: table.Name, anchor: anchors[table.Name], comment: table.Comment}
+		document.sections = append(document.sections, columnSection(table, refAnchor))
+		if section := constraintSection(table, refAnchor); len(section.rows) > 0 {
+			document.sections = append(document.sections, section)
+		}
+		if section := indexSection(table); len(section.rows) > 0 {
+			document.sections = append(document.sections, section)
+		}
+		if rows := referencedRows[table.Name]; len(rows) > 0 {
+			document.sections = append(document.sections, &docSection{title: "Referenced by",
+				header: []string{"Table", "Columns", "Referenced columns", "Constraint"}, rows: rows})
+		}
+		dictionary.tables = append(dictionary.tables, document)
+	}
+	return dictionary
+}
+
+// columnSection 列的序号、类型、可空、默认值、所属键及注释，外键列链接到被引用表
+func columnSection(table *Table, refAnchor func(*Constraint) string) *docSection {
+	keys := make(map[string]docCell)
+	addKey := func(column string, spans ...docSpan) {
+		if len(keys[column]) > 0 {
+			keys[column] = append(keys[column], docSpan{text: ", "})
+		}
+		keys[column] = append(keys[column], spans...)
+	}
+	for _, constraint := range table.Constraints {
+		for i, column := range constraint.Columns {
+			switch constraint.Type {
+			case ConstraintPrimaryKey:
+				addKey(column, docSpan{text: "PK"})
+			case ConstraintUnique:
+				addKey(column, docSpan{text: "UK"})
+			case ConstraintForeignKey:
+				target := constraint.RefTable
+				if i < len(constraint.RefColumns) {
+					target += "." + constraint.RefColumns[i]
+				}
+				addKey(column, docSpan{text: "FK → "}, docSpan{text: target, anchor: refAnchor(constraint)})
+			}
+		}
+	}
+
+	section := &docSection{title: "Columns", header: []string{"#", "Column", "Type", "Nullable", "Default", "Key", "Comment"}}
+	for i, column := range table.Columns {
+		nullable, defaultValue := "NO", column.Default
+		if column.Nullable {
+			nullable = "YES"
+		}
+		if column.AutoIncrement {
+			defaultValue = strings.TrimPrefix(defaultValue+" auto increment", " ")
+		}
+		section.rows = append(section.rows, []docCell{
+			{{text: strconv.Itoa(i + 1)}},
+			{{text: column.Name}},
+			{{text: column.DataType}},
+			{{text: nullable}},
+			{{text: defaultValue}},
+			keys[column.Name],
+			{{text: column.Comment}},
+		})
+	}
+	return section
+}
+
+// constraintSection 主键、唯一键、外键及检查约束，外键链接到被引用表
+func constraintSection(table *Table, refAnchor func(*Constraint) string) *docSection {
+	section := &docSection{title: "Constraints", header: []string{"Name", "Type", "Columns", "Definition"}}
+	for _, constraint := range table.Constraints {
+		definition := docCell{}
+		switch constraint.Type {
+		case ConstraintForeignKey:
+			refTable := constraint.RefTable
+			if constraint.RefSchema != "" {
+				refTable = constraint.RefSchema + "." + refTable
+			}
+			definition = append(definition, docSpan{text: "REFERENCES "}, docSpan{text: refTable, anchor: refAnchor(constraint)},
+				docSpan{text: " (" + strings.Join(constraint.RefColumns, ", ") + ")"})
+			if constraint.OnDelete != "" {
+				definition = append(definition, docSpan{text: " ON DELETE " + constraint.OnDelete})
+			}
+			if constraint.OnUpdate != "" {
+				definition = append(definition, docSpan{text: " ON UPDATE " + constraint.OnUpdate})
+			}
+		case ConstraintCheck:
+			definition = append(definition, docSpan{text: constraint.Expression})
+		}
+		section.rows = append(section.rows, []docCell{
+			{{text: constraint.Name}},
+			{{text: strings.ToUpper(strings.ReplaceAll(string(constraint.Type), "_", " "))}},
+			{{text: strings.Join(constraint.Columns, ", ")}},
+			definition,
+		})
+	}
+	return section
+}
+
+// indexSection 索引的唯一性、列(降序、函数索引表达式)及部分索引条件
+func indexSection(table *Table) *docSection {
+	section := &docSection{title: "Indexes", header: []string{"Name", "Unique", "Columns", "Filter"}}
+	for _, index := range table.Indexes {
+		columns := make([]string, 0, len(index.Columns))
+		for _, column := range index.Columns {
+			name := column.ColumnName
+			if name == "" {
+				name = column.Expression
+			}
+			if column.IsDesc {
+				name += " DESC"
+			}
+			columns = append(columns, name)
+		}
+		unique := "NO"
+		if index.Unique {
+			unique = "YES"
+		}
+		section.rows = append(section.rows, []docCell{
+			{{text: index.Name}},
+			{{text: unique}},
+			{{text: strings.Join(columns, ", ")}},
+			{{text: index.Filter}},
+		})
+	}
+	return section
+}
+
+// anchorName 小写的名称，非字母数字的字符改为 -
+func anchorName(name string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			builder.WriteRune(r)
+			continue
+		}
+		builder.WriteByte('-')
+	}
+	return builder.String()
+}
+
+// uniqueAnchor 锚点已使用时追加序号
+func uniqueAnchor(anchor string, used map[string]bool) string {
+	result := anchor
+	for i := 2; used[result]; i++ {
+		result = anchor + "-" + strconv.Itoa(i)
+	}
+	used[result] = true
+	return result
+}
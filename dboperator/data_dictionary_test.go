@@ -0,0 +1,76 @@
+package dboperator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func dictionaryTestSchema() *Schema {
+	return &Schema{DBType: dbx.DBTypePostgres, Name: "app", Tables: []*Table{
+		{
+			Name:    "orders",
+			Comment: "订单",
+			Columns: []*Column{
+				{Name: "id", DataType: "int8", AutoIncrement: true},
+				{Name: "user_id", DataType: "int8", Nullable: true, Comment: "下单人|买家"},
+				{Name: "customer_id", DataType: "int8", Nullable: true},
+			},
+			Constraints: []*Constraint{
+				{Name: "orders_pkey", Type: ConstraintPrimaryKey, Columns: []string{"id"}},
+				{Name: "orders_user_id_fkey", Type: ConstraintForeignKey, Columns: []string{"user_id"},
+					RefSchema: "app", RefTable: "Users", RefColumns: []string{"id"}, OnDelete: ActionCascade},
+				{Name: "orders_customer_fkey", Type: ConstraintForeignKey, Columns: []string{"customer_id"},
+					RefSchema: "crm", RefTable: "customers", RefColumns: []string{"id"}},
+			},
+			Indexes: []*Index{{Name: "orders_user_idx", Columns: []*IndexColumn{{ColumnName: "user_id", IsDesc: true}}}},
+		},
+		{
+			Name:    "Users",
+			Comment: "用户\n<账号>",
+			Columns: []*Column{{Name: "id", DataType: "int8"}},
+			Constraints: []*Constraint{
+				{Name: "users_pkey", Type: ConstraintPrimaryKey, Columns: []string{"id"}},
+			},
+		},
+	}}
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	markdown := string(GenerateMarkdown(dictionaryTestSchema()))
+	for _, expected := range []string{
+		"# Data dictionary: app\n\npostgres, 2 tables\n\n## Tables\n\n| Table | Columns | Comment |\n| --- | --- | --- |\n| [Users](#table-users) | 1 | 用户<br>&lt;账号&gt; |\n| [orders](#table-orders) | 3 | 订单 |\n",
+		"<a id=\"table-orders\"></a>\n\n## orders\n\n订单\n\n### Columns\n\n",
+		"| 1 | id | int8 | NO | auto increment | PK |  |\n",
+		"| 2 | user_id | int8 | YES |  | FK → [Users.id](#table-users) | 下单人\\|买家 |\n",
+		"| 3 | customer_id | int8 | YES |  | FK → customers.id |  |\n",
+		"| orders_user_id_fkey | FOREIGN KEY | user_id | REFERENCES [app.Users](#table-users) (id) ON DELETE CASCADE |\n",
+		"| orders_customer_fkey | FOREIGN KEY | customer_id | REFERENCES crm.customers (id) |\n",
+		"### Indexes\n\n| Name | Unique | Columns | Filter |\n| --- | --- | --- | --- |\n| orders_user_idx | NO | user_id DESC |  |\n",
+		"### Referenced by\n\n| Table | Columns | Referenced columns | Constraint |\n| --- | --- | --- | --- |\n| [orders](#table-orders) | user_id | id | orders_user_id_fkey |",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("missing %q in:\n%s", expected, markdown)
+		}
+	}
+}
+
+func TestGenerateHTML(t *testing.T) {
+	document := string(GenerateHTML(dictionaryTestSchema()))
+	for _, expected := range []string{
+		"<!DOCTYPE html>",
+		"<title>Data dictionary: app</title>",
+		"<td><a href=\"#table-users\">Users</a></td><td>1</td><td>用户<br>&lt;账号&gt;</td>",
+		"<section id=\"table-users\">\n<h2>Users</h2>\n<p class=\"comment\">用户<br>&lt;账号&gt;</p>",
+		"<td>FK → <a href=\"#table-users\">Users.id</a></td>",
+		"<td><a href=\"#table-orders\">orders</a></td><td>user_id</td><td>id</td><td>orders_user_id_fkey</td>",
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("missing %q in:\n%s", expected, document)
+		}
+	}
+	if strings.Contains(document, "http://") || strings.Contains(document, "https://") {
+		t.Error("html references external resources")
+	}
+}
@@ -14,14 +14,26 @@ const (
 	BOOL    FieldType = "bool"
 	STRING  FieldType = "string"
 	TIME    FieldType = "time"
+
+	JSON     FieldType = "json"
+	UUID     FieldType = "uuid"
+	ENUM     FieldType = "enum"
+	SET      FieldType = "set"
+	ARRAY    FieldType = "array"
+	INTERVAL FieldType = "interval"
+	XML      FieldType = "xml"
+	GEOMETRY FieldType = "geometry"
 )
 
 type Field struct {
 	Type          FieldType
 	ColumnName    string
 	ISNullable    bool
+	DefaultValue  string // 默认值，已归一化为通用表达式，见 NormalizeDefaultValue
+	Comment       string // 字段注释
 	IsText        bool   // 区分字符串和文本
 	IsFixedNumber bool   // 区分浮点数和定点数
+	IsUnsigned    bool   // 区分有符号和无符号整数，目标库无无符号类型时见 WidenUnsigned
 	TimeType      string // 区分时间类型 date|datetime|year|time|timetz|timestamp|timestamptz
 	StringValue   string
 	Int64Value    int64
@@ -29,50 +41,111 @@ type Field struct {
 	TimeValue     string
 	Float32Value  float32
 	Float64Value  float64
-	Length        int // 文本|时间长度
-	Scale         int // 小数点
-	Precision     int // 精度
+	Length        int      // 文本|时间长度
+	Scale         int      // 小数点
+	Precision     int      // 精度
+	EnumValues    []string // ENUM/SET 可选值
+	ElementType   *Field   // ARRAY 元素类型
+	IntervalType  string   // 区分时间间隔类型 year_month|day_second，为空时不限定
+	TargetType    string   // 目标库字段类型，由类型映射规则指定，非空时 Trans2DataType 直接使用
+	GeometryType  string   // 区分空间类型 point|linestring|polygon|multipoint|multilinestring|multipolygon|geometrycollection|geography，为空时为通用geometry
+	Charset       string   // 字符集，生成目标库表时为映射后的目标库写法，见 MapCollation
+	Collation     string   // 排序规则，同上
+	// 自增列，目标库以原生写法(auto_increment/identity/autoincrement)生成，
+	// AutoIncrementStart 为源库自增列的下一个值，迁移数据后插入不会冲突
+	IsAutoIncrement    bool
+	AutoIncrementStart int64
 }
 
 var (
 	BytesField = &Field{
-		Type: BYTES,
+		Type:       BYTES,
+		ISNullable: true,
 	}
 
 	TimeField = &Field{
-		Type: TIME,
+		Type:       TIME,
+		ISNullable: true,
 	}
 
 	BoolField = &Field{
-		Type: BOOL,
+		Type:       BOOL,
+		ISNullable: true,
 	}
 
 	StringField = &Field{
-		Type: STRING,
+		Type:       STRING,
+		ISNullable: true,
 	}
 
 	Int8Field = &Field{
-		Type: INT8,
+		Type:       INT8,
+		ISNullable: true,
 	}
 
 	Int16Field = &Field{
-		Type: INT16,
+		Type:       INT16,
+		ISNullable: true,
 	}
 
 	Int32Field = &Field{
-		Type: INT32,
+		Type:       INT32,
+		ISNullable: true,
 	}
 
 	Int64Field = &Field{
-		Type: INT64,
+		Type:       INT64,
+		ISNullable: true,
 	}
 
 	Float32Field = &Field{
-		Type: FLOAT32,
+		Type:       FLOAT32,
+		ISNullable: true,
 	}
 
 	Float64Field = &Field{
-		Type: FLOAT64,
+		Type:       FLOAT64,
+		ISNullable: true,
+	}
+
+	JSONField = &Field{
+		Type:       JSON,
+		ISNullable: true,
+	}
+
+	UUIDField = &Field{
+		Type:       UUID,
+		ISNullable: true,
+	}
+
+	EnumField = &Field{
+		Type:       ENUM,
+		ISNullable: true,
+	}
+
+	SetField = &Field{
+		Type:       SET,
+		ISNullable: true,
+	}
+
+	ArrayField = &Field{
+		Type:       ARRAY,
+		ISNullable: true,
+	}
+
+	IntervalField = &Field{
+		Type:       INTERVAL,
+		ISNullable: true,
+	}
+
+	XMLField = &Field{
+		Type:       XML,
+		ISNullable: true,
+	}
+
+	GeometryField = &Field{
+		Type:       GEOMETRY,
+		ISNullable: true,
 	}
 )
 
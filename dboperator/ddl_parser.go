@@ -0,0 +1,1178 @@
+package dboperator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// ddlToken DDL语句的词法单元，start、end 为在语句中的位置，用于截取默认值、检查条件等表达式的原文
+type ddlToken struct {
+	kind       sqlTokenKind
+	value      string // 标识符去掉引号，字符串保留引号
+	start, end int
+}
+
+// columnOptionWords 列定义中结束类型、默认值的关键字
+var columnOptionWords = map[string]bool{
+	"CONSTRAINT": true, "NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "UNIQUE": true, "CHECK": true,
+	"REFERENCES": true, "COMMENT": true, "AUTO_INCREMENT": true, "AUTOINCREMENT": true, "GENERATED": true,
+	"IDENTITY": true, "COLLATE": true, "CHARSET": true, "ON": true, "ENABLE": true, "DISABLE": true,
+	"VISIBLE": true, "INVISIBLE": true, "SRID": true, "STORAGE": true, "COLUMN_FORMAT": true, "ENCRYPT": true,
+}
+
+// pgSerialTypes postgresql 自增类型对应的整数类型
+var pgSerialTypes = map[string]string{
+	"smallserial": "int2", "serial2": "int2",
+	"serial": "int4", "serial4": "int4",
+	"bigserial": "int8", "serial8": "int8",
+}
+
+var pgTimeZoneTypeReg = regexp.MustCompile(`^(timestamp|time)(\(\d+\))? (with|without) time zone$`)
+
+// LoadDDL 读取DDL脚本文件并解析为模式快照，见 ParseDDL
+func LoadDDL(filePath string, dbType dbx.DBType, schemaName string) (schema *Schema, err error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return
+	}
+	return ParseDDL(dbType, schemaName, string(content))
+}
+
+// ParseDDL 解析 dbType 数据库的DDL脚本(如 mysqldump、pg_dump、dbms_metadata 导出的脚本)，生成与探查数据库相同的模式快照，
+// 可代替源库生成表。支持 create table 中的列、主键、唯一键、外键、检查约束及 mysql 的内联索引与表注释，
+// create index、comment on，以及 alter table 的 add 约束/列、alter column、modify；其余语句忽略。
+// 未加引号的名称按数据库的规则折叠大小写；模式限定与 schemaName 不同的表忽略，schemaName 为空时取首个模式限定。
+// 未命名的唯一键、外键、检查约束及索引按数据库的规则生成名称，如 mysql 的 t_ibfk_1、postgresql 的 t_a_key
+func ParseDDL(dbType dbx.DBType, schemaName, script string) (schema *Schema, err error) {
+	statements, err := splitDDLStatements(script, dbType)
+	if err != nil {
+		return
+	}
+	parser := &ddlParser{
+		dbType:     dbType,
+		identifier: GetIdentifierPolicy(dbType),
+		schema:     &Schema{Version: SchemaVersion, DBType: dbType, Name: schemaName, Tables: make([]*Table, 0)},
+		tables:     make(map[string]*Table),
+	}
+	for i, statement := range statements {
+		err = parser.parse(statement)
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	parser.finish()
+	schema = parser.schema
+	schema.Sort()
+	return
+}
+
+// splitDDLStatements 按分号及单独一行的 / 切分语句，去掉注释，字符串、引号标识符及 postgresql 的 $$ 内的分号不切分
+func splitDDLStatements(script string, dbType dbx.DBType) (statements []string, err error) {
+	var builder strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(builder.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		builder.Reset()
+	}
+	for i := 0; i < len(script); {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end, quoteErr := quoteEnd(script, i, dbType == dbx.DBTypeMySQL && c != '`')
+			if quoteErr != nil {
+				return nil, quoteErr
+			}
+			builder.WriteString(script[i:end])
+			i = end
+		case strings.HasPrefix(script[i:], "--") || c == '#' && dbType == dbx.DBTypeMySQL:
+			end := strings.IndexByte(script[i:], '\n')
+			if end == -1 {
+				end = len(script) - i
+			}
+			builder.WriteByte(' ')
+			i += end
+		case strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end == -1 {
+				return nil, errors.New("unterminated comment")
+			}
+			builder.WriteByte(' ')
+			i += end + 4
+		case c == '$' && dbType == dbx.DBTypePostgres && (i == 0 || !isIdentifierByte(script[i-1])) && dollarTagReg.MatchString(script[i:]):
+			tag := dollarTagReg.FindString(script[i:])
+			end := strings.Index(script[i+len(tag):], tag)
+			if end == -1 {
+				return nil, errors.New("unterminated dollar-quoted string")
+			}
+			end += i + 2*len(tag)
+			builder.WriteString(script[i:end])
+			i = end
+		case c == ';':
+			flush()
+			i++
+		case c == '/' && isSlashLine(script, i):
+			flush()
+			i++
+		default:
+			builder.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return
+}
+
+var dollarTagReg = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// isSlashLine oracle 以单独一行的 / 结束语句
+func isSlashLine(script string, i int) bool {
+	lineStart := strings.LastIndexByte(script[:i], '\n') + 1
+	lineEnd := strings.IndexByte(script[i:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(script) - i
+	}
+	return strings.TrimSpace(script[lineStart:i]) == "" && strings.TrimSpace(script[i+1:i+lineEnd]) == ""
+}
+
+// quoteEnd 引号结束后的位置，连续两个引号为转义，backslash 为 true 时反斜杠转义下一个字符(mysql)
+func quoteEnd(script string, start int, backslash bool) (int, error) {
+	quote := script[start]
+	for i := start + 1; i < len(script); i++ {
+		switch {
+		case backslash && script[i] == '\\':
+			i++
+		case script[i] == quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quote %c", quote)
+}
+
+// isIdentifierByte 未加引号标识符中的字符，非ASCII字符(如中文)视为标识符的一部分
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// tokenizeDDL 切分单条语句，mysql 的双引号为字符串，其余数据库为标识符
+func tokenizeDDL(statement string, dbType dbx.DBType) (tokens []ddlToken, err error) {
+	for i := 0; i < len(statement); {
+		c := statement[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"' && dbType == dbx.DBTypeMySQL:
+			end, quoteErr := quoteEnd(statement, i, dbType == dbx.DBTypeMySQL)
+			if quoteErr != nil {
+				return nil, quoteErr
+			}
+			tokens = append(tokens, ddlToken{kind: sqlTokenString, value: statement[i:end], start: i, end: end})
+			i = end
+		case c == '"' || c == '`':
+			end, quoteErr := quoteEnd(statement, i, false)
+			if quoteErr != nil {
+				return nil, quoteErr
+			}
+			quote := string(c)
+			value := strings.ReplaceAll(statement[i+1:end-1], quote+quote, quote)
+			tokens = append(tokens, ddlToken{kind: sqlTokenIdent, value: value, start: i, end: end})
+			i = end
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(statement) && (statement[end] >= '0' && statement[end] <= '9' || statement[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, ddlToken{kind: sqlTokenNumber, value: statement[i:end], start: i, end: end})
+			i = end
+		case isIdentifierByte(c):
+			end := i
+			for end < len(statement) && isIdentifierByte(statement[end]) {
+				end++
+			}
+			tokens = append(tokens, ddlToken{kind: sqlTokenWord, value: statement[i:end], start: i, end: end})
+			i = end
+		default:
+			end := i + 1
+			if strings.HasPrefix(statement[i:], "::") {
+				end++
+			}
+			tokens = append(tokens, ddlToken{kind: sqlTokenSymbol, value: statement[i:end], start: i, end: end})
+			i = end
+		}
+	}
+	return
+}
+
+type ddlParser struct {
+	dbType     dbx.DBType
+	identifier *IdentifierPolicy
+	schema     *Schema
+	tables     map[string]*Table
+
+	// 当前语句
+	text   string
+	tokens []ddlToken
+	pos    int
+}
+
+func (p *ddlParser) parse(statement string) (err error) {
+	p.tokens, err = tokenizeDDL(statement, p.dbType)
+	if err != nil {
+		return
+	}
+	p.text, p.pos = statement, 0
+	switch {
+	case p.acceptWords("CREATE"):
+		p.acceptWords("OR", "REPLACE")
+		for p.acceptAny("GLOBAL", "LOCAL", "TEMPORARY", "TEMP", "UNLOGGED") {
+		}
+		if p.acceptWords("TABLE") {
+			return p.parseCreateTable()
+		}
+		unique := p.acceptWords("UNIQUE")
+		p.acceptAny("BITMAP", "CLUSTERED", "NONCLUSTERED")
+		if p.acceptWords("INDEX") {
+			return p.parseCreateIndex(unique)
+		}
+	case p.acceptWords("COMMENT", "ON"):
+		return p.parseComment()
+	case p.acceptWords("ALTER", "TABLE"):
+		return p.parseAlterTable()
+	}
+	return
+}
+
+func (p *ddlParser) parseCreateTable() (err error) {
+	p.acceptWords("IF", "NOT", "EXISTS")
+	names, err := p.qualifiedName()
+	if err != nil || !p.inSchema(names) {
+		return
+	}
+	// create table ... as select、like 等不含列定义的写法忽略
+	if !p.isSymbol(0, "(") {
+		return
+	}
+	p.pos++
+	name := names[len(names)-1]
+	table := &Table{Name: name, Columns: make([]*Column, 0)}
+	if old, ok := p.tables[name]; ok {
+		*old = *table
+		table = old
+	} else {
+		p.tables[name] = table
+		p.schema.Tables = append(p.schema.Tables, table)
+	}
+	for !p.isSymbol(0, ")") {
+		if p.peek(0) == nil {
+			return p.errorf("unterminated table definition")
+		}
+		if p.isTableConstraint() {
+			err = p.parseTableConstraint(table)
+		} else {
+			err = p.parseColumn(table)
+		}
+		if err != nil {
+			return
+		}
+		p.skipToElementEnd()
+		if p.isSymbol(0, ",") {
+			p.pos++
+		}
+	}
+	p.pos++
+	p.parseTableOptions(table)
+	return
+}
+
+// parseTableOptions mysql 的表注释、默认字符集、排序规则及自增起始值
+func (p *ddlParser) parseTableOptions(table *Table) {
+	for p.peek(0) != nil {
+		switch {
+		case p.acceptWords("COMMENT"):
+			p.acceptSymbol("=")
+			table.Comment = p.stringValue(p.next())
+		case p.acceptWords("CHARACTER", "SET"), p.acceptWords("CHARSET"):
+			p.acceptSymbol("=")
+			table.Charset = p.optionValue()
+		case p.acceptWords("COLLATE"):
+			p.acceptSymbol("=")
+			table.Collation = p.optionValue()
+		case p.acceptWords("AUTO_INCREMENT"):
+			p.acceptSymbol("=")
+			token := p.next()
+			if token == nil {
+				return
+			}
+			nextValue, _ := strconv.ParseInt(token.value, 10, 64)
+			for _, column := range table.Columns {
+				if column.AutoIncrement {
+					column.NextValue = nextValue
+				}
+			}
+		default:
+			p.skipBalanced()
+		}
+	}
+}
+
+// isTableConstraint 是否表级约束，mysql 的 key、index 等仅在 mysql 中视为索引，其余数据库可作为列名
+func (p *ddlParser) isTableConstraint() bool {
+	if p.isWord(0, "CONSTRAINT") || p.isWord(0, "PRIMARY") && p.isWord(1, "KEY") || p.isWord(0, "FOREIGN") && p.isWord(1, "KEY") ||
+		p.isWord(0, "UNIQUE") || p.isWord(0, "CHECK") && p.isSymbol(1, "(") || p.isWord(0, "EXCLUDE") || p.isWord(0, "LIKE") {
+		return true
+	}
+	return p.dbType == dbx.DBTypeMySQL && (p.isWord(0, "KEY") || p.isWord(0, "INDEX") || p.isWord(0, "FULLTEXT") || p.isWord(0, "SPATIAL"))
+}
+
+func (p *ddlParser) parseTableConstraint(table *Table) (err error) {
+	var name string
+	if p.acceptWords("CONSTRAINT") && !p.isWord(0, "PRIMARY") && !p.isWord(0, "UNIQUE") && !p.isWord(0, "FOREIGN") && !p.isWord(0, "CHECK") {
+		name = p.name(p.next())
+	}
+	switch {
+	case p.acceptWords("PRIMARY", "KEY"):
+		p.skipToSymbol("(")
+		columns, columnErr := p.indexColumns()
+		if columnErr != nil {
+			return columnErr
+		}
+		p.setPrimaryKey(table, name, columnNames(columns))
+	case p.acceptWords("UNIQUE"):
+		p.acceptAny("KEY", "INDEX")
+		if indexName := p.optionalName(); name == "" {
+			name = indexName
+		}
+		p.skipToSymbol("(")
+		columns, columnErr := p.indexColumns()
+		if columnErr != nil {
+			return columnErr
+		}
+		table.Constraints = append(table.Constraints, &Constraint{Name: name, Type: ConstraintUnique, Columns: columnNames(columns)})
+	case p.acceptWords("FOREIGN", "KEY"):
+		if indexName := p.optionalName(); name == "" {
+			name = indexName
+		}
+		columns, columnErr := p.indexColumns()
+		if columnErr != nil {
+			return columnErr
+		}
+		return p.parseReferences(table, name, columnNames(columns))
+	case p.acceptWords("CHECK"):
+		expression, checkErr := p.parenthesized()
+		if checkErr != nil {
+			return checkErr
+		}
+		table.Constraints = append(table.Constraints, &Constraint{Name: name, Type: ConstraintCheck, Expression: expression})
+	case p.acceptAny("KEY", "INDEX"):
+		indexName := p.optionalName()
+		p.skipToSymbol("(")
+		columns, columnErr := p.indexColumns()
+		if columnErr != nil {
+			return columnErr
+		}
+		table.Indexes = append(table.Indexes, &Index{Name: indexName, Columns: columns})
+	}
+	return
+}
+
+// parseReferences 解析 references 子句，未指定被引用列时在解析完成后取被引用表的主键
+func (p *ddlParser) parseReferences(table *Table, name string, columns []string) (err error) {
+	if !p.acceptWords("REFERENCES") {
+		return p.errorf("expected references")
+	}
+	refNames, err := p.qualifiedName()
+	if err != nil {
+		return
+	}
+	foreignKey := &Constraint{Name: name, Type: ConstraintForeignKey, Columns: columns, RefTable: refNames[len(refNames)-1]}
+	if len(refNames) > 1 {
+		foreignKey.RefSchema = refNames[len(refNames)-2]
+	}
+	if p.isSymbol(0, "(") {
+		refColumns, columnErr := p.indexColumns()
+		if columnErr != nil {
+			return columnErr
+		}
+		foreignKey.RefColumns = columnNames(refColumns)
+	}
+	for {
+		switch {
+		case p.acceptWords("MATCH"):
+			p.next()
+			continue
+		case p.acceptWords("ON", "DELETE"):
+			foreignKey.OnDelete = p.referentialAction()
+			continue
+		case p.acceptWords("ON", "UPDATE"):
+			foreignKey.OnUpdate = p.referentialAction()
+			continue
+		}
+		break
+	}
+	table.Constraints = append(table.Constraints, foreignKey)
+	return
+}
+
+func (p *ddlParser) referentialAction() string {
+	for _, action := range [][]string{{"SET", "NULL"}, {"SET", "DEFAULT"}, {"NO", "ACTION"}, {"CASCADE"}, {"RESTRICT"}} {
+		if p.acceptWords(action...) {
+			return NormalizeReferentialAction(strings.Join(action, " "))
+		}
+	}
+	return ""
+}
+
+func (p *ddlParser) parseColumn(table *Table) (err error) {
+	token := p.next()
+	if token == nil || token.kind != sqlTokenWord && token.kind != sqlTokenIdent {
+		return p.errorf("expected column name")
+	}
+	column := &Column{Name: p.name(token), Nullable: true, Position: len(table.Columns) + 1}
+	column.DataType, column.AutoIncrement = p.dataType()
+	if column.DataType == "" {
+		// sqlite 允许不声明类型的字段，其类型亲和性无法映射到其他数据库
+		if p.dbType == dbx.DBTypeSQLite {
+			return p.errorf("sqlite column " + column.Name + " without a declared type is not supported")
+		}
+		return p.errorf("expected data type of column " + column.Name)
+	}
+	table.Columns = append(table.Columns, column)
+	return p.parseColumnOptions(table, column)
+}
+
+// dataType 解析字段类型，转为与探查数据库相同的写法，如 oracle 的 VARCHAR2(10 CHAR)、VARCHAR2(10 BYTE) 为 VARCHAR2(10)，
+// 与探查 oracle 时取 CHAR_LENGTH 相同，长度按字符数记录，生成 oracle 表时以 CHAR 长度语义建表；
+// postgresql 的 serial 为 int4 且为自增列
+func (p *ddlParser) dataType() (dataType string, autoIncrement bool) {
+	upper := p.dbType == dbx.DBTypeOracle || p.dbType == dbx.DBTypeDM
+	var builder strings.Builder
+	depth := 0
+	for token := p.peek(0); token != nil; token = p.peek(0) {
+		if depth == 0 && (token.kind == sqlTokenSymbol && (token.value == "," || token.value == ")") || p.isColumnOption()) {
+			break
+		}
+		p.pos++
+		switch {
+		case token.kind == sqlTokenSymbol:
+			value := token.value
+			switch value {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case "*":
+				// oracle 的 NUMBER(*,0) 为最大精度
+				if upper && depth > 0 {
+					value = "38"
+				}
+			}
+			builder.WriteString(value)
+			continue
+		case token.kind == sqlTokenWord && depth > 0 && (strings.EqualFold(token.value, "BYTE") || strings.EqualFold(token.value, "CHAR")):
+			continue
+		}
+		if last := builder.String(); last != "" && !strings.HasSuffix(last, "(") && !strings.HasSuffix(last, ",") && !strings.HasSuffix(last, ".") {
+			builder.WriteByte(' ')
+		}
+		value := token.value
+		if token.kind == sqlTokenWord {
+			value = strings.ToLower(value)
+			if upper {
+				value = strings.ToUpper(value)
+			}
+		}
+		builder.WriteString(value)
+	}
+	dataType = builder.String()
+	if p.dbType == dbx.DBTypePostgres {
+		if intType, ok := pgSerialTypes[dataType]; ok {
+			return intType, true
+		}
+		if matches := pgTimeZoneTypeReg.FindStringSubmatch(dataType); len(matches) == 4 {
+			switch {
+			case matches[3] == "with":
+				dataType = matches[1] + "tz"
+			case matches[1] == "timestamp":
+				dataType = matches[1] + matches[2]
+			default:
+				dataType = matches[1]
+			}
+		}
+	}
+	return
+}
+
+// isColumnOption 当前位置是否列选项，character set 为选项，character varying 为类型
+func (p *ddlParser) isColumnOption() bool {
+	token := p.peek(0)
+	if token == nil || token.kind != sqlTokenWord {
+		return false
+	}
+	return columnOptionWords[strings.ToUpper(token.value)] || p.isWord(0, "CHARACTER") && p.isWord(1, "SET")
+}
+
+func (p *ddlParser) parseColumnOptions(table *Table, column *Column) (err error) {
+	var constraintName string
+	for token := p.peek(0); token != nil && !(token.kind == sqlTokenSymbol && (token.value == "," || token.value == ")")); token = p.peek(0) {
+		name := constraintName
+		constraintName = ""
+		switch {
+		case p.acceptWords("CONSTRAINT"):
+			constraintName = p.name(p.next())
+		case p.acceptWords("NOT", "NULL"):
+			column.Nullable = false
+		case p.acceptWords("NULL"):
+			column.Nullable = true
+		case p.acceptWords("DEFAULT"):
+			p.setDefault(column, p.expression())
+		case p.acceptWords("PRIMARY", "KEY"):
+			p.setPrimaryKey(table, name, []string{column.Name})
+		case p.acceptWords("UNIQUE"):
+			p.acceptWords("KEY")
+			table.Constraints = append(table.Constraints, &Constraint{Name: name, Type: ConstraintUnique, Columns: []string{column.Name}})
+		case p.isWord(0, "REFERENCES"):
+			err = p.parseReferences(table, name, []string{column.Name})
+			if err != nil {
+				return
+			}
+		case p.acceptWords("CHECK"):
+			expression, checkErr := p.parenthesized()
+			if checkErr != nil {
+				return checkErr
+			}
+			table.Constraints = append(table.Constraints, &Constraint{Name: name, Type: ConstraintCheck, Expression: expression})
+		case p.acceptWords("COMMENT"):
+			column.Comment = p.stringValue(p.next())
+		case p.acceptAny("AUTO_INCREMENT", "AUTOINCREMENT"):
+			column.AutoIncrement = true
+		case p.acceptWords("GENERATED"):
+			// identity列: generated always/by default [on null] as identity [(...)]，计算列: generated always as (expr)
+			for p.acceptAny("ALWAYS", "BY", "DEFAULT", "ON", "NULL") {
+			}
+			p.acceptWords("AS")
+			if p.acceptWords("IDENTITY") {
+				column.AutoIncrement = true
+			}
+			if p.isSymbol(0, "(") {
+				p.skipBalanced()
+			}
+		case p.acceptWords("IDENTITY"):
+			column.AutoIncrement = true
+			if p.isSymbol(0, "(") {
+				p.skipBalanced()
+			}
+		case p.acceptWords("ON", "UPDATE"):
+			p.expression()
+		case p.acceptWords("CHARACTER", "SET"), p.acceptWords("CHARSET"):
+			column.Charset = p.optionValue()
+		case p.acceptWords("COLLATE"):
+			if column.Collation = p.optionValue(); strings.EqualFold(column.Collation, "default") {
+				column.Collation = ""
+			}
+		default:
+			p.skipBalanced()
+		}
+	}
+	return
+}
+
+// setDefault 设置默认值，default null 视为无默认值，序列默认值(nextval)与探查数据库一致视为自增列
+func (p *ddlParser) setDefault(column *Column, value string) {
+	if strings.EqualFold(value, "null") {
+		value = ""
+	}
+	column.Default = value
+	if strings.HasPrefix(strings.ToLower(value), "nextval(") {
+		column.AutoIncrement = true
+	}
+}
+
+// expression 截取表达式原文，至下一个列选项或逗号为止，首个单元总是包含在内，如 default null
+func (p *ddlParser) expression() string {
+	start := p.pos
+	depth := 0
+	for token := p.peek(0); token != nil; token = p.peek(0) {
+		if depth == 0 && p.pos > start && (token.kind == sqlTokenSymbol && (token.value == "," || token.value == ")") || p.isColumnOption()) {
+			break
+		}
+		if token.kind == sqlTokenSymbol {
+			if token.value == "(" {
+				depth++
+			} else if token.value == ")" {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return ""
+	}
+	return strings.TrimSpace(p.text[p.tokens[start].start:p.tokens[p.pos-1].end])
+}
+
+func (p *ddlParser) parseCreateIndex(unique bool) (err error) {
+	p.acceptWords("CONCURRENTLY")
+	p.acceptWords("IF", "NOT", "EXISTS")
+	var indexName string
+	if !p.isWord(0, "ON") {
+		names, nameErr := p.qualifiedName()
+		if nameErr != nil {
+			return nameErr
+		}
+		indexName = names[len(names)-1]
+	}
+	if !p.acceptWords("ON") {
+		return p.errorf("expected on")
+	}
+	p.acceptWords("ONLY")
+	names, err := p.qualifiedName()
+	if err != nil {
+		return
+	}
+	table := p.lookupTable(names)
+	if table == nil {
+		return
+	}
+	if p.acceptWords("USING") {
+		p.next()
+	}
+	columns, err := p.indexColumns()
+	if err != nil {
+		return
+	}
+	// mysql的唯一索引即唯一键
+	if unique && p.dbType == dbx.DBTypeMySQL {
+		table.Constraints = append(table.Constraints, &Constraint{Name: indexName, Type: ConstraintUnique, Columns: columnNames(columns)})
+		return
+	}
+	index := &Index{Name: indexName, Unique: unique, Columns: columns}
+	for p.peek(0) != nil {
+		if p.acceptWords("WHERE") && p.peek(0) != nil {
+			index.Filter = strings.TrimSpace(p.text[p.tokens[p.pos].start:])
+			break
+		}
+		p.skipBalanced()
+	}
+	table.Indexes = append(table.Indexes, index)
+	return
+}
+
+func (p *ddlParser) parseComment() (err error) {
+	var table *Table
+	var column *Column
+	switch {
+	case p.acceptWords("TABLE"):
+		names, nameErr := p.qualifiedName()
+		if nameErr != nil {
+			return nameErr
+		}
+		table = p.lookupTable(names)
+	case p.acceptWords("COLUMN"):
+		names, nameErr := p.qualifiedName()
+		if nameErr != nil {
+			return nameErr
+		}
+		if len(names) < 2 {
+			return p.errorf("expected table name of column")
+		}
+		if table = p.lookupTable(names[:len(names)-1]); table != nil {
+			column = table.Column(names[len(names)-1])
+		}
+	default:
+		return
+	}
+	if table == nil || !p.acceptWords("IS") {
+		return
+	}
+	comment := p.stringValue(p.next())
+	if column != nil {
+		column.Comment = comment
+	} else if table != nil {
+		table.Comment = comment
+	}
+	return
+}
+
+// parseAlterTable 解析 alter table 中以逗号分隔的 add 约束/列、alter column 及 modify，其余动作忽略
+func (p *ddlParser) parseAlterTable() (err error) {
+	p.acceptWords("IF", "EXISTS")
+	p.acceptWords("ONLY")
+	names, err := p.qualifiedName()
+	if err != nil {
+		return
+	}
+	table := p.lookupTable(names)
+	if table == nil {
+		return
+	}
+	for p.peek(0) != nil {
+		switch {
+		case p.acceptWords("ADD"):
+			if p.isTableConstraint() {
+				err = p.parseTableConstraint(table)
+			} else {
+				p.acceptWords("COLUMN")
+				p.acceptWords("IF", "NOT", "EXISTS")
+				err = p.parseColumn(table)
+			}
+		case p.acceptWords("ALTER"):
+			p.acceptWords("COLUMN")
+			column := table.Column(p.name(p.next()))
+			if column == nil {
+				break
+			}
+			switch {
+			case p.acceptWords("SET", "DEFAULT"):
+				p.setDefault(column, p.expression())
+			case p.acceptWords("SET", "NOT", "NULL"):
+				column.Nullable = false
+			case p.acceptWords("DROP", "NOT", "NULL"):
+				column.Nullable = true
+			case p.acceptWords("ADD"):
+				err = p.parseColumnOptions(table, column)
+			}
+		case p.acceptWords("MODIFY"):
+			err = p.parseModify(table)
+		case p.isWord(0, "AUTO_INCREMENT") || p.isWord(0, "COMMENT"):
+			p.parseTableOptions(table)
+		default:
+			p.skipBalanced()
+		}
+		if err != nil {
+			return
+		}
+		p.skipToElementEnd()
+		p.acceptSymbol(",")
+	}
+	return
+}
+
+// parseModify mysql 的 modify 为完整的列定义，oracle 的 modify (列 选项, ...) 仅修改选项
+func (p *ddlParser) parseModify(table *Table) (err error) {
+	p.acceptWords("COLUMN")
+	if !p.acceptSymbol("(") {
+		token := p.peek(0)
+		if token == nil {
+			return
+		}
+		old := table.Column(p.name(token))
+		if old == nil {
+			return
+		}
+		modified := &Table{Name: table.Name}
+		err = p.parseColumn(modified)
+		if err != nil {
+			return
+		}
+		column := modified.Columns[0]
+		column.Position = old.Position
+		if column.Comment == "" {
+			column.Comment = old.Comment
+		}
+		*old = *column
+		for _, constraint := range modified.Constraints {
+			if constraint.Type == ConstraintPrimaryKey {
+				p.setPrimaryKey(table, constraint.Name, constraint.Columns)
+				continue
+			}
+			table.Constraints = append(table.Constraints, constraint)
+		}
+		return
+	}
+	for p.peek(0) != nil && !p.isSymbol(0, ")") {
+		if column := table.Column(p.name(p.next())); column != nil {
+			err = p.parseColumnOptions(table, column)
+			if err != nil {
+				return
+			}
+		}
+		p.skipToElementEnd()
+		p.acceptSymbol(",")
+	}
+	p.acceptSymbol(")")
+	return
+}
+
+// indexColumns 解析括号中的列，支持 mysql 的前缀长度、postgresql 的操作符类、排序及表达式
+func (p *ddlParser) indexColumns() (columns []*IndexColumn, err error) {
+	if !p.acceptSymbol("(") {
+		return nil, p.errorf("expected (")
+	}
+	for {
+		start := p.pos
+		p.skipToElementEnd()
+		if p.peek(0) == nil {
+			return nil, p.errorf("unterminated column list")
+		}
+		if p.pos > start {
+			columns = append(columns, p.indexColumn(p.tokens[start:p.pos]))
+		}
+		if p.acceptSymbol(")") {
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *ddlParser) indexColumn(tokens []ddlToken) *IndexColumn {
+	column := &IndexColumn{}
+	if n := len(tokens); n >= 2 && strings.EqualFold(tokens[n-2].value, "NULLS") {
+		tokens = tokens[:n-2]
+	}
+	if n := len(tokens); n >= 2 && tokens[n-1].kind == sqlTokenWord {
+		switch strings.ToUpper(tokens[n-1].value) {
+		case "DESC":
+			column.IsDesc = true
+			tokens = tokens[:n-1]
+		case "ASC":
+			tokens = tokens[:n-1]
+		}
+	}
+	first := tokens[0]
+	isName := first.kind == sqlTokenIdent || first.kind == sqlTokenWord
+	switch {
+	// 列、mysql前缀索引 name(10)、postgresql操作符类 name text_pattern_ops
+	case isName && (len(tokens) == 1 ||
+		len(tokens) == 4 && tokens[1].value == "(" && tokens[2].kind == sqlTokenNumber && tokens[3].value == ")" ||
+		len(tokens) == 2 && tokens[1].kind == sqlTokenWord):
+		column.ColumnName = p.name(&first)
+	default:
+		column.Expression = trimOuterParens(p.text[first.start:tokens[len(tokens)-1].end])
+	}
+	return column
+}
+
+// parenthesized 括号内表达式的原文
+func (p *ddlParser) parenthesized() (string, error) {
+	if !p.isSymbol(0, "(") {
+		return "", p.errorf("expected (")
+	}
+	start := p.pos
+	p.skipBalanced()
+	if p.tokens[p.pos-1].value != ")" {
+		return "", p.errorf("unterminated parenthesis")
+	}
+	return strings.TrimSpace(p.text[p.tokens[start].end:p.tokens[p.pos-1].start]), nil
+}
+
+func (p *ddlParser) setPrimaryKey(table *Table, name string, columns []string) {
+	constraints := table.Constraints[:0]
+	for _, constraint := range table.Constraints {
+		if constraint.Type != ConstraintPrimaryKey {
+			constraints = append(constraints, constraint)
+		}
+	}
+	table.Constraints = append(constraints, &Constraint{Name: name, Type: ConstraintPrimaryKey, Columns: columns})
+	for _, columnName := range columns {
+		if column := table.Column(columnName); column != nil {
+			column.Nullable = false
+		}
+	}
+}
+
+// finish 补全外键的被引用列及模式，为未命名的约束、索引生成名称，去掉约束自带的索引
+func (p *ddlParser) finish() {
+	for _, table := range p.schema.Tables {
+		used := make(map[string]bool)
+		for _, constraint := range table.Constraints {
+			used[constraint.Name] = true
+		}
+		counts := make(map[ConstraintType]int)
+		for _, constraint := range table.Constraints {
+			counts[constraint.Type]++
+			if constraint.Type == ConstraintForeignKey {
+				if constraint.RefSchema == "" {
+					constraint.RefSchema = p.schema.Name
+				}
+				if refTable := p.tables[constraint.RefTable]; len(constraint.RefColumns) == 0 && refTable != nil {
+					if primaryKey := refTable.ConstraintsOf(ConstraintPrimaryKey); len(primaryKey) > 0 {
+						constraint.RefColumns = primaryKey[0].Columns
+					}
+				}
+			}
+			if constraint.Name == "" && constraint.Type != ConstraintPrimaryKey {
+				constraint.Name = uniqueName(p.constraintName(table.Name, constraint.Type, constraint.Columns, counts[constraint.Type]), used)
+			}
+		}
+		indexes := table.Indexes[:0]
+		for _, index := range table.Indexes {
+			if index.Name != "" && used[index.Name] {
+				continue
+			}
+			if index.Name == "" {
+				index.Name = uniqueName(p.indexName(table.Name, index), used)
+			}
+			used[index.Name] = true
+			indexes = append(indexes, index)
+		}
+		table.Indexes = indexes
+	}
+}
+
+// constraintName 未命名约束的名称，mysql 唯一键以首列命名、外键为 t_ibfk_n、检查约束为 t_chk_n，
+// 其余数据库按 postgresql 的规则，如 t_a_key、t_a_fkey、t_check
+func (p *ddlParser) constraintName(tableName string, constraintType ConstraintType, columns []string, n int) string {
+	if p.dbType == dbx.DBTypeMySQL {
+		switch constraintType {
+		case ConstraintUnique:
+			if len(columns) > 0 {
+				return columns[0]
+			}
+		case ConstraintForeignKey:
+			return fmt.Sprintf("%s_ibfk_%d", tableName, n)
+		case ConstraintCheck:
+			return fmt.Sprintf("%s_chk_%d", tableName, n)
+		}
+	}
+	switch constraintType {
+	case ConstraintUnique:
+		return tableName + "_" + strings.Join(columns, "_") + "_key"
+	case ConstraintForeignKey:
+		return tableName + "_" + strings.Join(columns, "_") + "_fkey"
+	}
+	return tableName + "_check"
+}
+
+// indexName 未命名索引的名称，mysql 以首列命名，其余数据库按 postgresql 的规则，如 t_a_idx
+func (p *ddlParser) indexName(tableName string, index *Index) string {
+	columns := make([]string, 0, len(index.Columns))
+	for _, column := range index.Columns {
+		if column.ColumnName != "" {
+			columns = append(columns, column.ColumnName)
+		}
+	}
+	if len(columns) == 0 {
+		columns = append(columns, "expr")
+	}
+	if p.dbType == dbx.DBTypeMySQL {
+		return columns[0]
+	}
+	return tableName + "_" + strings.Join(columns, "_") + "_idx"
+}
+
+// uniqueName 名称已使用时追加序号
+func uniqueName(name string, used map[string]bool) string {
+	result := name
+	for i := 1; used[result]; i++ {
+		result = fmt.Sprintf("%s%d", name, i)
+	}
+	used[result] = true
+	return result
+}
+
+func columnNames(columns []*IndexColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column.ColumnName != "" {
+			names = append(names, column.ColumnName)
+		}
+	}
+	return names
+}
+
+// inSchema 限定名称是否属于解析的模式，模式名为空时取首个模式限定
+func (p *ddlParser) inSchema(names []string) bool {
+	if len(names) < 2 {
+		return true
+	}
+	qualifier := names[len(names)-2]
+	if p.schema.Name == "" {
+		p.schema.Name = qualifier
+		return true
+	}
+	return strings.EqualFold(qualifier, p.schema.Name)
+}
+
+func (p *ddlParser) lookupTable(names []string) *Table {
+	if !p.inSchema(names) {
+		return nil
+	}
+	return p.tables[names[len(names)-1]]
+}
+
+// name 标识符原样保留，未加引号的名称按数据库的规则折叠大小写
+func (p *ddlParser) name(token *ddlToken) string {
+	if token == nil {
+		return ""
+	}
+	if token.kind == sqlTokenIdent {
+		return token.value
+	}
+	return foldCase(token.value, p.identifier.UnquotedCase)
+}
+
+func (p *ddlParser) qualifiedName() (names []string, err error) {
+	for {
+		token := p.peek(0)
+		if token == nil || token.kind != sqlTokenWord && token.kind != sqlTokenIdent {
+			return nil, p.errorf("expected name")
+		}
+		p.pos++
+		names = append(names, p.name(token))
+		if !p.acceptSymbol(".") {
+			return
+		}
+	}
+}
+
+// optionValue 字符集、排序规则的名称，可为字符串或限定名，如 'utf8mb4'、pg_catalog."C"
+func (p *ddlParser) optionValue() string {
+	if token := p.peek(0); token != nil && token.kind == sqlTokenString {
+		return p.stringValue(p.next())
+	}
+	names, err := p.qualifiedName()
+	if err != nil {
+		return ""
+	}
+	return names[len(names)-1]
+}
+
+// optionalName mysql 的 key、unique key、foreign key 后可选的索引名
+func (p *ddlParser) optionalName() string {
+	token := p.peek(0)
+	if token == nil || token.kind != sqlTokenWord && token.kind != sqlTokenIdent || p.isWord(0, "USING") {
+		return ""
+	}
+	p.pos++
+	return p.name(token)
+}
+
+// stringValue 字符串常量的值，去掉引号及转义，非字符串时为空
+func (p *ddlParser) stringValue(token *ddlToken) string {
+	if token == nil || token.kind != sqlTokenString {
+		return ""
+	}
+	quote, body := token.value[0], token.value[1:len(token.value)-1]
+	var builder strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == quote && i+1 < len(body) && body[i+1] == quote:
+			i++
+		case c == '\\' && p.dbType == dbx.DBTypeMySQL && i+1 < len(body):
+			i++
+			switch c = body[i]; c {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'r':
+				c = '\r'
+			case '0':
+				c = 0
+			}
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
+
+func (p *ddlParser) peek(offset int) *ddlToken {
+	if p.pos+offset >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos+offset]
+}
+
+func (p *ddlParser) next() *ddlToken {
+	token := p.peek(0)
+	if token != nil {
+		p.pos++
+	}
+	return token
+}
+
+func (p *ddlParser) isWord(offset int, word string) bool {
+	token := p.peek(offset)
+	return token != nil && token.kind == sqlTokenWord && strings.EqualFold(token.value, word)
+}
+
+func (p *ddlParser) isSymbol(offset int, symbol string) bool {
+	token := p.peek(offset)
+	return token != nil && token.kind == sqlTokenSymbol && token.value == symbol
+}
+
+// acceptWords 依次匹配全部单词时前进，否则不前进
+func (p *ddlParser) acceptWords(words ...string) bool {
+	for i, word := range words {
+		if !p.isWord(i, word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// acceptAny 匹配任一单词时前进
+func (p *ddlParser) acceptAny(words ...string) bool {
+	for _, word := range words {
+		if p.acceptWords(word) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ddlParser) acceptSymbol(symbol string) bool {
+	if p.isSymbol(0, symbol) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipBalanced 跳过一个单元，括号时跳过至匹配的右括号
+func (p *ddlParser) skipBalanced() {
+	depth := 0
+	for token := p.next(); token != nil; token = p.next() {
+		if token.kind == sqlTokenSymbol {
+			switch token.value {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			}
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// skipToElementEnd 跳过至同层的逗号或右括号，不消耗该符号
+func (p *ddlParser) skipToElementEnd() {
+	for token := p.peek(0); token != nil && !(token.kind == sqlTokenSymbol && (token.value == "," || token.value == ")")); token = p.peek(0) {
+		p.skipBalanced()
+	}
+}
+
+// skipToSymbol 跳过至同层的 symbol，如 mysql 的 primary key using btree (id)
+func (p *ddlParser) skipToSymbol(symbol string) {
+	for token := p.peek(0); token != nil && !(token.kind == sqlTokenSymbol && (token.value == symbol || token.value == "," || token.value == ")")); token = p.peek(0) {
+		p.skipBalanced()
+	}
+}
+
+func (p *ddlParser) errorf(message string) error {
+	var rest string
+	if token := p.peek(0); token != nil {
+		rest = p.text[token.start:]
+	}
+	if len(rest) > 40 {
+		rest = rest[:40] + "..."
+	}
+	return fmt.Errorf("%s near %q", message, rest)
+}
@@ -0,0 +1,213 @@
+package dboperator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestParseDDLMySQL(t *testing.T) {
+	script := "-- mysqldump\n/*!40101 SET NAMES utf8mb4 */;\n" +
+		"CREATE TABLE `users` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '' COMMENT 'user''s name; \\'nick\\'',\n" +
+		"  `flag` tinyint(1) DEFAULT NULL,\n" +
+		"  `created_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_name` (`name`),\n" +
+		"  KEY `idx_created` (`created_at` DESC, `name`(10))\n" +
+		") ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4 COMMENT='用户';\n" +
+		"CREATE TABLE orders (\n" +
+		"  id int NOT NULL PRIMARY KEY,\n" +
+		"  user_id bigint unsigned,\n" +
+		"  amount decimal(10, 2) CHECK (amount > 0),\n" +
+		"  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,\n" +
+		"  INDEX (user_id)\n" +
+		");\n"
+	schema, err := ParseDDL(dbx.DBTypeMySQL, "app", script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Tables) != 2 || schema.Name != "app" {
+		t.Fatalf("unexpected tables %+v", schema.Tables)
+	}
+	orders, users := schema.Tables[0], schema.Tables[1]
+	if users.Comment != "用户" || users.Charset != "utf8mb4" || users.Collation != "" {
+		t.Errorf("table comment = %q, charset = %q, collation = %q", users.Comment, users.Charset, users.Collation)
+	}
+	expectedColumns := []*Column{
+		{Name: "id", DataType: "bigint unsigned", Position: 1, AutoIncrement: true, NextValue: 42},
+		{Name: "name", DataType: "varchar(50)", Default: "''", Comment: "user's name; 'nick'", Position: 2,
+			Charset: "utf8mb4", Collation: "utf8mb4_bin"},
+		{Name: "flag", DataType: "tinyint(1)", Nullable: true, Position: 3},
+		{Name: "created_at", DataType: "datetime", Nullable: true, Default: "CURRENT_TIMESTAMP", Position: 4},
+	}
+	if !reflect.DeepEqual(users.Columns, expectedColumns) {
+		for _, column := range users.Columns {
+			t.Logf("%+v", column)
+		}
+		t.Fatal("unexpected users columns")
+	}
+	if unique := users.ConstraintsOf(ConstraintUnique); len(unique) != 1 || unique[0].Name != "uk_name" {
+		t.Errorf("unexpected unique keys %+v", unique)
+	}
+	expectedIndex := &Index{Name: "idx_created", Columns: []*IndexColumn{{ColumnName: "created_at", IsDesc: true}, {ColumnName: "name"}}}
+	if len(users.Indexes) != 1 || !reflect.DeepEqual(users.Indexes[0], expectedIndex) {
+		t.Errorf("unexpected indexes %+v", users.Indexes)
+	}
+
+	if orders.Columns[2].DataType != "decimal(10,2)" || orders.Columns[0].Nullable {
+		t.Errorf("unexpected orders columns %+v %+v", orders.Columns[0], orders.Columns[2])
+	}
+	foreignKeys := orders.ConstraintsOf(ConstraintForeignKey)
+	expectedForeignKey := &Constraint{Name: "orders_ibfk_1", Type: ConstraintForeignKey, Columns: []string{"user_id"},
+		RefSchema: "app", RefTable: "users", RefColumns: []string{"id"}, OnDelete: ActionCascade}
+	if len(foreignKeys) != 1 || !reflect.DeepEqual(foreignKeys[0], expectedForeignKey) {
+		t.Errorf("unexpected foreign keys %+v", foreignKeys)
+	}
+	if checks := orders.ConstraintsOf(ConstraintCheck); len(checks) != 1 || checks[0].Expression != "amount > 0" || checks[0].Name != "orders_chk_1" {
+		t.Errorf("unexpected checks %+v", checks)
+	}
+	if len(orders.Indexes) != 1 || orders.Indexes[0].Name != "user_id" {
+		t.Errorf("unexpected orders indexes %+v", orders.Indexes)
+	}
+}
+
+func TestParseDDLPostgres(t *testing.T) {
+	script := `
+CREATE FUNCTION public.touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN NEW.updated_at := now(); RETURN NEW; END;
+$$;
+CREATE TABLE public.Users (
+    id bigserial NOT NULL,
+    "Name" character varying(50) COLLATE pg_catalog."C" DEFAULT 'x'::character varying NOT NULL,
+    tags text[],
+    created_at timestamp(3) with time zone DEFAULT now()
+);
+CREATE TABLE other.ignored (id integer);
+COMMENT ON TABLE public.users IS 'users';
+COMMENT ON COLUMN public.users."Name" IS 'name';
+CREATE TABLE public.orders (
+    id integer GENERATED ALWAYS AS IDENTITY (START WITH 1),
+    user_id bigint REFERENCES public.users ON DELETE SET NULL
+);
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_name_key UNIQUE ("Name");
+CREATE INDEX users_lower_idx ON public.users USING btree (lower(("Name")::text)) WHERE (tags IS NOT NULL);
+`
+	schema, err := ParseDDL(dbx.DBTypePostgres, "", script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Name != "public" || len(schema.Tables) != 2 {
+		t.Fatalf("unexpected schema %s %+v", schema.Name, schema.Tables)
+	}
+	orders, users := schema.Tables[0], schema.Tables[1]
+	if users.Name != "users" || users.Comment != "users" {
+		t.Errorf("unexpected table %s %q", users.Name, users.Comment)
+	}
+	expectedColumns := []*Column{
+		{Name: "id", DataType: "int8", Position: 1, AutoIncrement: true},
+		{Name: "Name", DataType: "character varying(50)", Default: "'x'::character varying", Comment: "name", Position: 2, Collation: "C"},
+		{Name: "tags", DataType: "text[]", Nullable: true, Position: 3},
+		{Name: "created_at", DataType: "timestamptz", Nullable: true, Default: "now()", Position: 4},
+	}
+	if !reflect.DeepEqual(users.Columns, expectedColumns) {
+		for _, column := range users.Columns {
+			t.Logf("%+v", column)
+		}
+		t.Fatal("unexpected users columns")
+	}
+	if primaryKey := users.ConstraintsOf(ConstraintPrimaryKey); len(primaryKey) != 1 || primaryKey[0].Columns[0] != "id" {
+		t.Errorf("unexpected primary key %+v", primaryKey)
+	}
+	if unique := users.ConstraintsOf(ConstraintUnique); len(unique) != 1 || unique[0].Columns[0] != "Name" {
+		t.Errorf("unexpected unique keys %+v", unique)
+	}
+	expectedIndex := &Index{Name: "users_lower_idx", Columns: []*IndexColumn{{Expression: `lower(("Name")::text)`}}, Filter: "(tags IS NOT NULL)"}
+	if len(users.Indexes) != 1 || !reflect.DeepEqual(users.Indexes[0], expectedIndex) {
+		t.Errorf("unexpected index %+v", users.Indexes[0])
+	}
+	if !orders.Columns[0].AutoIncrement {
+		t.Error("identity column is not auto increment")
+	}
+	foreignKeys := orders.ConstraintsOf(ConstraintForeignKey)
+	expectedForeignKey := &Constraint{Name: "orders_user_id_fkey", Type: ConstraintForeignKey, Columns: []string{"user_id"},
+		RefSchema: "public", RefTable: "users", RefColumns: []string{"id"}, OnDelete: ActionSetNull}
+	if len(foreignKeys) != 1 || !reflect.DeepEqual(foreignKeys[0], expectedForeignKey) {
+		t.Errorf("unexpected foreign keys %+v", foreignKeys[0])
+	}
+}
+
+func TestParseDDLOracle(t *testing.T) {
+	script := `
+  CREATE TABLE "APP"."EMP"
+   (	"ID" NUMBER(*,0) NOT NULL ENABLE,
+	"NAME" VARCHAR2(100 BYTE) DEFAULT 'none',
+	"HIRED" TIMESTAMP (6) WITH TIME ZONE,
+	dept_id number(10)
+   ) SEGMENT CREATION IMMEDIATE TABLESPACE "USERS"
+/
+  CREATE UNIQUE INDEX "APP"."PK_EMP" ON "APP"."EMP" ("ID") TABLESPACE "USERS"
+/
+  ALTER TABLE "APP"."EMP" ADD CONSTRAINT "PK_EMP" PRIMARY KEY ("ID") USING INDEX TABLESPACE "USERS" ENABLE
+/
+  ALTER TABLE "APP"."EMP" MODIFY ("NAME" NOT NULL ENABLE)
+/
+  CREATE INDEX "APP"."IDX_EMP_DEPT" ON "APP"."EMP" ("DEPT_ID" DESC)
+/
+COMMENT ON COLUMN "APP"."EMP"."NAME" IS 'employee name'
+/
+`
+	schema, err := ParseDDL(dbx.DBTypeOracle, "app", script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Tables) != 1 {
+		t.Fatalf("unexpected tables %+v", schema.Tables)
+	}
+	emp := schema.Tables[0]
+	expectedColumns := []*Column{
+		{Name: "ID", DataType: "NUMBER(38,0)", Position: 1},
+		{Name: "NAME", DataType: "VARCHAR2(100)", Default: "'none'", Comment: "employee name", Position: 2},
+		{Name: "HIRED", DataType: "TIMESTAMP(6) WITH TIME ZONE", Nullable: true, Position: 3},
+		{Name: "DEPT_ID", DataType: "NUMBER(10)", Nullable: true, Position: 4},
+	}
+	if !reflect.DeepEqual(emp.Columns, expectedColumns) {
+		for _, column := range emp.Columns {
+			t.Logf("%+v", column)
+		}
+		t.Fatal("unexpected emp columns")
+	}
+	if primaryKey := emp.ConstraintsOf(ConstraintPrimaryKey); len(primaryKey) != 1 || primaryKey[0].Name != "PK_EMP" {
+		t.Errorf("unexpected primary key %+v", primaryKey)
+	}
+	expectedIndexes := []*Index{{Name: "IDX_EMP_DEPT", Columns: []*IndexColumn{{ColumnName: "DEPT_ID", IsDesc: true}}}}
+	if !reflect.DeepEqual(emp.Indexes, expectedIndexes) {
+		t.Errorf("unexpected indexes %+v", emp.Indexes)
+	}
+
+	if _, err = ParseDDL(dbx.DBTypeOracle, "", `CREATE TABLE t (id NUMBER, name VARCHAR2(10)`); err == nil {
+		t.Error("expected error for unterminated table definition")
+	}
+}
+
+func TestParseDDLCharLength(t *testing.T) {
+	schema, err := ParseDDL(dbx.DBTypeOracle, "", `CREATE TABLE t (code VARCHAR2(10 CHAR) NOT NULL, name VARCHAR2(20 BYTE))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := schema.Tables[0].Columns
+	if columns[0].DataType != "VARCHAR2(10)" || columns[0].Nullable || columns[1].DataType != "VARCHAR2(20)" {
+		t.Errorf("unexpected columns %+v %+v", *columns[0], *columns[1])
+	}
+}
+
+func TestParseDDLTypelessSQLiteColumn(t *testing.T) {
+	_, err := ParseDDL(dbx.DBTypeSQLite, "", `CREATE TABLE t (id INTEGER, x)`)
+	if err == nil || !strings.Contains(err.Error(), "sqlite column x without a declared type") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package dboperator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// ExecuteStatements 逐条执行语句，返回已执行的语句组成的脚本，出错时停止执行
+func ExecuteStatements(ctx context.Context, db *dbx.DBWrapper, statements []string) (ddlSQL string, err error) {
+	for _, statement := range statements {
+		err = db.DB.WithContext(ctx).Exec(statement).Error
+		if err != nil {
+			return
+		}
+		ddlSQL += Script([]string{statement})
+	}
+	return
+}
+
+// Script 将语句连接为可交由客户端执行的脚本，每条语句一行起始，未以分号结尾的语句补齐分号
+func Script(statements []string) (script string) {
+	for _, statement := range statements {
+		statement = strings.TrimSpace(statement)
+		if statement == "" {
+			continue
+		}
+		if !strings.HasSuffix(statement, ";") {
+			statement += ";"
+		}
+		script += statement + fmt.Sprintln()
+	}
+	return
+}
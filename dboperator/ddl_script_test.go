@@ -0,0 +1,11 @@
+package dboperator
+
+import "testing"
+
+func TestScript(t *testing.T) {
+	script := Script([]string{"create table t (id int);", "  comment on table t is 'x'  ", "", "ALTER TABLE T ADD C NUMBER"})
+	expected := "create table t (id int);\ncomment on table t is 'x';\nALTER TABLE T ADD C NUMBER;\n"
+	if script != expected {
+		t.Fatalf("unexpected script:\n%s", script)
+	}
+}
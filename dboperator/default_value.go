@@ -0,0 +1,161 @@
+package dboperator
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 归一化后的通用默认值表达式，由各数据库在建表时翻译为本地写法
+const (
+	DefaultCurrentTimestamp = "CURRENT_TIMESTAMP"
+	DefaultCurrentDate      = "CURRENT_DATE"
+	DefaultCurrentTime      = "CURRENT_TIME"
+	DefaultTrue             = "TRUE"
+	DefaultFalse            = "FALSE"
+)
+
+var (
+	castSuffixReg  = regexp.MustCompile(`(?i)::[a-z_][a-z0-9_ ]*(\(\d+(,\s*\d+)?\))?(\[\])?$`)
+	funcCallReg    = regexp.MustCompile(`(?i)^([a-z_][a-z0-9_]*)\s*\(\s*(\d*)\s*\)$`)
+	numberReg      = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+	mysqlBitReg    = regexp.MustCompile(`(?i)^b'([01]+)'$`)
+	currentDateReg = regexp.MustCompile(`(?i)^(cast\s*\(\s*(getdate\(\)|current_timestamp|sysdatetime\(\))\s+as\s+date\s*\)|convert\s*\(\s*\[?date\]?\s*,\s*(getdate\(\)|current_timestamp|sysdatetime\(\))\s*\)|trunc\s*\(\s*sysdate\s*\)|curdate\(\)|date\s*\(\s*'now'\s*\))$`)
+	currentTimeReg = regexp.MustCompile(`(?i)^(cast\s*\(\s*(getdate\(\)|current_timestamp|sysdatetime\(\))\s+as\s+time\s*\)|convert\s*\(\s*\[?time\]?\s*,\s*(getdate\(\)|current_timestamp|sysdatetime\(\))\s*\)|curtime\(\)|time\s*\(\s*'now'\s*\))$`)
+)
+
+// timestampFuncs 各数据库中表示当前时间戳的函数
+var timestampFuncs = map[string]bool{
+	"current_timestamp":     true,
+	"now":                   true,
+	"localtimestamp":        true,
+	"transaction_timestamp": true,
+	"statement_timestamp":   true,
+	"getdate":               true,
+	"sysdatetime":           true,
+	"getutcdate":            true,
+	"sysdate":               true,
+	"systimestamp":          true,
+}
+
+// NormalizeDefaultValue 将源库字段默认值归一化为通用表达式
+// 字符串常量保留单引号，数值保持原样，当前时间类函数统一为 CURRENT_TIMESTAMP 等标准写法，
+// 序列等无法移植的默认值返回空串，其余无法识别的表达式原样返回，见 IsPortableDefault
+func NormalizeDefaultValue(dataDefault string) string {
+	value := strings.TrimSpace(dataDefault)
+	for {
+		trimmed := trimOuterParens(value)
+		if trimmed == value {
+			break
+		}
+		value = trimmed
+	}
+	if value == "" || strings.EqualFold(value, "null") {
+		return ""
+	}
+
+	// postgresql 类型转换: 'abc'::character varying、(0)::numeric
+	for castSuffixReg.MatchString(value) && !endsInsideQuota(value) {
+		value = strings.TrimSpace(castSuffixReg.ReplaceAllString(value, ""))
+		value = trimOuterParens(value)
+	}
+	if strings.EqualFold(value, "null") {
+		return ""
+	}
+
+	lower := strings.ToLower(value)
+	if strings.HasPrefix(lower, "nextval(") || strings.HasPrefix(lower, "next value for ") || sequenceNextvalReg.MatchString(value) {
+		return ""
+	}
+
+	// sqlserver unicode 字符串 N'abc'
+	if strings.HasPrefix(value, "N'") && strings.HasSuffix(value, "'") {
+		value = value[1:]
+	}
+	if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) >= 2 {
+		return value
+	}
+	if numberReg.MatchString(value) {
+		return value
+	}
+	if matches := mysqlBitReg.FindStringSubmatch(value); len(matches) == 2 {
+		if strings.Trim(matches[1], "0") == "" {
+			return "0"
+		}
+		return "1"
+	}
+
+	switch lower {
+	case "true":
+		return DefaultTrue
+	case "false":
+		return DefaultFalse
+	case "current_date":
+		return DefaultCurrentDate
+	case "current_time", "localtime":
+		return DefaultCurrentTime
+	case "datetime('now')", "datetime('now', 'localtime')":
+		return DefaultCurrentTimestamp
+	}
+	if timestampFuncs[lower] {
+		return DefaultCurrentTimestamp
+	}
+	if matches := funcCallReg.FindStringSubmatch(value); len(matches) == 3 {
+		name := strings.ToLower(matches[1])
+		if timestampFuncs[name] {
+			return DefaultCurrentTimestamp
+		}
+		switch name {
+		case "current_date", "curdate":
+			return DefaultCurrentDate
+		case "current_time", "curtime", "localtime":
+			return DefaultCurrentTime
+		}
+	}
+	if currentDateReg.MatchString(value) {
+		return DefaultCurrentDate
+	}
+	if currentTimeReg.MatchString(value) {
+		return DefaultCurrentTime
+	}
+	return value
+}
+
+// IsPortableDefault 归一化后的默认值能否用于其他数据库：字符串、数值常量及通用表达式，
+// 其余为源库方言的表达式(如 newid()、SYS_GUID())，仅可用于同类数据库
+func IsPortableDefault(value string) bool {
+	switch value {
+	case "", DefaultCurrentTimestamp, DefaultCurrentDate, DefaultCurrentTime, DefaultTrue, DefaultFalse:
+		return true
+	}
+	return strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) >= 2 || numberReg.MatchString(value)
+}
+
+// trimOuterParens 去掉包裹整个表达式的括号，如 sqlserver 的 ((0))、('abc')
+func trimOuterParens(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '(' || value[len(value)-1] != ')' {
+		return value
+	}
+	depth := 0
+	inQuota := false
+	for i, c := range value {
+		switch {
+		case c == '\'':
+			inQuota = !inQuota
+		case inQuota:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 && i != len(value)-1 {
+				return value
+			}
+		}
+	}
+	return strings.TrimSpace(value[1 : len(value)-1])
+}
+
+// endsInsideQuota 判断表达式结尾是否位于字符串常量内部
+func endsInsideQuota(value string) bool {
+	return strings.Count(value, "'")%2 == 1
+}
@@ -0,0 +1,52 @@
+package dboperator
+
+import "testing"
+
+func TestNormalizeDefaultValue(t *testing.T) {
+	cases := map[string]string{
+		"":                                 "",
+		"NULL":                             "",
+		"NULL::character varying":          "",
+		"((0))":                            "0",
+		"('abc')":                          "'abc'",
+		"(N'abc')":                         "'abc'",
+		"'abc'::character varying":         "'abc'",
+		"'a::b'":                           "'a::b'",
+		"(-1)::numeric(10,2)":              "-1",
+		"(getdate())":                      DefaultCurrentTimestamp,
+		"now()":                            DefaultCurrentTimestamp,
+		"CURRENT_TIMESTAMP(6)":             DefaultCurrentTimestamp,
+		"SYSDATE\n":                        DefaultCurrentTimestamp,
+		"trunc(sysdate)":                   DefaultCurrentDate,
+		"(CONVERT([date],getdate()))":      DefaultCurrentDate,
+		"(newid())":                        "newid()",
+		"(CAST(GETDATE() AS DATE))":        DefaultCurrentDate,
+		"true":                             DefaultTrue,
+		"b'1'":                             "1",
+		"nextval('user_id_seq'::regclass)": "",
+		`"SCOTT"."ISEQ$$_7301".nextval`:    "",
+		"(NEXT VALUE FOR [dbo].[seq_id])":  "",
+	}
+	for raw, expected := range cases {
+		if actual := NormalizeDefaultValue(raw); actual != expected {
+			t.Errorf("NormalizeDefaultValue(%q) = %q, expected %q", raw, actual, expected)
+		}
+	}
+}
+
+func TestIsPortableDefault(t *testing.T) {
+	cases := map[string]bool{
+		"":                      true,
+		"'abc'":                 true,
+		"-1.5":                  true,
+		DefaultCurrentTimestamp: true,
+		"newid()":               false,
+		"SYS_GUID()":            false,
+		"gen_random_uuid()":     false,
+	}
+	for value, expected := range cases {
+		if actual := IsPortableDefault(value); actual != expected {
+			t.Errorf("IsPortableDefault(%q) = %v, expected %v", value, actual, expected)
+		}
+	}
+}
@@ -57,19 +57,35 @@ func (o DMOperator) Trans2CommonField(dataType string) *dboperator.Field {
 	case "number", "numeric", "decimal", "dec":
 		field = *dboperator.Float64Field
 		field.IsFixedNumber = true
+		// NUMBER(p) 按精度映射为整数，超出bigint位数的保持定点数
 		if typeStr == "number" && len(extra) == 1 {
-			if extra[0] <= "32" {
-				field.Type = dboperator.INT32
-				field.IsFixedNumber = false
-			} else {
-				field.Type = dboperator.INT64
-				field.IsFixedNumber = false
+			precision, err := strconv.Atoi(extra[0])
+			switch {
+			case err != nil:
+			case precision <= 9:
+				field.Type, field.IsFixedNumber = dboperator.INT32, false
+			case precision <= 18:
+				field.Type, field.IsFixedNumber = dboperator.INT64, false
 			}
 		}
 	case "double precision", "double", "binary_double":
 		field = *dboperator.Float64Field
 	case "boolean", "bool":
 		field = *dboperator.BoolField
+	case "json":
+		field = *dboperator.JSONField
+	case "xmltype":
+		field = *dboperator.XMLField
+	case "sdo_geometry":
+		field = *dboperator.GeometryField
+	case "interval year", "interval day":
+		// data_type: INTERVAL YEAR(2) TO MONTH、INTERVAL DAY(2) TO SECOND(6)
+		field = *dboperator.IntervalField
+		field.IntervalType = dboperator.IntervalDaySecond
+		if typeStr == "interval year" {
+			field.IntervalType = dboperator.IntervalYearMonth
+		}
+		extra = nil
 	default:
 		log.DefaultLogger().Warn("handle with default oracle type:%s", dataType)
 		field = *dboperator.StringField
@@ -102,6 +118,10 @@ func (o DMOperator) Trans2CommonField(dataType string) *dboperator.Field {
 }
 
 func (o DMOperator) Trans2DataType(field *dboperator.Field) string {
+	if field.TargetType != "" {
+		return field.TargetType
+	}
+	field = dboperator.WidenUnsigned(field)
 	switch field.Type {
 	case dboperator.BYTES:
 		fallthrough
@@ -169,6 +189,26 @@ func (o DMOperator) Trans2DataType(field *dboperator.Field) string {
 		} else {
 			return fmt.Sprintf("timestamp(%d)", field.Length)
 		}
+	case dboperator.JSON, dboperator.ARRAY:
+		// 数组以json数组保存
+		return "CLOB"
+	case dboperator.UUID:
+		return "VARCHAR2(36)"
+	case dboperator.ENUM, dboperator.SET:
+		// ENUM的可选值由检查约束限定
+		if length := dboperator.EnumLength(field); length <= 4000 {
+			return fmt.Sprintf("VARCHAR2(%d)", length)
+		}
+		return "CLOB"
+	case dboperator.INTERVAL:
+		if field.IntervalType == dboperator.IntervalYearMonth {
+			return "INTERVAL YEAR(9) TO MONTH"
+		}
+		return "INTERVAL DAY(9) TO SECOND(6)"
+	case dboperator.XML:
+		return "CLOB"
+	case dboperator.GEOMETRY:
+		return "CLOB"
 	default:
 		log.DefaultLogger().Warn("handle with to default oracle type:%s", field.Type)
 		return "CLOB"
@@ -195,3 +235,34 @@ func getTypeSuffix(field *dboperator.Field) string {
 	}
 	return ""
 }
+
+// getColumnOption 生成字段默认值(自增列为自增属性)、非空约束及枚举检查约束
+func getColumnOption(field *dboperator.Field) (option string) {
+	if field.IsAutoIncrement {
+		option += fmt.Sprintf(" identity(%d,1)", field.AutoIncrementStart)
+	} else if defaultValue := getDefaultValue(field); defaultValue != "" {
+		option += " default " + defaultValue
+	}
+	if !field.ISNullable {
+		option += " not null"
+	}
+	if expression := dboperator.EnumCheckExpression(field, identifier); expression != "" {
+		option += " check (" + expression + ")"
+	}
+	return
+}
+
+// getDefaultValue 将通用默认值翻译为达梦写法
+func getDefaultValue(field *dboperator.Field) string {
+	switch field.DefaultValue {
+	case dboperator.DefaultTrue:
+		return "1"
+	case dboperator.DefaultFalse:
+		return "0"
+	case dboperator.DefaultCurrentDate:
+		return "TRUNC(SYSDATE)"
+	case dboperator.DefaultCurrentTime:
+		return dboperator.DefaultCurrentTimestamp
+	}
+	return field.DefaultValue
+}
@@ -0,0 +1,273 @@
+package dboperator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DiagramFormat ER 图的文本格式
+type DiagramFormat string
+
+const (
+	DiagramMermaid  DiagramFormat = "mermaid"  // Mermaid erDiagram
+	DiagramPlantUML DiagramFormat = "plantuml" // PlantUML 实体关系图
+	DiagramDOT      DiagramFormat = "dot"      // Graphviz DOT
+)
+
+// DiagramFormatOf 按文件扩展名推断格式：.puml、.plantuml、.pu 为 PlantUML，.dot、.gv 为 DOT，其余为 Mermaid
+func DiagramFormatOf(filePath string) DiagramFormat {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".puml", ".plantuml", ".pu":
+		return DiagramPlantUML
+	case ".dot", ".gv":
+		return DiagramDOT
+	}
+	return DiagramMermaid
+}
+
+// diagramRelation 外键对应的关系，child 的外键列引用 parent
+type diagramRelation struct {
+	child, parent *Table
+	constraint    *Constraint
+	optional      bool // 外键列可空，子表的行可不关联父表
+	oneToOne      bool // 外键列即主键或唯一键，父表的行至多关联一行
+}
+
+// GenerateDiagram 由表、列、主键及外键生成 ER 图，filter 非 nil 时仅包含满足条件的表(不按行数过滤)，
+// 关系仅在两端的表均包含时绘制，引用其他模式的外键不绘制
+func GenerateDiagram(schema *Schema, format DiagramFormat, filter *TableFilter) ([]byte, error) {
+	tables := make([]*Table, 0, len(schema.Tables))
+	included := make(map[string]*Table)
+	for _, table := range schema.Tables {
+		if filter.Match(&TableInfo{TableName: table.Name, Comment: table.Comment, RowCount: -1}) {
+			tables = append(tables, table)
+			included[table.Name] = table
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	relations := make([]*diagramRelation, 0)
+	for _, table := range tables {
+		for _, constraint := range table.ConstraintsOf(ConstraintForeignKey) {
+			parent := included[constraint.RefTable]
+			if parent == nil || constraint.RefSchema != "" && schema.Name != "" && constraint.RefSchema != schema.Name {
+				continue
+			}
+			relation := &diagramRelation{child: table, parent: parent, constraint: constraint}
+			for _, columnName := range constraint.Columns {
+				if column := table.Column(columnName); column != nil && column.Nullable {
+					relation.optional = true
+				}
+			}
+			for _, key := range table.Constraints {
+				if (key.Type == ConstraintPrimaryKey || key.Type == ConstraintUnique) && sameColumns(key.Columns, constraint.Columns) {
+					relation.oneToOne = true
+				}
+			}
+			relations = append(relations, relation)
+		}
+	}
+
+	var buffer bytes.Buffer
+	switch format {
+	case DiagramMermaid, "":
+		writeMermaid(&buffer, tables, relations)
+	case DiagramPlantUML:
+		writePlantUML(&buffer, tables, relations)
+	case DiagramDOT:
+		writeDOT(&buffer, tables, relations)
+	default:
+		return nil, fmt.Errorf("unsupported diagram format %s", format)
+	}
+	return buffer.Bytes(), nil
+}
+
+// columnKeys 各列所属的键，依次为 PK、FK、UK
+func columnKeys(table *Table) map[string][]string {
+	keys := make(map[string][]string)
+	for _, keyType := range []ConstraintType{ConstraintPrimaryKey, ConstraintForeignKey, ConstraintUnique} {
+		key := map[ConstraintType]string{ConstraintPrimaryKey: "PK", ConstraintForeignKey: "FK", ConstraintUnique: "UK"}[keyType]
+		for _, constraint := range table.ConstraintsOf(keyType) {
+			for _, column := range constraint.Columns {
+				if !slices.Contains(keys[column], key) {
+					keys[column] = append(keys[column], key)
+				}
+			}
+		}
+	}
+	return keys
+}
+
+func writeMermaid(buffer *bytes.Buffer, tables []*Table, relations []*diagramRelation) {
+	buffer.WriteString("erDiagram\n")
+	tableIDs := diagramTableIdentifiers(tables)
+	for _, table := range tables {
+		fmt.Fprintf(buffer, "    %s {\n", tableIDs[table.Name])
+		keys := columnKeys(table)
+		columnNames := make([]string, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			columnNames = append(columnNames, column.Name)
+		}
+		columnIDs := diagramIdentifiers(columnNames)
+		for _, column := range table.Columns {
+			fmt.Fprintf(buffer, "        %s %s", diagramIdentifier(column.DataType), columnIDs[column.Name])
+			if len(keys[column.Name]) > 0 {
+				buffer.WriteString(" " + strings.Join(keys[column.Name], ", "))
+			}
+			if column.Comment != "" {
+				fmt.Fprintf(buffer, " %q", diagramLabel(column.Comment))
+			}
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString("    }\n")
+	}
+	for _, relation := range relations {
+		childSide, parentSide := "}o", "||"
+		if relation.oneToOne {
+			childSide = "|o"
+		}
+		if relation.optional {
+			parentSide = "o|"
+		}
+		fmt.Fprintf(buffer, "    %s %s--%s %s : %q\n", tableIDs[relation.child.Name], childSide, parentSide,
+			tableIDs[relation.parent.Name], diagramLabel(relation.constraint.Name))
+	}
+}
+
+func writePlantUML(buffer *bytes.Buffer, tables []*Table, relations []*diagramRelation) {
+	buffer.WriteString("@startuml\nhide circle\nskinparam linetype ortho\n\n")
+	tableIDs := diagramTableIdentifiers(tables)
+	for _, table := range tables {
+		fmt.Fprintf(buffer, "entity %q as %s {\n", diagramLabel(table.Name), tableIDs[table.Name])
+		keys := columnKeys(table)
+		primaryKeys := make(map[string]bool)
+		for _, constraint := range table.ConstraintsOf(ConstraintPrimaryKey) {
+			for _, column := range constraint.Columns {
+				primaryKeys[column] = true
+			}
+		}
+		// 主键列在分隔线之上，非空列以 * 标记
+		writeColumns := func(primary bool) {
+			for _, column := range table.Columns {
+				if primaryKeys[column.Name] != primary {
+					continue
+				}
+				mark := ""
+				if !column.Nullable {
+					mark = "* "
+				}
+				fmt.Fprintf(buffer, "  %s%s : %s", mark, diagramLabel(column.Name), diagramLabel(column.DataType))
+				for _, key := range keys[column.Name] {
+					fmt.Fprintf(buffer, " <<%s>>", key)
+				}
+				buffer.WriteString("\n")
+			}
+		}
+		writeColumns(true)
+		buffer.WriteString("  --\n")
+		writeColumns(false)
+		buffer.WriteString("}\n\n")
+	}
+	for _, relation := range relations {
+		parentSide, childSide := "||", "o{"
+		if relation.optional {
+			parentSide = "|o"
+		}
+		if relation.oneToOne {
+			childSide = "o|"
+		}
+		fmt.Fprintf(buffer, "%s %s--%s %s : %s\n", tableIDs[relation.parent.Name], parentSide, childSide,
+			tableIDs[relation.child.Name], diagramLabel(relation.constraint.Name))
+	}
+	buffer.WriteString("@enduml\n")
+}
+
+func writeDOT(buffer *bytes.Buffer, tables []*Table, relations []*diagramRelation) {
+	buffer.WriteString("digraph er {\n  graph [rankdir=LR];\n  node [shape=plaintext, fontname=\"Helvetica\"];\n  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, table := range tables {
+		keys := columnKeys(table)
+		fmt.Fprintf(buffer, "  %s [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">\n", dotQuote(table.Name))
+		fmt.Fprintf(buffer, "    <tr><td bgcolor=\"lightgray\"><b>%s</b></td></tr>\n", htmlText(table.Name))
+		for _, column := range table.Columns {
+			text := column.Name + " : " + column.DataType
+			if len(keys[column.Name]) > 0 {
+				text += " [" + strings.Join(keys[column.Name], ", ") + "]"
+			}
+			fmt.Fprintf(buffer, "    <tr><td port=\"%s\" align=\"left\">%s</td></tr>\n", htmlText(column.Name), htmlText(text))
+		}
+		buffer.WriteString("  </table>>];\n")
+	}
+	for _, relation := range relations {
+		childPort, parentPort := "", ""
+		if len(relation.constraint.Columns) > 0 {
+			childPort = ":" + dotQuote(relation.constraint.Columns[0])
+		}
+		if len(relation.constraint.RefColumns) > 0 {
+			parentPort = ":" + dotQuote(relation.constraint.RefColumns[0])
+		}
+		style := ""
+		if relation.optional {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(buffer, "  %s%s -> %s%s [label=%s%s];\n", dotQuote(relation.child.Name), childPort,
+			dotQuote(relation.parent.Name), parentPort, dotQuote(relation.constraint.Name), style)
+	}
+	buffer.WriteString("}\n")
+}
+
+// diagramTableIdentifiers 各表在 Mermaid、PlantUML 中的名称，见 diagramIdentifiers
+func diagramTableIdentifiers(tables []*Table) map[string]string {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	return diagramIdentifiers(names)
+}
+
+// diagramIdentifiers 按顺序为各名称分配 diagramIdentifier，如 "user profile" 与 user_profile 等改写后重复的名称
+// 依次追加 _2、_3 等序号
+func diagramIdentifiers(names []string) map[string]string {
+	identifiers := make(map[string]string, len(names))
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		base := diagramIdentifier(name)
+		identifier := base
+		for i := 2; used[identifier]; i++ {
+			identifier = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[identifier] = true
+		identifiers[name] = identifier
+	}
+	return identifiers
+}
+
+// diagramIdentifier Mermaid、PlantUML 中不加引号的名称，字母数字及 _、- 以外的字符改为 _
+func diagramIdentifier(name string) string {
+	var builder strings.Builder
+	for _, r := range name {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			builder.WriteRune(r)
+			continue
+		}
+		builder.WriteByte('_')
+	}
+	identifier := strings.Trim(builder.String(), "_")
+	if identifier == "" {
+		return "_"
+	}
+	return identifier
+}
+
+// diagramLabel 单行且不含双引号的文本
+func diagramLabel(text string) string {
+	return strings.ReplaceAll(singleLine(text), `"`, "'")
+}
+
+// dotQuote DOT 的双引号字符串
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+}
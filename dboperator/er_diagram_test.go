@@ -0,0 +1,106 @@
+package dboperator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiagram(t *testing.T) {
+	schema := dictionaryTestSchema()
+	schema.Tables = append(schema.Tables, &Table{
+		Name:    "user profile",
+		Columns: []*Column{{Name: "user_id", DataType: "int8"}},
+		Constraints: []*Constraint{
+			{Name: "profile_pkey", Type: ConstraintPrimaryKey, Columns: []string{"user_id"}},
+			{Name: "profile_user_fkey", Type: ConstraintForeignKey, Columns: []string{"user_id"}, RefTable: "Users", RefColumns: []string{"id"}},
+		},
+	})
+
+	mermaid, err := GenerateDiagram(schema, DiagramMermaid, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"erDiagram\n    Users {\n        int8 id PK\n    }\n",
+		"        int8 user_id FK \"下单人|买家\"\n",
+		"    orders }o--o| Users : \"orders_user_id_fkey\"\n",
+		"    user_profile |o--|| Users : \"profile_user_fkey\"\n",
+	} {
+		if !strings.Contains(string(mermaid), expected) {
+			t.Errorf("missing %q in:\n%s", expected, mermaid)
+		}
+	}
+	if strings.Contains(string(mermaid), "customers") {
+		t.Error("unexpected relation to another schema")
+	}
+
+	plantUML, err := GenerateDiagram(schema, DiagramPlantUML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"@startuml\n",
+		"entity \"orders\" as orders {\n  * id : int8 <<PK>>\n  --\n  user_id : int8 <<FK>>\n",
+		"Users |o--o{ orders : orders_user_id_fkey\n",
+		"Users ||--o| user_profile : profile_user_fkey\n",
+		"@enduml\n",
+	} {
+		if !strings.Contains(string(plantUML), expected) {
+			t.Errorf("missing %q in:\n%s", expected, plantUML)
+		}
+	}
+
+	filter := &TableFilter{Exclude: []string{"user*"}}
+	if err = filter.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	dot, err := GenerateDiagram(schema, DiagramFormatOf("er.gv"), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"digraph er {\n",
+		"  \"orders\" [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">\n",
+		"    <tr><td port=\"id\" align=\"left\">id : int8 [PK]</td></tr>\n",
+	} {
+		if !strings.Contains(string(dot), expected) {
+			t.Errorf("missing %q in:\n%s", expected, dot)
+		}
+	}
+	if strings.Contains(string(dot), "Users") || strings.Contains(string(dot), "->") {
+		t.Errorf("excluded tables in:\n%s", dot)
+	}
+
+	if _, err = GenerateDiagram(schema, "svg", nil); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestGenerateDiagramDuplicateIdentifiers(t *testing.T) {
+	schema := &Schema{Tables: []*Table{
+		{Name: "user_profile", Columns: []*Column{{Name: "id", DataType: "int8"}, {Name: "first name", DataType: "text"}, {Name: "first_name", DataType: "text"}}},
+		{Name: "user profile", Columns: []*Column{{Name: "id", DataType: "int8"}}, Constraints: []*Constraint{
+			{Name: "profile_fkey", Type: ConstraintForeignKey, Columns: []string{"id"}, RefTable: "user_profile", RefColumns: []string{"id"}},
+		}},
+	}}
+	mermaid, err := GenerateDiagram(schema, DiagramMermaid, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"    user_profile {\n        int8 id FK\n    }\n",
+		"    user_profile_2 {\n        int8 id\n        text first_name\n        text first_name_2\n    }\n",
+		"    user_profile }o--|| user_profile_2 : \"profile_fkey\"\n",
+	} {
+		if !strings.Contains(string(mermaid), expected) {
+			t.Errorf("missing %q in:\n%s", expected, mermaid)
+		}
+	}
+	plantUML, err := GenerateDiagram(schema, DiagramPlantUML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(plantUML), "entity \"user_profile\" as user_profile_2 {\n") {
+		t.Errorf("duplicate entity alias in:\n%s", plantUML)
+	}
+}
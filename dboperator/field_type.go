@@ -0,0 +1,110 @@
+package dboperator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jasonlabz/dbutil/core/utils"
+)
+
+// 时间间隔类型
+const (
+	IntervalYearMonth = "year_month"
+	IntervalDaySecond = "day_second"
+)
+
+// geometryTypes 各数据库空间类型名称归一化
+var geometryTypes = map[string]string{
+	"geometry":           "",
+	"sdo_geometry":       "",
+	"point":              "point",
+	"linestring":         "linestring",
+	"polygon":            "polygon",
+	"multipoint":         "multipoint",
+	"multilinestring":    "multilinestring",
+	"multipolygon":       "multipolygon",
+	"geometrycollection": "geometrycollection",
+	"geomcollection":     "geometrycollection",
+	"geography":          "geography",
+}
+
+// ParseEnumValues 解析枚举类型的可选值，如 enum('a','b”c') 解析为 [a b'c]
+func ParseEnumValues(dataType string) (values []string) {
+	start := strings.Index(dataType, "(")
+	end := strings.LastIndex(dataType, ")")
+	if start == -1 || end <= start {
+		return
+	}
+	tokens, err := tokenizeSQL(dataType[start+1 : end])
+	if err != nil {
+		return nil
+	}
+	for _, token := range tokens {
+		if token.kind == sqlTokenString {
+			values = append(values, strings.ReplaceAll(token.value[1:len(token.value)-1], "''", "'"))
+		}
+	}
+	return
+}
+
+// GeometryType 将空间类型名称归一化为 Field.GeometryType，非空间类型返回 false
+func GeometryType(typeName string) (geometryType string, ok bool) {
+	geometryType, ok = geometryTypes[strings.ToLower(strings.TrimSpace(typeName))]
+	return
+}
+
+// EnumLength 以字符串保存 ENUM/SET 时所需的长度，SET 为全部可选值以逗号连接的长度
+func EnumLength(field *Field) (length int) {
+	for _, value := range field.EnumValues {
+		valueLength := utf8.RuneCountInString(value)
+		if field.Type == SET {
+			length += valueLength + 1
+		} else if valueLength > length {
+			length = valueLength
+		}
+	}
+	if field.Type == SET && length > 0 {
+		length--
+	}
+	if length == 0 {
+		length = 1
+	}
+	return
+}
+
+// EnumCheckExpression 无原生枚举类型的数据库以检查约束限定可选值，列名按 policy 引用，非 ENUM 字段返回空串
+func EnumCheckExpression(field *Field, policy *IdentifierPolicy) string {
+	if field.Type != ENUM || len(field.EnumValues) == 0 {
+		return ""
+	}
+	values := make([]string, 0, len(field.EnumValues))
+	for _, value := range field.EnumValues {
+		values = append(values, utils.QuotaString(value))
+	}
+	return fmt.Sprintf("%s IN (%s)", policy.Name(field.ColumnName), strings.Join(values, ","))
+}
+
+// WidenUnsigned 目标库无无符号整数时，将无符号整数扩大为下一级有符号整数，bigint unsigned 扩大为 numeric(20)；
+// 自增列的值不会超出bigint范围，保持bigint以便生成自增属性。返回副本，不修改原字段
+func WidenUnsigned(field *Field) *Field {
+	if !field.IsUnsigned {
+		return field
+	}
+	widened := *field
+	widened.IsUnsigned = false
+	switch field.Type {
+	case INT8:
+		widened.Type = INT16
+	case INT16:
+		widened.Type = INT32
+	case INT32:
+		widened.Type = INT64
+	case INT64:
+		if !field.IsAutoIncrement {
+			widened.Type, widened.IsFixedNumber = FLOAT64, true
+			widened.Precision, widened.Scale = 20, 0
+		}
+	}
+	return &widened
+}
@@ -0,0 +1,36 @@
+package dboperator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnumValues(t *testing.T) {
+	values := ParseEnumValues(`enum('small','it''s','a,b)')`)
+	expected := []string{"small", "it's", "a,b)"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("ParseEnumValues = %q, expected %q", values, expected)
+	}
+}
+
+func TestEnumLength(t *testing.T) {
+	field := &Field{Type: ENUM, EnumValues: []string{"a", "中文值"}}
+	if length := EnumLength(field); length != 3 {
+		t.Errorf("EnumLength(enum) = %d, expected 3", length)
+	}
+	field.Type = SET
+	if length := EnumLength(field); length != 5 {
+		t.Errorf("EnumLength(set) = %d, expected 5", length)
+	}
+}
+
+func TestWidenUnsigned(t *testing.T) {
+	field := &Field{Type: INT32, IsUnsigned: true}
+	if widened := WidenUnsigned(field); widened.Type != INT64 || widened.IsUnsigned || !field.IsUnsigned {
+		t.Errorf("WidenUnsigned(int32) = %+v", *widened)
+	}
+	field = &Field{Type: INT64, IsUnsigned: true}
+	if widened := WidenUnsigned(field); widened.Type != FLOAT64 || !widened.IsFixedNumber || widened.Precision != 20 {
+		t.Errorf("WidenUnsigned(int64) = %+v", *widened)
+	}
+}
@@ -0,0 +1,184 @@
+package dboperator
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/log"
+)
+
+// 归一化后的外键引用动作
+const (
+	ActionNoAction   = "NO ACTION"
+	ActionRestrict   = "RESTRICT"
+	ActionCascade    = "CASCADE"
+	ActionSetNull    = "SET NULL"
+	ActionSetDefault = "SET DEFAULT"
+)
+
+// NormalizeReferentialAction 将各数据库的外键动作(如sqlserver的SET_NULL)归一化，无动作时返回空串
+func NormalizeReferentialAction(action string) string {
+	action = strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(action, "_", " ")))
+	switch action {
+	case ActionRestrict, ActionCascade, ActionSetNull, ActionSetDefault:
+		return action
+	}
+	return ""
+}
+
+// GroupForeignKeyColumns 将按 表名、约束名、列序号 排序的外键列汇总为各表外键
+func GroupForeignKeyColumns(rows []*TableForeignKeyColumn) (foreignKeyInfo map[string][]*ForeignKeyInfo) {
+	foreignKeyInfo = make(map[string][]*ForeignKeyInfo)
+	foreignKeyMap := make(map[string]*ForeignKeyInfo)
+	for _, row := range rows {
+		key := row.TableName + "." + row.ConstraintName
+		foreignKey, ok := foreignKeyMap[key]
+		if !ok {
+			foreignKey = &ForeignKeyInfo{
+				ConstraintName: row.ConstraintName,
+				RefSchemaName:  row.RefSchemaName,
+				RefTableName:   row.RefTableName,
+				OnDelete:       NormalizeReferentialAction(row.OnDelete),
+				OnUpdate:       NormalizeReferentialAction(row.OnUpdate),
+			}
+			foreignKeyMap[key] = foreignKey
+			foreignKeyInfo[row.TableName] = append(foreignKeyInfo[row.TableName], foreignKey)
+		}
+		foreignKey.Columns = append(foreignKey.Columns, row.ColumnName)
+		foreignKey.RefColumns = append(foreignKey.RefColumns, row.RefColumnName)
+	}
+	return
+}
+
+// SortTablesByDependency 按外键依赖对表排序，被引用表在前，见 SortByDependency
+func SortTablesByDependency(tableNames []string, foreignKeysMap map[string][]*ForeignKeyInfo) (sorted []string, cycles [][]string) {
+	dependsOn := make(map[string][]string)
+	for _, tableName := range tableNames {
+		for _, foreignKey := range foreignKeysMap[tableName] {
+			dependsOn[tableName] = append(dependsOn[tableName], foreignKey.RefTableName)
+		}
+	}
+	return SortByDependency(tableNames, dependsOn)
+}
+
+// SortByDependency 按依赖关系排序，被依赖者在前；同层按名称排序以保证结果稳定。
+// 自身依赖及names之外的依赖忽略，处于循环依赖中的对象追加在末尾，cycles 为检测到的依赖环
+func SortByDependency(names []string, dependsOn map[string][]string) (sorted []string, cycles [][]string) {
+	nameSet := make(map[string]bool, len(names))
+	for _, name := range names {
+		nameSet[name] = true
+	}
+	edges := make(map[string][]string)
+	inDegree := make(map[string]int)
+	referencedBy := make(map[string][]string)
+	for _, name := range names {
+		seen := make(map[string]bool)
+		for _, dependency := range dependsOn[name] {
+			if dependency == name || !nameSet[dependency] || seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			edges[name] = append(edges[name], dependency)
+			referencedBy[dependency] = append(referencedBy[dependency], name)
+			inDegree[name]++
+		}
+	}
+
+	ready := make([]string, 0)
+	for _, name := range names {
+		if inDegree[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	visited := make(map[string]bool)
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		visited[name] = true
+		sorted = append(sorted, name)
+		for _, dependent := range referencedBy[name] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if len(sorted) == len(names) {
+		return
+	}
+
+	remaining := make([]string, 0)
+	for _, name := range names {
+		if !visited[name] {
+			remaining = append(remaining, name)
+		}
+	}
+	sort.Strings(remaining)
+	sorted = append(sorted, remaining...)
+	cycles = findCycles(remaining, edges)
+	return
+}
+
+// findCycles 在未能排序的对象中找出依赖环，每个环以起点表结尾闭合，如 [a b a]
+func findCycles(tableNames []string, dependsOn map[string][]string) (cycles [][]string) {
+	covered := make(map[string]bool)
+	for _, start := range tableNames {
+		if covered[start] {
+			continue
+		}
+		// 广度优先查找回到起点的最短路径
+		prev := map[string]string{start: ""}
+		queue := []string{start}
+		var last string
+		for len(queue) > 0 && last == "" {
+			current := queue[0]
+			queue = queue[1:]
+			refTables := append([]string(nil), dependsOn[current]...)
+			sort.Strings(refTables)
+			for _, refTable := range refTables {
+				if refTable == start {
+					last = current
+					break
+				}
+				if _, ok := prev[refTable]; !ok {
+					prev[refTable] = current
+					queue = append(queue, refTable)
+				}
+			}
+		}
+		if last == "" {
+			continue
+		}
+		cycle := []string{start}
+		for table := last; table != start; table = prev[table] {
+			cycle = append(cycle, table)
+		}
+		// 路径按回溯顺序收集，反转为依赖方向
+		for i, j := 1, len(cycle)-1; i < j; i, j = i+1, j-1 {
+			cycle[i], cycle[j] = cycle[j], cycle[i]
+		}
+		cycle = append(cycle, start)
+		for _, table := range cycle {
+			covered[table] = true
+		}
+		cycles = append(cycles, cycle)
+	}
+	return
+}
+
+// TableCreationOrder 返回建表顺序，存在循环依赖时告警，外键需在全部表创建后追加
+func TableCreationOrder(ctx context.Context, tableFieldsMap map[string][]*Field, foreignKeysMap map[string][]*ForeignKeyInfo) []string {
+	tableNames := make([]string, 0, len(tableFieldsMap))
+	for tableName := range tableFieldsMap {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+	sorted, cycles := SortTablesByDependency(tableNames, foreignKeysMap)
+	for _, cycle := range cycles {
+		log.GetLogger(ctx).Warn("foreign key cycle detected: %s, constraints will be added after all tables are created",
+			strings.Join(cycle, " -> "))
+	}
+	return sorted
+}
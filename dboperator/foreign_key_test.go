@@ -0,0 +1,45 @@
+package dboperator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortTablesByDependency(t *testing.T) {
+	foreignKeysMap := map[string][]*ForeignKeyInfo{
+		"order":      {{RefTableName: "user"}, {RefTableName: "product"}},
+		"order_item": {{RefTableName: "order"}, {RefTableName: "order_item"}},
+		"user":       {{RefTableName: "dept"}},
+		"a":          {{RefTableName: "b"}},
+		"b":          {{RefTableName: "c"}},
+		"c":          {{RefTableName: "a"}},
+	}
+	tableNames := []string{"a", "b", "c", "dept", "order", "order_item", "product", "user"}
+	sorted, cycles := SortTablesByDependency(tableNames, foreignKeysMap)
+
+	expected := []string{"dept", "product", "user", "order", "order_item", "a", "b", "c"}
+	if !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("sorted = %v, expected %v", sorted, expected)
+	}
+	expectedCycles := [][]string{{"a", "b", "c", "a"}}
+	if !reflect.DeepEqual(cycles, expectedCycles) {
+		t.Errorf("cycles = %v, expected %v", cycles, expectedCycles)
+	}
+}
+
+func TestNormalizeReferentialAction(t *testing.T) {
+	cases := map[string]string{
+		"":            "",
+		"NO ACTION":   "",
+		"NO_ACTION":   "",
+		"cascade":     ActionCascade,
+		"SET_NULL":    ActionSetNull,
+		"SET DEFAULT": ActionSetDefault,
+		"RESTRICT":    ActionRestrict,
+	}
+	for raw, expected := range cases {
+		if actual := NormalizeReferentialAction(raw); actual != expected {
+			t.Errorf("NormalizeReferentialAction(%q) = %q, expected %q", raw, actual, expected)
+		}
+	}
+}
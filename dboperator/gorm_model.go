@@ -0,0 +1,218 @@
+package dboperator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NullStyle 可空列的Go类型写法
+type NullStyle string
+
+const (
+	NullStylePointer NullStyle = "pointer" // 指针，如 *string
+	NullStyleSQL     NullStyle = "sql"     // database/sql 的 Null 类型，如 sql.NullString
+)
+
+// ModelOptions 生成GORM模型的可选配置
+type ModelOptions struct {
+	PackageName string    // 包名，默认 model
+	NullStyle   NullStyle // 可空列的写法，默认指针
+	JSONTag     bool      // 是否生成 json 标签，名称为列名
+}
+
+// commonInitialisms 结构体及字段名中保持大写的缩写，同 golint
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "GUID": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true, "QPS": true,
+	"RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true, "URI": true, "URL": true,
+	"UTF8": true, "VM": true, "XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// sqlNullTypes Go类型对应的 database/sql Null 类型，无对应类型的取可容纳的类型
+var sqlNullTypes = map[string]string{
+	"string": "sql.NullString", "bool": "sql.NullBool", "time.Time": "sql.NullTime",
+	"int8": "sql.NullInt16", "uint8": "sql.NullByte", "int16": "sql.NullInt16", "uint16": "sql.NullInt32",
+	"int32": "sql.NullInt32", "uint32": "sql.NullInt64", "int64": "sql.NullInt64", "uint64": "sql.NullInt64",
+	"float32": "sql.NullFloat64", "float64": "sql.NullFloat64",
+}
+
+// GenerateModels 由表结构生成带 gorm 标签的Go结构体，字段类型由 transfer 将列类型转为通用字段后映射，
+// 可空列按 options.NullStyle 生成指针或 sql.Null 类型，[]byte 本身可为 nil 不做处理。
+// 标签包含列名、类型、主键、自增、非空、默认值、唯一索引及注释，并生成 TableName 方法；结果已格式化
+func GenerateModels(tables []*Table, transfer ITransfer, options *ModelOptions) (source []byte, err error) {
+	if options == nil {
+		options = &ModelOptions{}
+	}
+	packageName := options.PackageName
+	if packageName == "" {
+		packageName = "model"
+	}
+	sorted := append([]*Table(nil), tables...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var body bytes.Buffer
+	imports := make(map[string]bool)
+	structNames := make(map[string]bool)
+	for _, table := range sorted {
+		structName := uniqueGoName(GoName(table.Name), structNames)
+		writeModel(&body, table, structName, transfer, options, imports)
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "// Code generated by dbutil. DO NOT EDIT.\n\npackage %s\n\n", packageName)
+	if len(imports) > 0 {
+		buffer.WriteString("import (\n")
+		for _, path := range []string{"database/sql", "time"} {
+			if imports[path] {
+				fmt.Fprintf(&buffer, "\t%q\n", path)
+			}
+		}
+		buffer.WriteString(")\n\n")
+	}
+	buffer.Write(body.Bytes())
+	return format.Source(buffer.Bytes())
+}
+
+func writeModel(buffer *bytes.Buffer, table *Table, structName string, transfer ITransfer, options *ModelOptions, imports map[string]bool) {
+	primaryKeys := make(map[string]bool)
+	for _, constraint := range table.ConstraintsOf(ConstraintPrimaryKey) {
+		for _, column := range constraint.Columns {
+			primaryKeys[column] = true
+		}
+	}
+	uniqueIndexes := make(map[string][]string)
+	for _, constraint := range table.ConstraintsOf(ConstraintUnique) {
+		for _, column := range constraint.Columns {
+			uniqueIndexes[column] = append(uniqueIndexes[column], constraint.Name)
+		}
+	}
+
+	if table.Comment != "" {
+		fmt.Fprintf(buffer, "// %s %s\n", structName, singleLine(table.Comment))
+	} else {
+		fmt.Fprintf(buffer, "// %s 表 %s\n", structName, table.Name)
+	}
+	fmt.Fprintf(buffer, "type %s struct {\n", structName)
+	fieldNames := make(map[string]bool)
+	for _, column := range table.Columns {
+		fieldName := uniqueGoName(GoName(column.Name), fieldNames)
+		goType := modelGoType(transfer.Trans2CommonField(column.DataType), column.Nullable && !primaryKeys[column.Name], options.NullStyle)
+		switch {
+		case strings.HasPrefix(goType, "sql."):
+			imports["database/sql"] = true
+		case strings.HasSuffix(goType, "time.Time"):
+			imports["time"] = true
+		}
+
+		settings := []string{"column:" + column.Name, "type:" + column.DataType}
+		if primaryKeys[column.Name] {
+			settings = append(settings, "primaryKey")
+		}
+		if column.AutoIncrement {
+			settings = append(settings, "autoIncrement")
+		}
+		if !column.Nullable {
+			settings = append(settings, "not null")
+		}
+		if defaultValue := NormalizeDefaultValue(column.Default); defaultValue != "" && !column.AutoIncrement {
+			settings = append(settings, "default:"+defaultValue)
+		}
+		for _, indexName := range uniqueIndexes[column.Name] {
+			settings = append(settings, "uniqueIndex:"+indexName)
+		}
+		if column.Comment != "" {
+			settings = append(settings, "comment:"+singleLine(column.Comment))
+		}
+		for i, setting := range settings {
+			settings[i] = strings.ReplaceAll(setting, ";", `\;`)
+		}
+		tag := "gorm:" + strconv.Quote(strings.Join(settings, ";"))
+		if options.JSONTag {
+			tag += " json:" + strconv.Quote(column.Name)
+		}
+		tag = strings.ReplaceAll(tag, "`", "'")
+		fmt.Fprintf(buffer, "\t%s %s `%s`", fieldName, goType, tag)
+		if column.Comment != "" {
+			fmt.Fprintf(buffer, " // %s", singleLine(column.Comment))
+		}
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("}\n\n")
+	fmt.Fprintf(buffer, "// TableName 表名\nfunc (%s) TableName() string {\n\treturn %s\n}\n\n", structName, strconv.Quote(table.Name))
+}
+
+// modelGoType 通用字段对应的Go类型，无法识别的类型为 string
+func modelGoType(field *Field, nullable bool, nullStyle NullStyle) string {
+	goType := "string"
+	if field != nil {
+		switch field.Type {
+		case BYTES, RUNES:
+			return "[]byte"
+		case INT8, INT16, INT32, INT64:
+			goType = string(field.Type)
+			if field.IsUnsigned {
+				goType = "u" + goType
+			}
+		case FLOAT32, FLOAT64, BOOL:
+			goType = string(field.Type)
+		case TIME:
+			goType = "time.Time"
+		}
+	}
+	if !nullable {
+		return goType
+	}
+	if nullStyle == NullStyleSQL {
+		return sqlNullTypes[goType]
+	}
+	return "*" + goType
+}
+
+// GoName 将表名、列名转为导出的Go标识符，如 user_id 为 UserID，非字母数字的字符作为分隔，数字开头时加前缀 X
+func GoName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var builder strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			builder.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		// 全大写的名称(如oracle)按小写处理
+		if strings.ToUpper(word) == word {
+			runes = []rune(strings.ToLower(word))
+		}
+		runes[0] = unicode.ToUpper(runes[0])
+		builder.WriteString(string(runes))
+	}
+	goName := builder.String()
+	if goName == "" {
+		return "X"
+	}
+	if first := []rune(goName)[0]; !unicode.IsUpper(first) {
+		goName = "X" + goName
+	}
+	return goName
+}
+
+// uniqueGoName 名称已使用时追加序号
+func uniqueGoName(name string, used map[string]bool) string {
+	result := name
+	for i := 2; used[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+	used[result] = true
+	return result
+}
+
+func singleLine(comment string) string {
+	return strings.Join(strings.Fields(comment), " ")
+}
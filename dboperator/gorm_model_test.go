@@ -0,0 +1,71 @@
+package dboperator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateModels(t *testing.T) {
+	transfer := mapTransfer{
+		"bigint unsigned": {Type: INT64, IsUnsigned: true},
+		"varchar(50)":     {Type: STRING, Length: 50},
+		"datetime":        {Type: TIME, TimeType: "datetime"},
+		"blob":            {Type: BYTES},
+		"int":             {Type: INT32},
+	}
+	tables := []*Table{
+		{
+			Name:    "user_accounts",
+			Comment: "用户\n账号",
+			Columns: []*Column{
+				{Name: "id", DataType: "bigint unsigned", AutoIncrement: true},
+				{Name: "user_name", DataType: "varchar(50)", Default: "'a;b'", Comment: "登录名"},
+				{Name: "login_at", DataType: "datetime", Nullable: true},
+				{Name: "avatar", DataType: "blob", Nullable: true},
+				{Name: "tenant_id", DataType: "int", Nullable: true},
+			},
+			Constraints: []*Constraint{
+				{Type: ConstraintPrimaryKey, Columns: []string{"id"}},
+				{Name: "uk_tenant_name", Type: ConstraintUnique, Columns: []string{"tenant_id", "user_name"}},
+			},
+		},
+		{Name: "2fa", Columns: []*Column{{Name: "CODE", DataType: "varchar(50)"}}},
+	}
+
+	source, err := GenerateModels(tables, transfer, &ModelOptions{JSONTag: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 忽略 gofmt 的对齐
+	code := strings.Join(strings.Fields(string(source)), " ")
+	for _, expected := range []string{
+		"package model",
+		"\"time\"",
+		"// UserAccounts 用户 账号",
+		"ID uint64 `gorm:\"column:id;type:bigint unsigned;primaryKey;autoIncrement;not null\" json:\"id\"`",
+		"UserName string `gorm:\"column:user_name;type:varchar(50);not null;default:'a\\\\;b';uniqueIndex:uk_tenant_name;comment:登录名\" json:\"user_name\"` // 登录名",
+		"LoginAt *time.Time `gorm:\"column:login_at;type:datetime\" json:\"login_at\"`",
+		"Avatar []byte",
+		"TenantID *int32",
+		"func (UserAccounts) TableName() string {\n\treturn \"user_accounts\"\n}",
+		"type X2fa struct {\n\tCode string",
+	} {
+		if !strings.Contains(code, strings.Join(strings.Fields(expected), " ")) {
+			t.Errorf("missing %s in:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "database/sql") {
+		t.Error("unexpected database/sql import for pointer style")
+	}
+
+	source, err = GenerateModels(tables, transfer, &ModelOptions{PackageName: "entity", NullStyle: NullStyleSQL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code = strings.Join(strings.Fields(string(source)), " ")
+	for _, expected := range []string{"package entity", "\"database/sql\"", "LoginAt sql.NullTime", "TenantID sql.NullInt32"} {
+		if !strings.Contains(code, strings.Join(strings.Fields(expected), " ")) {
+			t.Errorf("missing %s in:\n%s", expected, code)
+		}
+	}
+}
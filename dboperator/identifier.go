@@ -0,0 +1,255 @@
+package dboperator
+
+import (
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// IdentifierCase 标识符大小写
+type IdentifierCase string
+
+const (
+	IdentifierCasePreserve IdentifierCase = "preserve" // 保持原样
+	IdentifierCaseLower    IdentifierCase = "lower"    // 转小写
+	IdentifierCaseUpper    IdentifierCase = "upper"    // 转大写
+)
+
+// plainIdentifierReg 无需加引号的标识符，其余字符(含中文)一律加引号
+var plainIdentifierReg = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// commonReservedWords 各数据库均保留的关键字
+const commonReservedWords = `ADD ALL ALTER AND ANY AS ASC BETWEEN BY CASE CAST CHECK COLUMN CONSTRAINT CREATE CROSS
+CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER DEFAULT DELETE DESC DISTINCT DROP ELSE END EXCEPT EXISTS
+FALSE FETCH FOR FOREIGN FROM FULL GRANT GROUP HAVING IN INNER INSERT INTERSECT INTO IS JOIN LEFT LIKE NOT NULL ON OR
+ORDER OUTER PRIMARY REFERENCES RIGHT SELECT SET TABLE THEN TO TRUE UNION UNIQUE UPDATE USING VALUES WHEN WHERE WITH`
+
+// dialectReservedWords 各数据库特有的保留字
+var dialectReservedWords = map[dbx.DBType]string{
+	dbx.DBTypePostgres: `ANALYSE ANALYZE ARRAY ASYMMETRIC AUTHORIZATION BINARY BOTH COLLATE COLLATION CONCURRENTLY
+CURRENT_CATALOG CURRENT_ROLE CURRENT_SCHEMA DEFERRABLE DO FREEZE ILIKE INITIALLY ISNULL LATERAL LEADING LIMIT
+LOCALTIME LOCALTIMESTAMP NATURAL NOTNULL OFFSET ONLY OVERLAPS PLACING RETURNING SESSION_USER SIMILAR SOME SYMMETRIC
+TABLESAMPLE TRAILING USER VARIADIC VERBOSE WINDOW`,
+	dbx.DBTypeMySQL: `ACCESSIBLE BEFORE BIGINT BINARY BLOB BOTH CALL CASCADE CHANGE CHAR CHARACTER CONDITION CONTINUE
+CONVERT CURSOR DATABASE DATABASES DAY_HOUR DAY_MICROSECOND DAY_MINUTE DAY_SECOND DEC DECIMAL DECLARE DELAYED
+DESCRIBE DETERMINISTIC DISTINCTROW DIV DOUBLE DUAL EACH ELSEIF ENCLOSED ESCAPED EXIT EXPLAIN FLOAT FORCE FULLTEXT
+FUNCTION GENERATED GROUPS HIGH_PRIORITY HOUR_MINUTE HOUR_SECOND IF IGNORE INDEX INFILE INOUT INT INTEGER INTERVAL
+KEY KEYS KILL LEADING LEAVE LIMIT LINEAR LINES LOAD LOCALTIME LOCALTIMESTAMP LOCK LONG LOOP MATCH MINUTE_SECOND MOD
+MODIFIES NATURAL NUMERIC OPTIMIZE OPTION OUT OVER PARTITION PRECISION PROCEDURE PURGE RANGE RANK READ REAL REGEXP
+RELEASE RENAME REPEAT REPLACE REQUIRE RESTRICT RETURN REVOKE RLIKE ROW ROWS SCHEMA SCHEMAS SEPARATOR SHOW SMALLINT
+SPATIAL SQL STARTING STORED TERMINATED TINYINT TRAILING TRIGGER UNDO UNLOCK UNSIGNED USAGE USE VARCHAR VIRTUAL
+WHILE WINDOW WRITE XOR YEAR_MONTH ZEROFILL`,
+	dbx.DBTypeOracle: `ACCESS AUDIT CHAR CLUSTER COMMENT COMPRESS CONNECT CURRENT DATE DECIMAL EXCLUSIVE FILE FLOAT
+IDENTIFIED IMMEDIATE INCREMENT INDEX INITIAL INTEGER LEVEL LOCK LONG MAXEXTENTS MINUS MLSLABEL MODE MODIFY NOAUDIT
+NOCOMPRESS NOWAIT NUMBER OF OFFLINE ONLINE OPTION PCTFREE PRIOR PUBLIC RAW RENAME RESOURCE REVOKE ROW ROWID ROWNUM
+ROWS SESSION SHARE SIZE SMALLINT START SUCCESSFUL SYNONYM SYSDATE TRIGGER UID USER VALIDATE VARCHAR VARCHAR2 VIEW
+WHENEVER`,
+	dbx.DBTypeDM: `ACCESS AUDIT CHAR CLUSTER COMMENT COMPRESS CONNECT CURRENT DATE DECIMAL EXCLUSIVE FILE FLOAT
+IDENTIFIED IDENTITY IMMEDIATE INCREMENT INDEX INITIAL INTEGER LEVEL LIMIT LOCK LONG MINUS MODE MODIFY NOWAIT NUMBER
+OF OFFSET OPTION PRIOR PUBLIC RAW RENAME REVOKE ROW ROWID ROWNUM ROWS SESSION SIZE SMALLINT START SYNONYM SYSDATE
+TOP TRIGGER USER VARCHAR VARCHAR2 VIEW`,
+	dbx.DBTypeSqlserver: `BACKUP BEGIN BREAK BROWSE BULK CASCADE CHECKPOINT CLOSE CLUSTERED COALESCE COLLATE COMMIT
+COMPUTE CONTAINS CONTAINSTABLE CONTINUE CONVERT CURSOR DATABASE DBCC DEALLOCATE DECLARE DENY DISK DISTRIBUTED
+DOUBLE DUMP ERRLVL ESCAPE EXEC EXECUTE EXIT EXTERNAL FILE FILLFACTOR FREETEXT FREETEXTTABLE FUNCTION GOTO HOLDLOCK
+IDENTITY IDENTITY_INSERT IDENTITYCOL IF INDEX KEY KILL LINENO LOAD MERGE NATIONAL NOCHECK NONCLUSTERED NULLIF OF
+OFF OFFSETS OPEN OPENDATASOURCE OPENQUERY OPENROWSET OPENXML OPTION OVER PERCENT PIVOT PLAN PRECISION PRINT PROC
+PROCEDURE PUBLIC RAISERROR READ READTEXT RECONFIGURE REPLICATION RESTORE RESTRICT RETURN REVERT REVOKE ROLLBACK
+ROWCOUNT ROWGUIDCOL RULE SAVE SCHEMA SECURITYAUDIT SESSION_USER SETUSER SHUTDOWN SOME STATISTICS SYSTEM_USER
+TABLESAMPLE TEXTSIZE TOP TRAN TRANSACTION TRIGGER TRUNCATE TSEQUAL UNPIVOT UPDATETEXT USE USER VARYING VIEW
+WAITFOR WHILE WITHIN WRITETEXT`,
+	dbx.DBTypeSQLite: `ABORT ACTION AFTER ANALYZE ATTACH AUTOINCREMENT BEFORE BEGIN CASCADE COLLATE COMMIT CONFLICT
+DATABASE DEFERRABLE DEFERRED DETACH EACH ESCAPE EXCLUSIVE EXPLAIN FAIL GLOB IF IGNORE IMMEDIATE INDEX INDEXED
+INITIALLY INSTEAD ISNULL KEY LIMIT MATCH NATURAL NO NOTNULL OF OFFSET PLAN PRAGMA QUERY RAISE RECURSIVE REGEXP
+REINDEX RELEASE RENAME REPLACE RESTRICT ROLLBACK ROW SAVEPOINT TEMP TEMPORARY TRANSACTION TRIGGER VACUUM VIEW
+VIRTUAL`,
+}
+
+// identifierHashLength 缩短名称时追加的哈希后缀长度，含下划线
+const identifierHashLength = 9
+
+// identifierPolicies 各数据库的标识符规则，可在生成DDL前修改。
+// 最大长度按字节计，oracle 12.2以下为30字节，12.2及以上可将 MaxLength 改为128
+var identifierPolicies = map[dbx.DBType]*IdentifierPolicy{
+	dbx.DBTypePostgres:  NewIdentifierPolicy(dbx.DBTypePostgres, `"`, `"`, IdentifierCaseLower, 63),
+	dbx.DBTypeMySQL:     NewIdentifierPolicy(dbx.DBTypeMySQL, "`", "`", IdentifierCasePreserve, 64),
+	dbx.DBTypeOracle:    NewIdentifierPolicy(dbx.DBTypeOracle, `"`, `"`, IdentifierCaseUpper, 30),
+	dbx.DBTypeDM:        NewIdentifierPolicy(dbx.DBTypeDM, `"`, `"`, IdentifierCaseUpper, 128),
+	dbx.DBTypeSqlserver: NewIdentifierPolicy(dbx.DBTypeSqlserver, "[", "]", IdentifierCasePreserve, 128),
+	dbx.DBTypeSQLite:    NewIdentifierPolicy(dbx.DBTypeSQLite, `"`, `"`, IdentifierCasePreserve, 0),
+}
+
+// IdentifierPolicy 标识符规则：引号、大小写折叠、长度限制及保留字。
+// Quote 用于引用已存在的对象，名称原样保留；Name 用于新建的对象，名称先按 Case 折叠，超长时缩短
+type IdentifierPolicy struct {
+	QuoteStart   string
+	QuoteEnd     string
+	UnquotedCase IdentifierCase // 数据库对未加引号标识符的折叠方式，preserve 表示不区分大小写
+	Case         IdentifierCase // 新建对象名称的折叠方式，默认保持源库大小写
+	AlwaysQuote  bool           // 是否总是加引号，默认仅在名称含特殊字符、为保留字或大小写会被折叠时加引号
+	MaxLength    int            // 标识符最大字节数，0 表示不限制
+
+	reservedWords map[string]bool
+}
+
+// NewIdentifierPolicy 创建标识符规则，保留字为通用保留字及 dbType 特有的保留字
+func NewIdentifierPolicy(dbType dbx.DBType, quoteStart, quoteEnd string, unquotedCase IdentifierCase, maxLength int) *IdentifierPolicy {
+	reservedWords := make(map[string]bool)
+	for _, word := range strings.Fields(commonReservedWords + " " + dialectReservedWords[dbType]) {
+		reservedWords[word] = true
+	}
+	return &IdentifierPolicy{
+		QuoteStart:    quoteStart,
+		QuoteEnd:      quoteEnd,
+		UnquotedCase:  unquotedCase,
+		Case:          IdentifierCasePreserve,
+		MaxLength:     maxLength,
+		reservedWords: reservedWords,
+	}
+}
+
+// GetIdentifierPolicy 获取数据库的标识符规则，未注册的数据库按标准SQL以双引号引用
+func GetIdentifierPolicy(dbType dbx.DBType) *IdentifierPolicy {
+	if policy, ok := identifierPolicies[dbType]; ok {
+		return policy
+	}
+	return NewIdentifierPolicy(dbType, `"`, `"`, IdentifierCasePreserve, 0)
+}
+
+// IsReserved 是否保留字，忽略大小写
+func (p *IdentifierPolicy) IsReserved(name string) bool {
+	return p.reservedWords[strings.ToUpper(name)]
+}
+
+// NeedQuote 名称是否需要加引号
+func (p *IdentifierPolicy) NeedQuote(name string) bool {
+	if p.AlwaysQuote || !plainIdentifierReg.MatchString(name) || p.IsReserved(name) {
+		return true
+	}
+	return foldCase(name, p.UnquotedCase) != name
+}
+
+// Fold 新建对象在数据库中的名称，按 Case 折叠，超出 MaxLength 时缩短
+func (p *IdentifierPolicy) Fold(name string) string {
+	return p.Shorten(foldCase(name, p.Case))
+}
+
+// Shorten 名称超出 MaxLength 时截断并追加完整名称的哈希，同一名称的结果总是相同，不同名称截断后依哈希区分。
+// 名称不含小写字母时哈希以大写表示，避免仅因哈希而需加引号
+func (p *IdentifierPolicy) Shorten(name string) string {
+	if p.MaxLength <= identifierHashLength || len(name) <= p.MaxLength {
+		return name
+	}
+	// 按字符截断，不拆分多字节字符
+	end := p.MaxLength - identifierHashLength
+	for end > 0 && !utf8.RuneStart(name[end]) {
+		end--
+	}
+	prefix := name[:end]
+	hash := fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(name)))
+	if strings.ToUpper(prefix) == prefix {
+		hash = strings.ToUpper(hash)
+	}
+	return prefix + "_" + hash
+}
+
+// Quote 引用已存在的对象，需要时加引号，名称中的引号转义
+func (p *IdentifierPolicy) Quote(name string) string {
+	if !p.NeedQuote(name) {
+		return name
+	}
+	return p.QuoteStart + strings.ReplaceAll(name, p.QuoteEnd, p.QuoteEnd+p.QuoteEnd) + p.QuoteEnd
+}
+
+// Name 新建对象的名称，按 Case 折叠后引用
+func (p *IdentifierPolicy) Name(name string) string {
+	return p.Quote(p.Fold(name))
+}
+
+// QualifiedName 模式下新建对象的名称，如 schema.table，模式名为空时不限定
+func (p *IdentifierPolicy) QualifiedName(schemaName, name string) string {
+	if schemaName == "" {
+		return p.Name(name)
+	}
+	return p.Name(schemaName) + "." + p.Name(name)
+}
+
+// QualifiedQuote 引用模式下已存在的对象，模式名按 Name 处理，为空时不限定
+func (p *IdentifierPolicy) QualifiedQuote(schemaName, name string) string {
+	if schemaName == "" {
+		return p.Quote(name)
+	}
+	return p.Name(schemaName) + "." + p.Quote(name)
+}
+
+// QuoteList 引用已存在的多个对象，以逗号连接，如主键列
+func (p *IdentifierPolicy) QuoteList(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, p.Quote(name))
+	}
+	return strings.Join(quoted, ",")
+}
+
+// QuoteExpression 将已归一化表达式(见 NormalizeCheckExpression、NormalizeViewDefinition)中加双引号的标识符改为本规则的写法
+func (p *IdentifierPolicy) QuoteExpression(expression string) string {
+	return RewriteIdentifiers(expression, p.Name)
+}
+
+// RewriteIdentifiers 将已归一化表达式中加双引号的标识符替换为 rewrite 的结果，字符串常量保持不变
+func RewriteIdentifiers(expression string, rewrite func(name string) string) string {
+	var builder strings.Builder
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '\'':
+			end := i + 1
+			for end < len(expression) {
+				if expression[end] == '\'' {
+					if end+1 < len(expression) && expression[end+1] == '\'' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end, len(expression)-1)
+			builder.WriteString(expression[i : end+1])
+			i = end
+		case '"':
+			end := i + 1
+			for end < len(expression) {
+				if expression[end] == '"' {
+					if end+1 < len(expression) && expression[end+1] == '"' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= len(expression) {
+				builder.WriteString(expression[i:])
+				return builder.String()
+			}
+			builder.WriteString(rewrite(strings.ReplaceAll(expression[i+1:end], `""`, `"`)))
+			i = end
+		default:
+			builder.WriteByte(expression[i])
+		}
+	}
+	return builder.String()
+}
+
+func foldCase(name string, identifierCase IdentifierCase) string {
+	switch identifierCase {
+	case IdentifierCaseLower:
+		return strings.ToLower(name)
+	case IdentifierCaseUpper:
+		return strings.ToUpper(name)
+	}
+	return name
+}
@@ -0,0 +1,66 @@
+package dboperator
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestIdentifierPolicy(t *testing.T) {
+	cases := []struct {
+		dbType   dbx.DBType
+		name     string
+		expected string
+	}{
+		{dbx.DBTypeMySQL, "order", "`order`"},
+		{dbx.DBTypeMySQL, "UserName", "UserName"},
+		{dbx.DBTypeMySQL, "a`b", "`a``b`"},
+		{dbx.DBTypeSqlserver, "user", "[user]"},
+		{dbx.DBTypeSqlserver, "a]b", "[a]]b]"},
+		{dbx.DBTypePostgres, "orders", "orders"},
+		{dbx.DBTypePostgres, "ORDERS", `"ORDERS"`},
+		{dbx.DBTypeOracle, "ORDERS", "ORDERS"},
+		{dbx.DBTypeOracle, "orders", `"orders"`},
+		{dbx.DBTypeOracle, "LEVEL", `"LEVEL"`},
+		{dbx.DBTypeSQLite, "用户", `"用户"`},
+	}
+	for _, c := range cases {
+		if quoted := GetIdentifierPolicy(c.dbType).Quote(c.name); quoted != c.expected {
+			t.Errorf("%s Quote(%s) = %s, expected %s", c.dbType, c.name, quoted, c.expected)
+		}
+	}
+
+	policy := NewIdentifierPolicy(dbx.DBTypePostgres, `"`, `"`, IdentifierCaseLower, 63)
+	policy.Case = IdentifierCaseLower
+	if name := policy.QualifiedName("APP", "ORDERS"); name != "app.orders" {
+		t.Errorf("QualifiedName = %s, expected app.orders", name)
+	}
+	expression := policy.QuoteExpression(`"STATUS" IN ('A"B', 'it''s') AND "User" > 0`)
+	if expected := `status IN ('A"B', 'it''s') AND "user" > 0`; expression != expected {
+		t.Errorf("QuoteExpression = %s, expected %s", expression, expected)
+	}
+}
+
+func TestIdentifierShorten(t *testing.T) {
+	policy := GetIdentifierPolicy(dbx.DBTypeOracle)
+	long := "CUSTOMER_ORDER_ITEM_ATTRIBUTE_HISTORY"
+	shortened := policy.Shorten(long)
+	if len(shortened) != policy.MaxLength || shortened != policy.Shorten(long) {
+		t.Errorf("Shorten(%s) = %s, expected deterministic name of %d bytes", long, shortened, policy.MaxLength)
+	}
+	if other := policy.Shorten(long + "_ARCHIVE"); other == shortened {
+		t.Errorf("Shorten of different names both yield %s", shortened)
+	}
+	if quoted := policy.Name(long); quoted != shortened {
+		t.Errorf("Name(%s) = %s, expected unquoted %s", long, quoted, shortened)
+	}
+	if name := policy.Shorten("ORDERS"); name != "ORDERS" {
+		t.Errorf("Shorten(ORDERS) = %s, expected unchanged", name)
+	}
+
+	multiByte := "订单明细历史记录归档备份表"
+	if name := policy.Shorten(multiByte); len(name) > policy.MaxLength || !utf8.ValidString(name) {
+		t.Errorf("Shorten(%s) = %s, expected valid utf-8 within %d bytes", multiByte, name, policy.MaxLength)
+	}
+}
@@ -0,0 +1,50 @@
+package dboperator
+
+import (
+	"regexp"
+)
+
+var quotedIdentReg = regexp.MustCompile(`^"([^"]+)"$`)
+
+// GroupIndexColumns 将按 表名、索引名、列序号 排序的索引列汇总为各表索引
+func GroupIndexColumns(rows []*TableIndexColumn) (indexInfo map[string][]*IndexInfo) {
+	indexInfo = make(map[string][]*IndexInfo)
+	indexMap := make(map[string]*IndexInfo)
+	for _, row := range rows {
+		key := row.TableName + "." + row.IndexName
+		index, ok := indexMap[key]
+		if !ok {
+			index = &IndexInfo{
+				IndexName: row.IndexName,
+				IsUnique:  row.IsUnique,
+				Filter:    row.Filter,
+			}
+			indexMap[key] = index
+			indexInfo[row.TableName] = append(indexInfo[row.TableName], index)
+		}
+		column := &IndexColumn{
+			ColumnName: row.ColumnName,
+			Expression: row.Expression,
+			IsDesc:     row.IsDesc,
+		}
+		// oracle降序列以函数索引形式存储，表达式仅为带引号的列名
+		if matches := quotedIdentReg.FindStringSubmatch(column.Expression); len(matches) == 2 {
+			column.ColumnName, column.Expression = matches[1], ""
+		}
+		if column.Expression != "" {
+			column.ColumnName = ""
+		}
+		index.Columns = append(index.Columns, column)
+	}
+	return
+}
+
+// IsFunctional 是否包含函数索引列
+func (i *IndexInfo) IsFunctional() bool {
+	for _, column := range i.Columns {
+		if column.Expression != "" {
+			return true
+		}
+	}
+	return false
+}
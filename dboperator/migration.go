@@ -0,0 +1,180 @@
+package dboperator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/core/utils"
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// AlterAction 表结构修改动作
+type AlterAction string
+
+const (
+	AlterDropUnique     AlterAction = "drop_unique"
+	AlterDropPrimaryKey AlterAction = "drop_primary_key"
+	AlterDropColumn     AlterAction = "drop_column"
+	AlterAddColumn      AlterAction = "add_column"
+	AlterModifyColumn   AlterAction = "modify_column"
+	AlterAddPrimaryKey  AlterAction = "add_primary_key"
+	AlterAddUnique      AlterAction = "add_unique"
+)
+
+// alterOrder 修改项的执行顺序，先删除键及列，再新增、修改列，最后新增键
+var alterOrder = []AlterAction{AlterDropUnique, AlterDropPrimaryKey, AlterDropColumn, AlterAddColumn, AlterModifyColumn, AlterAddPrimaryKey, AlterAddUnique}
+
+// TableAlteration 对目标库已存在表的单项修改，表名、列名、约束名均为目标库中的实际名称，执行时原样引用
+type TableAlteration struct {
+	TableName       string
+	Action          AlterAction
+	Field           *Field   // 新增、修改列时为期望的字段，ColumnName 为目标库中的列名
+	ColumnName      string   // 删除列的列名
+	ConstraintName  string   // 删除唯一约束的约束名
+	Columns         []string // 新增主键、唯一约束的列
+	TypeChanged     bool     // 修改列时类型是否变化
+	NullableChanged bool     // 修改列时可空是否变化
+	Destructive     bool     // 是否可能丢失数据或约束，如删除列、删除键、收窄类型
+	Reason          string   // 修改原因，用于日志
+}
+
+// PlanAlterations 比较期望的表结构与目标库中已存在的表 current，生成将 current 修改为期望结构的修改项。
+// fields、primaryKeys、uniqueKeys 为期望的列及键，名称为源库(或重命名后)的名称，按 IdentifierPolicy.Fold 折叠后
+// 与目标库名称匹配，匹配不到时忽略大小写匹配；target 为目标库的类型转换，期望字段经目标库类型往返后与现有类型比较。
+// 默认值、注释、自增属性不参与比较
+func PlanAlterations(current *Table, fields []*Field, primaryKeys []string, uniqueKeys map[string][]string,
+	targetDBType dbx.DBType, target ITransfer) (alterations []*TableAlteration) {
+	identifier := GetIdentifierPolicy(targetDBType)
+	planned := make(map[AlterAction][]*TableAlteration)
+	add := func(alteration *TableAlteration) {
+		alteration.TableName = current.Name
+		planned[alteration.Action] = append(planned[alteration.Action], alteration)
+	}
+	columnName := func(name string) string {
+		folded := identifier.Fold(name)
+		if current.Column(folded) != nil {
+			return folded
+		}
+		for _, column := range current.Columns {
+			if strings.EqualFold(column.Name, folded) {
+				return column.Name
+			}
+		}
+		return folded
+	}
+
+	desired := make(map[string]bool)
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		name := columnName(field.ColumnName)
+		desired[name] = true
+		targetField := *field
+		targetField.ColumnName = name
+		column := current.Column(name)
+		if column == nil {
+			add(&TableAlteration{Action: AlterAddColumn, Field: &targetField, Reason: "column not exists"})
+			continue
+		}
+		if alteration := planModifyColumn(column, &targetField, targetDBType, target); alteration != nil {
+			add(alteration)
+		}
+	}
+	for _, column := range current.Columns {
+		if !desired[column.Name] {
+			add(&TableAlteration{Action: AlterDropColumn, ColumnName: column.Name, Destructive: true, Reason: "column not in source"})
+		}
+	}
+
+	desiredPrimaryKey := make([]string, 0, len(primaryKeys))
+	for _, key := range primaryKeys {
+		desiredPrimaryKey = append(desiredPrimaryKey, columnName(key))
+	}
+	var currentPrimaryKey []string
+	if constraints := current.ConstraintsOf(ConstraintPrimaryKey); len(constraints) > 0 {
+		currentPrimaryKey = constraints[0].Columns
+	}
+	if !sameColumns(currentPrimaryKey, desiredPrimaryKey) {
+		if len(currentPrimaryKey) > 0 {
+			add(&TableAlteration{Action: AlterDropPrimaryKey, Columns: currentPrimaryKey, Destructive: true,
+				Reason: fmt.Sprintf("primary key (%s) changed", strings.Join(currentPrimaryKey, ","))})
+		}
+		if len(desiredPrimaryKey) > 0 {
+			// 原主键未删除时无法新增主键，随删除一同视为破坏性修改
+			add(&TableAlteration{Action: AlterAddPrimaryKey, Columns: desiredPrimaryKey, Destructive: len(currentPrimaryKey) > 0,
+				Reason: fmt.Sprintf("primary key (%s)", strings.Join(desiredPrimaryKey, ","))})
+		}
+	}
+
+	unmatched := make(map[string][]string)
+	for _, constraint := range current.ConstraintsOf(ConstraintUnique) {
+		unmatched[constraint.Name] = constraint.Columns
+	}
+	for _, name := range utils.SortedKeys(uniqueKeys) {
+		columns := make([]string, 0, len(uniqueKeys[name]))
+		for _, column := range uniqueKeys[name] {
+			columns = append(columns, columnName(column))
+		}
+		matched := false
+		for _, constraintName := range utils.SortedKeys(unmatched) {
+			if sameColumns(unmatched[constraintName], columns) {
+				delete(unmatched, constraintName)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add(&TableAlteration{Action: AlterAddUnique, Columns: columns})
+		}
+	}
+	for _, constraintName := range utils.SortedKeys(unmatched) {
+		add(&TableAlteration{Action: AlterDropUnique, ConstraintName: constraintName, Columns: unmatched[constraintName],
+			Destructive: true, Reason: "unique key not in source"})
+	}
+
+	for _, action := range alterOrder {
+		alterations = append(alterations, planned[action]...)
+	}
+	return
+}
+
+// planModifyColumn 类型或可空发生变化时生成修改列，类型收窄、无法转换或可空改为非空时视为破坏性修改
+func planModifyColumn(column *Column, field *Field, targetDBType dbx.DBType, target ITransfer) *TableAlteration {
+	alteration := &TableAlteration{Action: AlterModifyColumn, Field: field}
+	reasons := make([]string, 0, 2)
+	currentField := target.Trans2CommonField(column.DataType)
+	desiredType := target.Trans2DataType(field)
+	desiredField := target.Trans2CommonField(desiredType)
+	if currentField != nil && desiredField != nil && describeNumber(targetDBType, currentField) != describeNumber(targetDBType, desiredField) {
+		alteration.TypeChanged = true
+		reasons = append(reasons, fmt.Sprintf("type %s -> %s", column.DataType, desiredType))
+		if level, _ := CompareField(targetDBType, targetDBType, currentField, desiredField); level == CompatibilityLossy || level == CompatibilityUnsupported {
+			alteration.Destructive = true
+		}
+	}
+	if column.Nullable != field.ISNullable {
+		alteration.NullableChanged = true
+		reasons = append(reasons, fmt.Sprintf("nullable %t -> %t", column.Nullable, field.ISNullable))
+		// 已有空值时无法改为非空
+		alteration.Destructive = alteration.Destructive || column.Nullable
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	alteration.Reason = strings.Join(reasons, ", ")
+	return alteration
+}
+
+// sameColumns 列名忽略大小写比较，顺序需一致
+func sameColumns(left, right []string) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if !strings.EqualFold(left[i], right[i]) {
+			return false
+		}
+	}
+	return true
+}
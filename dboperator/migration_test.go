@@ -0,0 +1,115 @@
+package dboperator
+
+import (
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// describeTransfer 以 describeField 作为类型写法的类型转换，仅用于测试
+type describeTransfer map[string]*Field
+
+func (d describeTransfer) Trans2CommonField(dataType string) *Field {
+	return d[dataType]
+}
+
+func (d describeTransfer) Trans2DataType(field *Field) string {
+	return describeField(field)
+}
+
+func TestPlanAlterations(t *testing.T) {
+	transfer := describeTransfer{}
+	for _, field := range []*Field{
+		{Type: INT64}, {Type: INT32}, {Type: STRING, Length: 50}, {Type: STRING, Length: 100}, {Type: STRING, Length: 200},
+	} {
+		transfer[describeField(field)] = field
+	}
+	current := &Table{
+		Name: "users",
+		Columns: []*Column{
+			{Name: "id", DataType: "int64"},
+			{Name: "name", DataType: "string(50)"},
+			{Name: "age", DataType: "int64", Nullable: true},
+			{Name: "legacy", DataType: "int32", Nullable: true},
+		},
+		Constraints: []*Constraint{
+			{Type: ConstraintPrimaryKey, Columns: []string{"id"}},
+			{Name: "users_email_key", Type: ConstraintUnique, Columns: []string{"legacy"}},
+		},
+	}
+	fields := []*Field{
+		{ColumnName: "ID", Type: INT64},
+		{ColumnName: "Name", Type: STRING, Length: 100},
+		{ColumnName: "age", Type: INT32},
+		{ColumnName: "email", Type: STRING, Length: 200, ISNullable: true},
+	}
+	alterations := PlanAlterations(current, fields, []string{"ID"}, map[string][]string{"uk_name": {"Name"}}, dbx.DBTypePostgres, transfer)
+
+	expected := []struct {
+		action      AlterAction
+		name        string
+		destructive bool
+	}{
+		{AlterDropUnique, "users_email_key", true},
+		{AlterDropColumn, "legacy", true},
+		{AlterAddColumn, "email", false},
+		{AlterModifyColumn, "name", false},
+		{AlterModifyColumn, "age", true},
+		{AlterAddUnique, "name", false},
+	}
+	if len(alterations) != len(expected) {
+		for _, alteration := range alterations {
+			t.Logf("%s %+v", alteration.Action, alteration)
+		}
+		t.Fatalf("expected %d alterations, got %d", len(expected), len(alterations))
+	}
+	for i, want := range expected {
+		alteration := alterations[i]
+		var name string
+		switch {
+		case alteration.Field != nil:
+			name = alteration.Field.ColumnName
+		case alteration.ColumnName != "":
+			name = alteration.ColumnName
+		case alteration.ConstraintName != "":
+			name = alteration.ConstraintName
+		default:
+			name = alteration.Columns[0]
+		}
+		if alteration.Action != want.action || name != want.name || alteration.Destructive != want.destructive {
+			t.Errorf("alteration %d: expected %s %s destructive=%t, got %s %s destructive=%t",
+				i, want.action, want.name, want.destructive, alteration.Action, name, alteration.Destructive)
+		}
+	}
+	if age := alterations[4]; !age.TypeChanged || !age.NullableChanged {
+		t.Errorf("expected type and nullable of age changed, got %+v", age)
+	}
+}
+
+func TestPlanAlterationsPrimaryKeyChange(t *testing.T) {
+	transfer := describeTransfer{"int64": {Type: INT64}}
+	current := &Table{
+		Name: "orders",
+		Columns: []*Column{
+			{Name: "id", DataType: "int64"},
+			{Name: "user_id", DataType: "int64", Nullable: true},
+		},
+		Constraints: []*Constraint{{Type: ConstraintPrimaryKey, Columns: []string{"id"}}},
+	}
+	fields := []*Field{{ColumnName: "id", Type: INT64}, {ColumnName: "user_id", Type: INT64}}
+	alterations := PlanAlterations(current, fields, []string{"id", "user_id"}, nil, dbx.DBTypePostgres, transfer)
+	if len(alterations) != 3 {
+		t.Fatalf("expected 3 alterations, got %d", len(alterations))
+	}
+	for _, alteration := range alterations {
+		if !alteration.Destructive {
+			t.Errorf("expected %s %+v destructive", alteration.Action, alteration)
+		}
+	}
+
+	current.Constraints = nil
+	alterations = PlanAlterations(current, fields[:1], []string{"id"}, nil, dbx.DBTypePostgres, transfer)
+	if len(alterations) != 2 || alterations[1].Action != AlterAddPrimaryKey || alterations[1].Destructive {
+		t.Errorf("unexpected alterations %+v", alterations)
+	}
+}
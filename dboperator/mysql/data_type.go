@@ -10,9 +10,18 @@ import (
 	"github.com/jasonlabz/dbutil/log"
 )
 
+// TinyIntAsBool 是否将 tinyint(1) 视为布尔类型，mysql以 tinyint(1) 表示 boolean，关闭后按 tinyint 处理
+var TinyIntAsBool = true
+
 func (m MySQLOperator) Trans2CommonField(dataType string) *dboperator.Field {
 	var field dboperator.Field
 	lowerWords := strings.ToLower(dataType)
+	// data_type: int(10) unsigned zerofill，zerofill仅影响显示且隐含unsigned
+	var unsigned bool
+	if !strings.HasPrefix(lowerWords, "enum") && !strings.HasPrefix(lowerWords, "set") {
+		unsigned = strings.Contains(lowerWords, "unsigned") || strings.Contains(lowerWords, "zerofill")
+		lowerWords = strings.Join(strings.Fields(strings.NewReplacer("unsigned", "", "zerofill", "").Replace(lowerWords)), " ")
+	}
 	typeStr := lowerWords
 	var extra []string
 	if strings.Contains(lowerWords, ")") {
@@ -51,6 +60,10 @@ func (m MySQLOperator) Trans2CommonField(dataType string) *dboperator.Field {
 		field.IsText = true
 	case "tinyint", "int1":
 		field = *dboperator.Int8Field
+		if TinyIntAsBool && lowerWords == "tinyint(1)" {
+			field = *dboperator.BoolField
+			extra = nil
+		}
 	case "smallint", "int2":
 		field = *dboperator.Int16Field
 	case "mediumint", "int", "integer", "int3", "int4":
@@ -68,6 +81,19 @@ func (m MySQLOperator) Trans2CommonField(dataType string) *dboperator.Field {
 		field = *dboperator.BoolField
 	case "tinyblob", "blob", "mediumblob", "longblob":
 		field = *dboperator.BytesField
+	case "json":
+		field = *dboperator.JSONField
+	case "enum", "set":
+		field = *dboperator.EnumField
+		if typeStr == "set" {
+			field = *dboperator.SetField
+		}
+		// 可选值区分大小写，从原始类型中解析
+		field.EnumValues = dboperator.ParseEnumValues(dataType)
+		extra = nil
+	case "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection":
+		field = *dboperator.GeometryField
+		field.GeometryType, _ = dboperator.GeometryType(typeStr)
 	default:
 		log.DefaultLogger().Warn("handle with default mysql type:%s", dataType)
 		field = *dboperator.StringField
@@ -82,6 +108,13 @@ func (m MySQLOperator) Trans2CommonField(dataType string) *dboperator.Field {
 		}
 	}
 
+	if unsigned {
+		switch field.Type {
+		case dboperator.INT8, dboperator.INT16, dboperator.INT32, dboperator.INT64:
+			field.IsUnsigned = true
+		}
+	}
+
 	if len(extra) == 2 {
 		val1, err1 := strconv.Atoi(extra[1])
 		val0, err0 := strconv.Atoi(extra[0])
@@ -100,19 +133,22 @@ func (m MySQLOperator) Trans2CommonField(dataType string) *dboperator.Field {
 }
 
 func (m MySQLOperator) Trans2DataType(field *dboperator.Field) string {
+	if field.TargetType != "" {
+		return field.TargetType
+	}
 	switch field.Type {
 	case dboperator.BYTES:
 		fallthrough
 	case dboperator.RUNES:
 		return "longblob"
 	case dboperator.INT8:
-		return utils.IsTrueOrNot(field.Precision <= 0, "tinyint", fmt.Sprintf("tinyint(%d)", field.Precision))
+		return utils.IsTrueOrNot(field.Precision <= 0, "tinyint", fmt.Sprintf("tinyint(%d)", field.Precision)) + getUnsignedSuffix(field)
 	case dboperator.INT16:
-		return utils.IsTrueOrNot(field.Precision <= 0, "smallint", fmt.Sprintf("tinyint(%d)", field.Precision))
+		return utils.IsTrueOrNot(field.Precision <= 0, "smallint", fmt.Sprintf("smallint(%d)", field.Precision)) + getUnsignedSuffix(field)
 	case dboperator.INT32:
-		return utils.IsTrueOrNot(field.Precision <= 0, "int", fmt.Sprintf("int(%d)", field.Precision))
+		return utils.IsTrueOrNot(field.Precision <= 0, "int", fmt.Sprintf("int(%d)", field.Precision)) + getUnsignedSuffix(field)
 	case dboperator.INT64:
-		return utils.IsTrueOrNot(field.Precision <= 0, "bigint", fmt.Sprintf("bigint(%d)", field.Precision))
+		return utils.IsTrueOrNot(field.Precision <= 0, "bigint", fmt.Sprintf("bigint(%d)", field.Precision)) + getUnsignedSuffix(field)
 	case dboperator.FLOAT32:
 		return fmt.Sprintf("float%s", getTypeSuffix(field))
 	case dboperator.FLOAT64:
@@ -144,12 +180,39 @@ func (m MySQLOperator) Trans2DataType(field *dboperator.Field) string {
 			timeType = "datetime"
 		}
 		return utils.IsTrueOrNot(field.Length == 0, timeType, fmt.Sprintf("%s(%d)", timeType, field.Length))
+	case dboperator.JSON, dboperator.ARRAY:
+		// 数组以json数组保存
+		return "json"
+	case dboperator.UUID:
+		return "char(36)"
+	case dboperator.ENUM, dboperator.SET:
+		if len(field.EnumValues) == 0 {
+			return "text"
+		}
+		values := make([]string, 0, len(field.EnumValues))
+		for _, value := range field.EnumValues {
+			values = append(values, utils.QuotaString(value))
+		}
+		return fmt.Sprintf("%s(%s)", field.Type, strings.Join(values, ","))
+	case dboperator.INTERVAL:
+		return "varchar(64)"
+	case dboperator.XML:
+		return "longtext"
+	case dboperator.GEOMETRY:
+		if field.GeometryType == "" || field.GeometryType == "geography" {
+			return "geometry"
+		}
+		return field.GeometryType
 	default:
 		log.DefaultLogger().Warn("handle with to default mysql type:%s", field.Type)
 		return "text"
 	}
 }
 
+func getUnsignedSuffix(field *dboperator.Field) string {
+	return utils.IsTrueOrNot(field.IsUnsigned, " unsigned", "")
+}
+
 func getTypeSuffix(field *dboperator.Field) string {
 	if field.Precision > 0 && field.Scale > 0 {
 		return fmt.Sprintf("(%d,%d)", field.Precision, field.Scale)
@@ -162,3 +225,49 @@ func getTypeSuffix(field *dboperator.Field) string {
 	}
 	return ""
 }
+
+// getColumnOption 生成字段字符集、排序规则、默认值、非空约束、自增属性及注释
+func getColumnOption(field *dboperator.Field) (option string) {
+	if field.Charset != "" {
+		option += " character set " + field.Charset
+	}
+	if field.Collation != "" {
+		option += " collate " + field.Collation
+	}
+	if defaultValue := getDefaultValue(field); defaultValue != "" {
+		option += " default " + defaultValue
+	}
+	if !field.ISNullable {
+		option += " not null"
+	}
+	if field.IsAutoIncrement {
+		option += " auto_increment"
+	}
+	if field.Comment != "" {
+		option += " comment " + utils.QuotaString(field.Comment)
+	}
+	return
+}
+
+// getDefaultValue 将通用默认值翻译为mysql写法，text/blob等类型仅支持表达式形式的默认值
+func getDefaultValue(field *dboperator.Field) string {
+	switch field.DefaultValue {
+	case "":
+		return ""
+	case dboperator.DefaultTrue:
+		return "1"
+	case dboperator.DefaultFalse:
+		return "0"
+	case dboperator.DefaultCurrentDate, dboperator.DefaultCurrentTime:
+		return "(" + field.DefaultValue + ")"
+	case dboperator.DefaultCurrentTimestamp:
+		if field.TimeType == "date" {
+			return "(" + dboperator.DefaultCurrentDate + ")"
+		}
+		return field.DefaultValue
+	}
+	if field.IsText || field.Type == dboperator.BYTES || field.Type == dboperator.RUNES {
+		return "(" + field.DefaultValue + ")"
+	}
+	return field.DefaultValue
+}
@@ -3,6 +3,8 @@ package mysql
 import (
 	"fmt"
 	"testing"
+
+	"github.com/jasonlabz/dbutil/dboperator"
 )
 
 func TestDataType(t *testing.T) {
@@ -13,3 +15,27 @@ func TestDataType(t *testing.T) {
 	trans2DataType := operator.Trans2DataType(field)
 	fmt.Println(trans2DataType)
 }
+
+func TestUnsignedAndTinyIntType(t *testing.T) {
+	operator := NewMySQLOperator()
+	field := operator.Trans2CommonField("int(10) unsigned zerofill")
+	if !field.IsUnsigned || operator.Trans2DataType(field) != "int(10) unsigned" {
+		t.Errorf("int(10) unsigned zerofill => %+v", *field)
+	}
+	if field = operator.Trans2CommonField("tinyint(1)"); field.Type != dboperator.BOOL {
+		t.Errorf("tinyint(1) => %s, expected bool", field.Type)
+	}
+	TinyIntAsBool = false
+	defer func() { TinyIntAsBool = true }()
+	if field = operator.Trans2CommonField("tinyint(1)"); field.Type != dboperator.INT8 {
+		t.Errorf("tinyint(1) => %s, expected int8", field.Type)
+	}
+}
+
+func TestCharsetOption(t *testing.T) {
+	field := NewMySQLOperator().Trans2CommonField("varchar(20)")
+	field.Charset, field.Collation, field.ISNullable = "utf8mb4", "utf8mb4_bin", true
+	if option := getColumnOption(field); option != " character set utf8mb4 collate utf8mb4_bin" {
+		t.Errorf("column option = %q", option)
+	}
+}
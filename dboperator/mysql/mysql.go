@@ -7,6 +7,7 @@ import (
 	"github.com/jasonlabz/dbutil/core/utils"
 	"github.com/jasonlabz/dbutil/dboperator"
 	"github.com/jasonlabz/dbutil/dbx"
+	"github.com/jasonlabz/dbutil/log"
 	"strings"
 )
 
@@ -16,6 +17,9 @@ func NewMySQLOperator() dboperator.IOperator {
 
 type MySQLOperator struct{}
 
+// identifier 标识符规则，见 dboperator.GetIdentifierPolicy
+var identifier = dboperator.GetIdentifierPolicy(dbx.DBTypeMySQL)
+
 var (
 	TableNameAllTables     = "INFORMATION_SCHEMA.TABLES"
 	TableNameAllTablesCols = "INFORMATION_SCHEMA.COLUMNS"
@@ -51,15 +55,19 @@ func (m MySQLOperator) GetDataBySQL(ctx context.Context, dbName, sqlStatement st
 	return
 }
 
+func (m MySQLOperator) GetSchema(ctx context.Context, dbName, schemaName string) (schema *dboperator.Schema, err error) {
+	return dboperator.InspectSchema(ctx, m, dbx.DBTypeMySQL, dbName, schemaName)
+}
+
 func (m MySQLOperator) GetTableData(ctx context.Context, dbName, schemaName, tableName string, pageInfo *dboperator.Pagination) (rows []map[string]interface{}, err error) {
 	rows = make([]map[string]interface{}, 0)
 	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
-	queryTable := fmt.Sprintf("\"%s\"", tableName)
+	queryTable := identifier.Quote(tableName)
 	if schemaName != "" {
-		queryTable = fmt.Sprintf("\"%s\".\"%s\"", schemaName, tableName)
+		queryTable = identifier.Quote(schemaName) + "." + queryTable
 	}
 	var count int64
 	err = db.DB.WithContext(ctx).
@@ -90,8 +98,12 @@ func (m MySQLOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 	err = db.DB.WithContext(ctx).
 		Raw("SELECT TABLE_SCHEMA as table_schema, " +
 			"TABLE_NAME as table_name, " +
-			"TABLE_COMMENT as comments " +
+			"TABLE_COMMENT as comments, " +
+			"IFNULL(TABLE_ROWS, -1) as row_count, " +
+			"IFNULL(ccsa.CHARACTER_SET_NAME, '') as charset, " +
+			"IFNULL(TABLE_COLLATION, '') as collation " +
 			"FROM INFORMATION_SCHEMA.TABLES " +
+			"LEFT JOIN INFORMATION_SCHEMA.COLLATION_CHARACTER_SET_APPLICABILITY ccsa ON ccsa.COLLATION_NAME = TABLE_COLLATION " +
 			"WHERE TABLE_TYPE = 'BASE TABLE' " +
 			"AND TABLE_SCHEMA IN (" + strings.Join(schemas, ",") + ") " +
 			"ORDER  BY TABLE_SCHEMA, TABLE_NAME").
@@ -109,6 +121,9 @@ func (m MySQLOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Charset:   row.Charset,
+					Collation: row.Collation,
 				}},
 			}
 		} else {
@@ -116,6 +131,9 @@ func (m MySQLOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Charset:   row.Charset,
+					Collation: row.Collation,
 				})
 		}
 	}
@@ -136,7 +154,8 @@ func (m MySQLOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbT
 	err = db.DB.WithContext(ctx).
 		Raw("SELECT TABLE_SCHEMA as table_schema, " +
 			"TABLE_NAME as table_name, " +
-			"TABLE_COMMENT as comments " +
+			"TABLE_COMMENT as comments, " +
+			"IFNULL(TABLE_ROWS, -1) as row_count " +
 			"FROM INFORMATION_SCHEMA.TABLES " +
 			"WHERE TABLE_TYPE = 'BASE TABLE' " +
 			"AND TABLE_SCHEMA NOT IN ('mysql', 'sys', 'performance_schema', 'information_schema') " +
@@ -155,6 +174,7 @@ func (m MySQLOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbT
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				}},
 			}
 		} else {
@@ -162,6 +182,7 @@ func (m MySQLOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbT
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				})
 		}
 	}
@@ -185,7 +206,11 @@ func (m MySQLOperator) GetColumns(ctx context.Context, dbName string) (dbTableCo
 			"t.TABLE_NAME table_name, " +
 			"c.COLUMN_NAME column_name, " +
 			"c.COLUMN_COMMENT comments, " +
-			"c.COLUMN_TYPE data_type " +
+			"c.COLUMN_TYPE data_type, " +
+			"if(c.IS_NULLABLE = 'YES', 1, 0) is_nullable, " +
+			"c.COLUMN_DEFAULT column_default, " +
+			"c.EXTRA extra, " +
+			"c.ORDINAL_POSITION ordinal_position " +
 			"from " +
 			"INFORMATION_SCHEMA.TABLES t " +
 			"inner join INFORMATION_SCHEMA.COLUMNS c on " +
@@ -209,9 +234,12 @@ func (m MySQLOperator) GetColumns(ctx context.Context, dbName string) (dbTableCo
 				row.TableName: {
 					TableName: row.TableName,
 					ColumnInfoList: []*dboperator.ColumnInfo{{
-						ColumnName: row.ColumnName,
-						Comment:    row.Comments,
-						DataType:   row.DataType,
+						ColumnName:      row.ColumnName,
+						Comment:         row.Comments,
+						DataType:        row.DataType,
+						IsNullable:      row.IsNullable,
+						DefaultValue:    getColumnDefault(row),
+						OrdinalPosition: row.OrdinalPosition,
 					}},
 				},
 			}
@@ -219,19 +247,31 @@ func (m MySQLOperator) GetColumns(ctx context.Context, dbName string) (dbTableCo
 			dbTableColInfoMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    getColumnDefault(row),
+					OrdinalPosition: row.OrdinalPosition,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    getColumnDefault(row),
+				OrdinalPosition: row.OrdinalPosition,
 			})
 		}
 	}
+	for schemaName, tableColMap := range dbTableColMap {
+		err = m.fillAutoIncrement(ctx, db, schemaName, tableColMap)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -257,7 +297,13 @@ func (m MySQLOperator) GetColumnsUnderTables(ctx context.Context, dbName, logicD
 			"t.TABLE_NAME table_name, "+
 			"c.COLUMN_NAME column_name, "+
 			"c.COLUMN_COMMENT comments, "+
-			"c.COLUMN_TYPE data_type "+
+			"c.COLUMN_TYPE data_type, "+
+			"if(c.IS_NULLABLE = 'YES', 1, 0) is_nullable, "+
+			"c.COLUMN_DEFAULT column_default, "+
+			"c.EXTRA extra, "+
+			"c.ORDINAL_POSITION ordinal_position, "+
+			"IFNULL(c.CHARACTER_SET_NAME, '') charset, "+
+			"IFNULL(c.COLLATION_NAME, '') collation "+
 			"from "+
 			"INFORMATION_SCHEMA.TABLES t "+
 			"inner join INFORMATION_SCHEMA.COLUMNS c on "+
@@ -278,19 +324,103 @@ func (m MySQLOperator) GetColumnsUnderTables(ctx context.Context, dbName, logicD
 			tableColMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    getColumnDefault(row),
+					OrdinalPosition: row.OrdinalPosition,
+					Charset:         row.Charset,
+					Collation:       row.Collation,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    getColumnDefault(row),
+				OrdinalPosition: row.OrdinalPosition,
+				Charset:         row.Charset,
+				Collation:       row.Collation,
 			})
 		}
 	}
+	err = m.fillAutoIncrement(ctx, db, logicDBName, tableColMap)
+	return
+}
+
+// fillAutoIncrement 标记auto_increment列，下一个值取表的AUTO_INCREMENT，
+// mysql8默认缓存INFORMATION_SCHEMA.TABLES统计信息，可能落后于实际值，因此与 max(列)+1 取较大者
+func (m MySQLOperator) fillAutoIncrement(ctx context.Context, db *dbx.DBWrapper, schemaName string, tableColMap map[string]*dboperator.TableColInfo) (err error) {
+	if len(tableColMap) == 0 {
+		return
+	}
+	autoIncrements := make([]*dboperator.TableAutoIncrement, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT c.TABLE_NAME AS table_name, c.COLUMN_NAME AS column_name, COALESCE(t.AUTO_INCREMENT, 1) AS next_value
+FROM INFORMATION_SCHEMA.COLUMNS c
+JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+WHERE c.TABLE_SCHEMA = ? AND c.TABLE_NAME IN ? AND c.EXTRA LIKE '%auto_increment%'`, schemaName, utils.SortedKeys(tableColMap)).
+		Scan(&autoIncrements).Error
+	if err != nil {
+		return
+	}
+	for _, autoIncrement := range autoIncrements {
+		var nextValue int64
+		err = db.DB.WithContext(ctx).Raw(fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) + 1 FROM %s.%s",
+			identifier.Quote(autoIncrement.ColumnName), identifier.Quote(schemaName), identifier.Quote(autoIncrement.TableName))).Scan(&nextValue).Error
+		if err != nil {
+			return
+		}
+		if nextValue > autoIncrement.NextValue {
+			autoIncrement.NextValue = nextValue
+		}
+	}
+	dboperator.FillAutoIncrement(tableColMap, autoIncrements)
+	return
+}
+
+func (m MySQLOperator) GetViewsUnderSchema(ctx context.Context, dbName string, schemas []string) (schemaViewMap map[string][]*dboperator.ViewInfo, err error) {
+	if dbName == "" || len(schemas) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	gormDBViews := make([]*dboperator.GormDBView, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT TABLE_SCHEMA as schema_name, TABLE_NAME as view_name, VIEW_DEFINITION as definition
+FROM INFORMATION_SCHEMA.VIEWS WHERE TABLE_SCHEMA IN ? ORDER BY TABLE_SCHEMA, TABLE_NAME`, schemas).
+		Scan(&gormDBViews).Error
+	if err != nil {
+		return
+	}
+	schemaViewMap = dboperator.GroupViews(gormDBViews)
+	return
+}
+
+// CreateViews mysql不支持物化视图，创建为普通视图
+func (m MySQLOperator) CreateViews(ctx context.Context, dbName, schemaName string, views []*dboperator.ViewInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, m.RenderViews(ctx, schemaName, views))
+}
+
+func (m MySQLOperator) RenderViews(ctx context.Context, schemaName string, views []*dboperator.ViewInfo) (statements []string) {
+	for _, view := range views {
+		if view.IsMaterialized {
+			log.GetLogger(ctx).Warn("mysql does not support materialized view, create %s as view", view.ViewName)
+		}
+		statements = append(statements, fmt.Sprintf("create or replace view %s as %s",
+			identifier.QualifiedName(schemaName, view.ViewName), identifier.QuoteExpression(view.Definition)))
+	}
 	return
 }
 
@@ -378,8 +508,148 @@ WHERE t.constraint_type='UNIQUE' and  TABLE_SCHEMA = '` + schemaName + `' and
 	return
 }
 
+func (m MySQLOperator) GetTableIndexes(ctx context.Context, dbName string, schemaName string, tables []string) (indexInfo map[string][]*dboperator.IndexInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// mysql的唯一索引即唯一约束，已由GetTableUniqueKeys返回
+	tableIndexColumns := make([]*dboperator.TableIndexColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT s.TABLE_SCHEMA as schema_name, s.TABLE_NAME as table_name, s.INDEX_NAME as index_name,
+    0 as is_unique, s.SEQ_IN_INDEX as column_position, ifnull(s.COLUMN_NAME, '') as column_name,
+    ifnull(s.EXPRESSION, '') as expression, if(s.COLLATION = 'D', 1, 0) as is_desc
+FROM INFORMATION_SCHEMA.STATISTICS s
+WHERE s.NON_UNIQUE = 1 AND s.INDEX_TYPE NOT IN ('FULLTEXT', 'SPATIAL')
+    AND s.TABLE_SCHEMA = ? AND s.TABLE_NAME IN ?
+ORDER BY s.TABLE_NAME, s.INDEX_NAME, s.SEQ_IN_INDEX`, schemaName, tables).
+		Scan(&tableIndexColumns).Error
+	if err != nil {
+		return
+	}
+	indexInfo = dboperator.GroupIndexColumns(tableIndexColumns)
+	return
+}
+
+func (m MySQLOperator) CreateIndexes(ctx context.Context, dbName, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// mysql不支持 create index if not exists，跳过已存在的索引
+	existIndexes := make([]*dboperator.TableIndexColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT DISTINCT TABLE_NAME as table_name, INDEX_NAME as index_name
+FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = ?`, identifier.Fold(schemaName)).Scan(&existIndexes).Error
+	if err != nil {
+		return
+	}
+	existMap := make(map[string]bool)
+	for _, existIndex := range existIndexes {
+		existMap[existIndex.TableName+"."+existIndex.IndexName] = true
+	}
+	indexNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.IndexNames(indexesMap))
+	pendingMap := make(map[string][]*dboperator.IndexInfo)
+	for tableName, indexes := range indexesMap {
+		for _, index := range indexes {
+			if !existMap[identifier.Fold(tableName)+"."+indexNames.Get(tableName, index.IndexName)] {
+				pendingMap[tableName] = append(pendingMap[tableName], index)
+			}
+		}
+	}
+	return dboperator.ExecuteStatements(ctx, db, renderIndexes(ctx, schemaName, pendingMap, indexNames))
+}
+
+// RenderIndexes 不检查目标库中已存在的索引
+func (m MySQLOperator) RenderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (statements []string) {
+	return renderIndexes(ctx, schemaName, indexesMap, dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.IndexNames(indexesMap)))
+}
+
+// renderIndexes 以 indexNames 中分配的名称生成创建索引语句
+func renderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*dboperator.IndexInfo, indexNames dboperator.ObjectNames) (statements []string) {
+	for _, tableName := range utils.SortedKeys(indexesMap) {
+		for _, index := range indexesMap[tableName] {
+			if index.Filter != "" {
+				log.GetLogger(ctx).Warn("mysql does not support partial index, skip index %s on %s", index.IndexName, tableName)
+				continue
+			}
+			columns := make([]string, 0, len(index.Columns))
+			for _, column := range index.Columns {
+				columnStr := utils.IsTrueOrNot(column.Expression != "", "("+column.Expression+")", identifier.Name(column.ColumnName))
+				columns = append(columns, columnStr+utils.IsTrueOrNot(column.IsDesc, " desc", ""))
+			}
+			statements = append(statements, fmt.Sprintf("create %sindex %s on %s (%s)",
+				utils.IsTrueOrNot(index.IsUnique, "unique ", ""), identifier.Name(indexNames.Get(tableName, index.IndexName)),
+				identifier.QualifiedName(schemaName, tableName), strings.Join(columns, ",")))
+		}
+	}
+	return
+}
+
+func (m MySQLOperator) GetTableForeignKeys(ctx context.Context, dbName string, schemaName string, tables []string) (foreignKeyInfo map[string][]*dboperator.ForeignKeyInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableForeignKeyColumns := make([]*dboperator.TableForeignKeyColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT k.TABLE_SCHEMA as schema_name, k.TABLE_NAME as table_name, k.CONSTRAINT_NAME as constraint_name,
+       k.COLUMN_NAME as column_name, k.REFERENCED_TABLE_SCHEMA as ref_schema_name, k.REFERENCED_TABLE_NAME as ref_table_name,
+       k.REFERENCED_COLUMN_NAME as ref_column_name, k.ORDINAL_POSITION as column_position,
+       r.DELETE_RULE as on_delete, r.UPDATE_RULE as on_update
+  FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+  JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS r
+    ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME AND r.TABLE_NAME = k.TABLE_NAME
+ WHERE k.TABLE_SCHEMA = ? AND k.TABLE_NAME IN ?
+ ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, schemaName, tables).
+		Scan(&tableForeignKeyColumns).Error
+	if err != nil {
+		return
+	}
+	foreignKeyInfo = dboperator.GroupForeignKeyColumns(tableForeignKeyColumns)
+	return
+}
+
+func (m MySQLOperator) GetTableCheckConstraints(ctx context.Context, dbName string, schemaName string, tables []string) (checkInfo map[string][]*dboperator.CheckInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// CHECK_CONSTRAINTS 自 mysql 8.0.16 起提供，低版本不支持检查约束
+	var checkTableCount int64
+	err = db.DB.WithContext(ctx).Raw(`SELECT COUNT(*) FROM INFORMATION_SCHEMA.TABLES
+WHERE TABLE_SCHEMA = 'information_schema' AND TABLE_NAME = 'CHECK_CONSTRAINTS'`).Scan(&checkTableCount).Error
+	if err != nil || checkTableCount == 0 {
+		return
+	}
+	tableCheckConstraints := make([]*dboperator.TableCheckConstraint, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT tc.TABLE_SCHEMA as schema_name, tc.TABLE_NAME as table_name, tc.CONSTRAINT_NAME as constraint_name,
+       cc.CHECK_CLAUSE as expression
+  FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+  JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS cc
+    ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+ WHERE tc.CONSTRAINT_TYPE = 'CHECK' AND tc.TABLE_SCHEMA = ? AND tc.TABLE_NAME IN ?
+ ORDER BY tc.TABLE_NAME, tc.CONSTRAINT_NAME`, schemaName, tables).
+		Scan(&tableCheckConstraints).Error
+	if err != nil {
+		return
+	}
+	checkInfo = dboperator.GroupCheckConstraints(tableCheckConstraints)
+	return
+}
+
 func (m MySQLOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string,
-	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field) (ddlSQL string, err error) {
+	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (ddlSQL string, err error) {
 	if dbName == "" {
 		err = errors.New("empty dnName")
 		return
@@ -388,25 +658,60 @@ func (m MySQLOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string
 	if err != nil {
 		return
 	}
+	ddlSQL, err = dboperator.ExecuteStatements(ctx, db, m.renderTables(ctx, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap))
+	if err != nil {
+		return
+	}
+	if len(foreignKeysMap) == 0 {
+		return
+	}
 
-	//ddlSQL := ""
+	// mysql外键名在库内唯一且不支持 if not exists，跳过同一表上已存在的外键
+	existForeignKeys := make([]*dboperator.TableForeignKeyColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT TABLE_NAME AS table_name, CONSTRAINT_NAME AS constraint_name FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS
+WHERE TABLE_SCHEMA = ? AND CONSTRAINT_TYPE = 'FOREIGN KEY'`, identifier.Fold(schemaName)).Scan(&existForeignKeys).Error
+	if err != nil {
+		return
+	}
+	existNames := make(map[string]string)
+	for _, existForeignKey := range existForeignKeys {
+		existNames[existForeignKey.ConstraintName] = existForeignKey.TableName
+	}
+	foreignKeySQL, err := dboperator.ExecuteStatements(ctx, db, renderForeignKeys(schemaName, tableFieldsMap, foreignKeysMap, existNames))
+	ddlSQL += foreignKeySQL
+	return
+}
+
+// RenderDDL 外键名仅在本次生成的外键间去重，不检查目标库中已存在的外键
+func (m MySQLOperator) RenderDDL(ctx context.Context, schemaName string, primaryKeysMap map[string][]string,
+	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (statements []string) {
+	statements = m.renderTables(ctx, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap)
+	return append(statements, renderForeignKeys(schemaName, tableFieldsMap, foreignKeysMap, make(map[string]string))...)
+}
+
+// renderTables 生成建表语句，表按外键依赖顺序排列
+func (m MySQLOperator) renderTables(ctx context.Context, schemaName string, primaryKeysMap map[string][]string,
+	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (statements []string) {
 	ddlTemplate := `
 create table if not exists %s (
 	%s
-);`
-	for tableName, fields := range tableFieldsMap {
+)%s;`
+	uniqueNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.UniqueKeyNames(uniqueKeysMap))
+	checkNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.CheckNames(checksMap))
+	for _, tableName := range dboperator.TableCreationOrder(ctx, tableFieldsMap, foreignKeysMap) {
+		fields := tableFieldsMap[tableName]
 		var includeField string
 		for _, field := range fields {
 			if field == nil {
 				continue
 			}
 			dataType := m.Trans2DataType(field)
-			includeField += fmt.Sprintf("	%s %s,", utils.QuotaName(field.ColumnName), dataType) + fmt.Sprintln()
+			includeField += fmt.Sprintf("	%s %s%s,", identifier.Name(field.ColumnName), dataType, getColumnOption(field)) + fmt.Sprintln()
 		}
 		if len(primaryKeysMap) > 0 {
-			keys := primaryKeysMap[tableName]
-			for i, key := range keys {
-				keys[i] = utils.QuotaName(key)
+			keys := make([]string, 0, len(primaryKeysMap[tableName]))
+			for _, key := range primaryKeysMap[tableName] {
+				keys = append(keys, identifier.Name(key))
 			}
 			if len(keys) > 0 {
 				includeField += fmt.Sprintf("	primary key (%s),", strings.Join(keys, ",")) + fmt.Sprintln()
@@ -414,24 +719,150 @@ create table if not exists %s (
 		}
 		if len(uniqueKeysMap) > 0 {
 			uniqueKeys := uniqueKeysMap[tableName]
-			for _, columns := range uniqueKeys {
-				for i, column := range columns {
-					columns[i] = utils.QuotaName(column)
+			for _, uniqueName := range utils.SortedKeys(uniqueKeys) {
+				uniqueColumns := uniqueKeys[uniqueName]
+				columns := make([]string, 0, len(uniqueColumns))
+				for _, column := range uniqueColumns {
+					columns = append(columns, identifier.Name(column))
+				}
+				if uniqueName == "" {
+					includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+					continue
 				}
-				includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+				includeField += fmt.Sprintf("	constraint %s unique (%s),", identifier.Name(uniqueNames.Get(tableName, uniqueName)), strings.Join(columns, ",")) + fmt.Sprintln()
 			}
 		}
 
+		for _, check := range checksMap[tableName] {
+			includeField += fmt.Sprintf("	constraint %s check (%s),", identifier.Name(checkNames.Get(tableName, check.ConstraintName)), identifier.QuoteExpression(check.Expression)) + fmt.Sprintln()
+		}
+
 		includeField = strings.TrimSpace(includeField)
 		includeField = strings.Trim(includeField, ",")
 
-		ddlStr := fmt.Sprintf(ddlTemplate, fmt.Sprintf("%s.%s", utils.QuotaName(schemaName), utils.QuotaName(tableName)), includeField)
-		ddlSQL += ddlStr + fmt.Sprintln()
+		var tableOption string
+		for _, field := range fields {
+			if field != nil && field.IsAutoIncrement && field.AutoIncrementStart > 1 {
+				tableOption += fmt.Sprintf(" auto_increment = %d", field.AutoIncrementStart)
+			}
+		}
+		if tableComment := tableCommentMap[tableName]; tableComment != "" {
+			tableOption += " comment = " + utils.QuotaString(tableComment)
+		}
+		statements = append(statements, fmt.Sprintf(ddlTemplate, identifier.QualifiedName(schemaName, tableName), includeField, tableOption))
 	}
+	return
+}
 
-	err = db.DB.WithContext(ctx).Exec(ddlSQL).Error
+// renderForeignKeys 生成全部表创建后添加外键的语句，existNames 为目标库中已存在的外键名及所属表，
+// 同一表上已存在的外键跳过，与其他表或本次生成的外键重名时以表名作前缀，见 dboperator.AssignObjectNames
+func renderForeignKeys(schemaName string, tableFieldsMap map[string][]*dboperator.Field,
+	foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, existNames map[string]string) (statements []string) {
+	existOnTable := func(tableName string, foreignKey *dboperator.ForeignKeyInfo) bool {
+		existTable, ok := existNames[identifier.Fold(foreignKey.ConstraintName)]
+		return ok && existTable == identifier.Fold(tableName)
+	}
+	usedNames := make(map[string]bool)
+	for existName := range existNames {
+		usedNames[existName] = true
+	}
+	pendingMap := make(map[string][]*dboperator.ForeignKeyInfo)
+	for tableName, foreignKeys := range foreignKeysMap {
+		for _, foreignKey := range foreignKeys {
+			if !existOnTable(tableName, foreignKey) {
+				pendingMap[tableName] = append(pendingMap[tableName], foreignKey)
+			}
+		}
+	}
+	foreignKeyNames := dboperator.AssignObjectNames(identifier, usedNames, dboperator.ForeignKeyNames(pendingMap))
+	for _, tableName := range utils.SortedKeys(pendingMap) {
+		if _, ok := tableFieldsMap[tableName]; !ok {
+			continue
+		}
+		for _, foreignKey := range pendingMap[tableName] {
+			constraintName := foreignKeyNames.Get(tableName, foreignKey.ConstraintName)
+			columns := make([]string, 0, len(foreignKey.Columns))
+			for _, column := range foreignKey.Columns {
+				columns = append(columns, identifier.Name(column))
+			}
+			refColumns := make([]string, 0, len(foreignKey.RefColumns))
+			for _, column := range foreignKey.RefColumns {
+				refColumns = append(refColumns, identifier.Name(column))
+			}
+			foreignKeyStr := fmt.Sprintf("alter table %s add constraint %s foreign key (%s) references %s (%s)",
+				identifier.QualifiedName(schemaName, tableName), identifier.Name(constraintName), strings.Join(columns, ","),
+				identifier.QualifiedName(schemaName, foreignKey.RefTableName), strings.Join(refColumns, ","))
+			foreignKeyStr += getReferentialAction("delete", foreignKey.OnDelete, foreignKey.ConstraintName)
+			foreignKeyStr += getReferentialAction("update", foreignKey.OnUpdate, foreignKey.ConstraintName)
+			statements = append(statements, foreignKeyStr)
+		}
+	}
+	return
+}
+
+func (m MySQLOperator) ExecuteAlterations(ctx context.Context, dbName, schemaName string, alterations []*dboperator.TableAlteration) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, m.RenderAlterations(ctx, schemaName, alterations))
+}
+
+// RenderAlterations modify column 需完整定义字段，默认值、注释等随之重建
+func (m MySQLOperator) RenderAlterations(ctx context.Context, schemaName string, alterations []*dboperator.TableAlteration) (statements []string) {
+	for _, alteration := range alterations {
+		var action string
+		switch alteration.Action {
+		case dboperator.AlterAddColumn:
+			action = fmt.Sprintf("add column %s %s%s", identifier.Quote(alteration.Field.ColumnName), m.Trans2DataType(alteration.Field), getColumnOption(alteration.Field))
+		case dboperator.AlterDropColumn:
+			action = "drop column " + identifier.Quote(alteration.ColumnName)
+		case dboperator.AlterModifyColumn:
+			action = fmt.Sprintf("modify column %s %s%s", identifier.Quote(alteration.Field.ColumnName), m.Trans2DataType(alteration.Field), getColumnOption(alteration.Field))
+		case dboperator.AlterAddPrimaryKey:
+			action = fmt.Sprintf("add primary key (%s)", identifier.QuoteList(alteration.Columns))
+		case dboperator.AlterDropPrimaryKey:
+			action = "drop primary key"
+		case dboperator.AlterAddUnique:
+			action = fmt.Sprintf("add unique (%s)", identifier.QuoteList(alteration.Columns))
+		case dboperator.AlterDropUnique:
+			action = "drop index " + identifier.Quote(alteration.ConstraintName)
+		}
+		statements = append(statements, fmt.Sprintf("alter table %s %s", identifier.QualifiedQuote(schemaName, alteration.TableName), action))
+	}
 	return
 }
+
+// getReferentialAction innodb不支持 set default，忽略并告警
+func getReferentialAction(event, action, constraintName string) string {
+	switch action {
+	case "":
+		return ""
+	case dboperator.ActionSetDefault:
+		log.DefaultLogger().Warn("mysql does not support on %s set default, ignore it on foreign key %s", event, constraintName)
+		return ""
+	}
+	return fmt.Sprintf(" on %s %s", event, strings.ToLower(action))
+}
+
+// getColumnDefault mysql的information_schema中字符串默认值不带引号，此处补齐以便与其他数据库保持一致
+func getColumnDefault(row *dboperator.GormTableColumn) string {
+	columnDefault := row.ColumnDefault
+	if columnDefault == "" || strings.EqualFold(columnDefault, "null") {
+		return columnDefault
+	}
+	if strings.Contains(strings.ToUpper(row.Extra), "DEFAULT_GENERATED") ||
+		strings.HasPrefix(columnDefault, "'") ||
+		strings.HasPrefix(strings.ToUpper(columnDefault), "CURRENT_TIMESTAMP") {
+		return columnDefault
+	}
+	switch field := (MySQLOperator{}).Trans2CommonField(row.DataType); field.Type {
+	case dboperator.STRING, dboperator.TIME, dboperator.BYTES:
+		return utils.QuotaString(columnDefault)
+	}
+	return columnDefault
+}
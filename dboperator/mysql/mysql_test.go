@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dboperator"
+)
+
+func testFields(operator dboperator.IOperator, columnNames ...string) []*dboperator.Field {
+	fields := make([]*dboperator.Field, 0, len(columnNames))
+	for _, columnName := range columnNames {
+		field := operator.Trans2CommonField("int")
+		field.ColumnName = columnName
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func TestRenderDDLUniqueKeyOrder(t *testing.T) {
+	operator := NewMySQLOperator()
+	fields := map[string][]*dboperator.Field{"t": testFields(operator, "a", "b", "c", "d")}
+	uniqueKeys := map[string]map[string][]string{"t": {"uk_d": {"d"}, "uk_b": {"b"}, "uk_c": {"c"}, "uk_a": {"a"}}}
+	expected := "constraint uk_a unique (a),\n\tconstraint uk_b unique (b),\n\tconstraint uk_c unique (c),\n\tconstraint uk_d unique (d)"
+	for i := 0; i < 10; i++ {
+		statements := operator.RenderDDL(context.Background(), "app", nil, uniqueKeys, fields, nil, nil, nil)
+		if len(statements) != 1 || !strings.Contains(statements[0], expected) {
+			t.Fatalf("unexpected unique keys in %v", statements)
+		}
+	}
+}
+
+func TestRenderForeignKeysDuplicateName(t *testing.T) {
+	operator := NewMySQLOperator()
+	fields := map[string][]*dboperator.Field{
+		"users":    testFields(operator, "id"),
+		"a_orders": testFields(operator, "user_id"),
+		"b_orders": testFields(operator, "user_id"),
+		"c_orders": testFields(operator, "user_id"),
+	}
+	foreignKeys := make(map[string][]*dboperator.ForeignKeyInfo)
+	for _, tableName := range []string{"a_orders", "b_orders", "c_orders"} {
+		foreignKeys[tableName] = []*dboperator.ForeignKeyInfo{{ConstraintName: "fk_user", Columns: []string{"user_id"},
+			RefTableName: "users", RefColumns: []string{"id"}}}
+	}
+	statements := renderForeignKeys("", fields, foreignKeys, map[string]string{"fk_user": "c_orders"})
+	expected := []string{
+		"alter table a_orders add constraint a_orders_fk_user foreign key (user_id) references users (id)",
+		"alter table b_orders add constraint b_orders_fk_user foreign key (user_id) references users (id)",
+	}
+	if strings.Join(statements, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected statements:\n%s", strings.Join(statements, "\n"))
+	}
+
+	statements = renderForeignKeys("app", fields, foreignKeys, make(map[string]string))
+	if len(statements) != 3 || !strings.HasPrefix(statements[0], "alter table app.a_orders add constraint fk_user ") ||
+		!strings.Contains(statements[2], " constraint c_orders_fk_user ") || !strings.Contains(statements[2], "references app.users") {
+		t.Errorf("unexpected statements:\n%s", strings.Join(statements, "\n"))
+	}
+}
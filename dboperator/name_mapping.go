@@ -0,0 +1,76 @@
+package dboperator
+
+import (
+	"os"
+
+	"github.com/bytedance/sonic"
+)
+
+// NameMapping 源库名称与目标库新建对象名称的对应关系，仅记录发生变化(重命名、大小写折叠、超长缩短)的名称，
+// 供数据迁移及应用程序按目标库名称访问
+type NameMapping struct {
+	Tables      map[string]string            `json:"tables"`      // 源表名 -> 目标表名
+	Columns     map[string]map[string]string `json:"columns"`     // 源表名 -> 源列名 -> 目标列名
+	Constraints map[string]map[string]string `json:"constraints"` // 源表名 -> 源约束名或索引名 -> 目标名称
+}
+
+// NewNameMapping 创建名称对应关系
+func NewNameMapping() *NameMapping {
+	return &NameMapping{
+		Tables:      make(map[string]string),
+		Columns:     make(map[string]map[string]string),
+		Constraints: make(map[string]map[string]string),
+	}
+}
+
+// AddTable 记录表名，名称未变化时忽略
+func (m *NameMapping) AddTable(tableName, targetName string) {
+	if tableName != targetName {
+		m.Tables[tableName] = targetName
+	}
+}
+
+// AddColumn 记录 tableName 表的列名，名称未变化时忽略
+func (m *NameMapping) AddColumn(tableName, columnName, targetName string) {
+	addTableScoped(m.Columns, tableName, columnName, targetName)
+}
+
+// AddConstraint 记录 tableName 表的约束名或索引名，名称未变化时忽略
+func (m *NameMapping) AddConstraint(tableName, constraintName, targetName string) {
+	addTableScoped(m.Constraints, tableName, constraintName, targetName)
+}
+
+// Table 源表名对应的目标表名
+func (m *NameMapping) Table(tableName string) string {
+	if targetName, ok := m.Tables[tableName]; ok {
+		return targetName
+	}
+	return tableName
+}
+
+// Column 源表 tableName 中源列名对应的目标列名
+func (m *NameMapping) Column(tableName, columnName string) string {
+	if targetName, ok := m.Columns[tableName][columnName]; ok {
+		return targetName
+	}
+	return columnName
+}
+
+// WriteJSON 将对应关系以json格式写入文件
+func (m *NameMapping) WriteJSON(filePath string) (err error) {
+	content, err := sonic.ConfigStd.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return
+	}
+	return os.WriteFile(filePath, content, 0644)
+}
+
+func addTableScoped(mapping map[string]map[string]string, tableName, name, targetName string) {
+	if name == targetName {
+		return
+	}
+	if mapping[tableName] == nil {
+		mapping[tableName] = make(map[string]string)
+	}
+	mapping[tableName][name] = targetName
+}
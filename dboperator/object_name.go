@@ -0,0 +1,85 @@
+package dboperator
+
+import (
+	"github.com/jasonlabz/dbutil/core/utils"
+)
+
+// ObjectNames 约束或索引在目标库中的名称，表名 -> 源名称 -> 目标名称，见 AssignObjectNames
+type ObjectNames map[string]map[string]string
+
+// Get 表 tableName 中源名称为 name 的约束或索引在目标库中的名称，未分配时原样返回
+func (n ObjectNames) Get(tableName, name string) string {
+	if targetName, ok := n[tableName][name]; ok {
+		return targetName
+	}
+	return name
+}
+
+// AssignObjectNames 按表名顺序为 namesMap 中各表的约束或索引分配目标库中的名称，表内按给定顺序，空名称跳过。
+// 名称按 identifier 折叠，与 usedNames 中已有或先分配的名称重复时以表名作前缀(约束名、索引名在模式内唯一的数据库中
+// 不同表的同名约束、索引无法同时创建)；生成DDL及名称对应关系均以此分配，二者保持一致
+func AssignObjectNames(identifier *IdentifierPolicy, usedNames map[string]bool, namesMap map[string][]string) ObjectNames {
+	objectNames := make(ObjectNames)
+	for _, tableName := range utils.SortedKeys(namesMap) {
+		for _, name := range namesMap[tableName] {
+			if name == "" {
+				continue
+			}
+			if _, ok := objectNames[tableName][name]; ok {
+				continue
+			}
+			targetName := identifier.Fold(name)
+			if usedNames[targetName] {
+				targetName = identifier.Fold(identifier.Fold(tableName) + "_" + targetName)
+			}
+			usedNames[targetName] = true
+			if objectNames[tableName] == nil {
+				objectNames[tableName] = make(map[string]string)
+			}
+			objectNames[tableName][name] = targetName
+		}
+	}
+	return objectNames
+}
+
+// UniqueKeyNames 各表唯一键名，按名称排序
+func UniqueKeyNames(uniqueKeysMap map[string]map[string][]string) map[string][]string {
+	namesMap := make(map[string][]string)
+	for tableName, uniqueKeys := range uniqueKeysMap {
+		namesMap[tableName] = utils.SortedKeys(uniqueKeys)
+	}
+	return namesMap
+}
+
+// CheckNames 各表检查约束名
+func CheckNames(checksMap map[string][]*CheckInfo) map[string][]string {
+	namesMap := make(map[string][]string)
+	for tableName, checks := range checksMap {
+		for _, check := range checks {
+			namesMap[tableName] = append(namesMap[tableName], check.ConstraintName)
+		}
+	}
+	return namesMap
+}
+
+// ForeignKeyNames 各表外键名
+func ForeignKeyNames(foreignKeysMap map[string][]*ForeignKeyInfo) map[string][]string {
+	namesMap := make(map[string][]string)
+	for tableName, foreignKeys := range foreignKeysMap {
+		for _, foreignKey := range foreignKeys {
+			namesMap[tableName] = append(namesMap[tableName], foreignKey.ConstraintName)
+		}
+	}
+	return namesMap
+}
+
+// IndexNames 各表索引名
+func IndexNames(indexesMap map[string][]*IndexInfo) map[string][]string {
+	namesMap := make(map[string][]string)
+	for tableName, indexes := range indexesMap {
+		for _, index := range indexes {
+			namesMap[tableName] = append(namesMap[tableName], index.IndexName)
+		}
+	}
+	return namesMap
+}
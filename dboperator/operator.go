@@ -31,17 +31,52 @@ type IDataExplorer interface {
 	GetTablePrimeKeys(ctx context.Context, dbName string, schemaName string, tables []string) (primeKeyInfo map[string][]string, err error)
 	// GetTableUniqueKeys 查询唯一键
 	GetTableUniqueKeys(ctx context.Context, dbName string, schemaName string, tables []string) (uniqueKeyInfo map[string]map[string][]string, err error)
-	// ExecuteDDL 执行DDL
-	ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*Field) (ddlSQL string, err error)
+	// GetTableIndexes 查询普通索引(不含主键及唯一约束)
+	GetTableIndexes(ctx context.Context, dbName string, schemaName string, tables []string) (indexInfo map[string][]*IndexInfo, err error)
+	// CreateIndexes 创建索引，不支持的部分索引/函数索引将跳过并告警
+	CreateIndexes(ctx context.Context, dbName, schemaName string, indexesMap map[string][]*IndexInfo) (ddlSQL string, err error)
+	// GetTableForeignKeys 查询外键
+	GetTableForeignKeys(ctx context.Context, dbName string, schemaName string, tables []string) (foreignKeyInfo map[string][]*ForeignKeyInfo, err error)
+	// GetTableCheckConstraints 查询检查约束，表达式为源库写法
+	GetTableCheckConstraints(ctx context.Context, dbName string, schemaName string, tables []string) (checkInfo map[string][]*CheckInfo, err error)
+	// ExecuteDDL 执行DDL, tableCommentMap为表注释, foreignKeysMap为外键，表按外键依赖顺序创建，外键在全部表创建后追加,
+	// checksMap为检查约束，表达式需已归一化，见 NormalizeCheckExpression
+	ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*ForeignKeyInfo, checksMap map[string][]*CheckInfo) (ddlSQL string, err error)
+	// ExecuteAlterations 按修改项修改已存在的表，修改项由 PlanAlterations 生成，数据库不支持的修改跳过并告警
+	ExecuteAlterations(ctx context.Context, dbName, schemaName string, alterations []*TableAlteration) (ddlSQL string, err error)
 	// GetDataBySQL 执行自定义
 	GetDataBySQL(ctx context.Context, dbName, sqlStatement string) (rows []map[string]interface{}, err error)
 	// GetTableData 执行查询表数据, pageInfo为nil时不分页
 	GetTableData(ctx context.Context, dbName, schemaName, tableName string, pageInfo *Pagination) (rows []map[string]interface{}, err error)
+	// GetSchema 获取模式快照，包含模式下全部表的列、约束、索引及视图，见 InspectSchema
+	GetSchema(ctx context.Context, dbName, schemaName string) (schema *Schema, err error)
+}
+
+// IViewExplorer 视图探查
+type IViewExplorer interface {
+	// GetViewsUnderSchema 获取模式下视图及物化视图，定义为源库写法
+	GetViewsUnderSchema(ctx context.Context, dbName string, schemas []string) (schemaViewMap map[string][]*ViewInfo, err error)
+	// CreateViews 按顺序创建视图，定义需已归一化，见 NormalizeViewDefinition；不支持物化视图的数据库创建为普通视图并告警
+	CreateViews(ctx context.Context, dbName, schemaName string, views []*ViewInfo) (ddlSQL string, err error)
+}
+
+// IDDLRenderer 生成DDL语句而不连接数据库，ExecuteDDL 等方法执行的即为这些语句，可用于预览或离线生成脚本，见 Script
+type IDDLRenderer interface {
+	// RenderDDL 生成建表、注释及外键语句，参数同 ExecuteDDL
+	RenderDDL(ctx context.Context, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*ForeignKeyInfo, checksMap map[string][]*CheckInfo) (statements []string)
+	// RenderIndexes 生成创建索引语句，不支持的部分索引/函数索引将跳过并告警
+	RenderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*IndexInfo) (statements []string)
+	// RenderAlterations 生成修改已存在表的语句，数据库不支持的修改跳过并告警
+	RenderAlterations(ctx context.Context, schemaName string, alterations []*TableAlteration) (statements []string)
+	// RenderViews 生成创建视图语句，参数同 CreateViews，不支持物化视图时按普通视图创建并告警
+	RenderViews(ctx context.Context, schemaName string, views []*ViewInfo) (statements []string)
 }
 
 type IOperator interface {
 	IConnector
 	IDataExplorer
+	IViewExplorer
+	IDDLRenderer
 	ITransfer
 }
 
@@ -49,6 +84,9 @@ type GormDBTable struct {
 	TableSchema string `db:"table_schema" gorm:"table_schema"`
 	TableName   string `db:"table_name" gorm:"table_name"`
 	Comments    string `db:"comments" gorm:"comments"`
+	RowCount    int64  `db:"row_count" gorm:"row_count"` // 估算行数
+	Charset     string `db:"charset" gorm:"charset"`     // 默认字符集
+	Collation   string `db:"collation" gorm:"collation"` // 默认排序规则
 }
 
 type TablePrimeKey struct {
@@ -58,22 +96,104 @@ type TablePrimeKey struct {
 	ConstraintName string `db:"constraint_name" gorm:"constraint_name"`
 }
 
-type GormTableColumn struct {
-	TableSchema string `db:"table_schema" gorm:"table_schema"`
+type TableIndexColumn struct {
+	SchemaName     string `db:"schema_name" gorm:"schema_name"`
+	TableName      string `db:"table_name" gorm:"table_name"`
+	IndexName      string `db:"index_name" gorm:"index_name"`
+	IsUnique       bool   `db:"is_unique" gorm:"is_unique"`
+	ColumnName     string `db:"column_name" gorm:"column_name"`
+	Expression     string `db:"expression" gorm:"expression"`           // 函数索引表达式
+	IsDesc         bool   `db:"is_desc" gorm:"is_desc"`                 // 是否降序
+	ColumnPosition int    `db:"column_position" gorm:"column_position"` // 列在索引中的序号
+	Filter         string `db:"filter" gorm:"filter"`                   // 部分索引过滤条件
+}
+
+type TableForeignKeyColumn struct {
+	SchemaName     string `db:"schema_name" gorm:"schema_name"`
+	TableName      string `db:"table_name" gorm:"table_name"`
+	ConstraintName string `db:"constraint_name" gorm:"constraint_name"`
+	ColumnName     string `db:"column_name" gorm:"column_name"`
+	RefSchemaName  string `db:"ref_schema_name" gorm:"ref_schema_name"` // 被引用表所在模式
+	RefTableName   string `db:"ref_table_name" gorm:"ref_table_name"`   // 被引用表
+	RefColumnName  string `db:"ref_column_name" gorm:"ref_column_name"` // 被引用列
+	OnDelete       string `db:"on_delete" gorm:"on_delete"`             // 删除时动作
+	OnUpdate       string `db:"on_update" gorm:"on_update"`             // 更新时动作
+	ColumnPosition int    `db:"column_position" gorm:"column_position"` // 列在外键中的序号
+}
+
+type GormDBView struct {
+	SchemaName     string `db:"schema_name" gorm:"schema_name"`
+	ViewName       string `db:"view_name" gorm:"view_name"`
+	Definition     string `db:"definition" gorm:"definition"`           // 视图查询语句
+	IsMaterialized bool   `db:"is_materialized" gorm:"is_materialized"` // 是否物化视图
+}
+
+type TableCheckConstraint struct {
+	SchemaName     string `db:"schema_name" gorm:"schema_name"`
+	TableName      string `db:"table_name" gorm:"table_name"`
+	ConstraintName string `db:"constraint_name" gorm:"constraint_name"`
+	Expression     string `db:"expression" gorm:"expression"` // 检查条件
+}
+
+type TableAutoIncrement struct {
+	TableName  string `db:"table_name" gorm:"table_name"`
+	ColumnName string `db:"column_name" gorm:"column_name"`
+	NextValue  int64  `db:"next_value" gorm:"next_value"` // 自增列下一个值
+}
+
+// TableTrigger 表上的 before insert 行级触发器，用于识别以序列填充的自增列
+type TableTrigger struct {
 	TableName   string `db:"table_name" gorm:"table_name"`
-	ColumnName  string `db:"column_name" gorm:"column_name"`
-	Comments    string `db:"comments" gorm:"comments"`
-	DataType    string `db:"data_type" gorm:"data_type"`
-	//IsNullable      bool   `db:"is_nullable" gorm:"is_nullable"`           // 可否为null
-	OrdinalPosition int `db:"ordinal_position" gorm:"ordinal_position"` // 字段序号
+	TriggerName string `db:"trigger_name" gorm:"trigger_name"`
+	TriggerBody string `db:"trigger_body" gorm:"trigger_body"`
+}
+
+type GormTableColumn struct {
+	TableSchema     string `db:"table_schema" gorm:"table_schema"`
+	TableName       string `db:"table_name" gorm:"table_name"`
+	ColumnName      string `db:"column_name" gorm:"column_name"`
+	Comments        string `db:"comments" gorm:"comments"`
+	DataType        string `db:"data_type" gorm:"data_type"`
+	IsNullable      bool   `db:"is_nullable" gorm:"is_nullable"`           // 可否为null
+	ColumnDefault   string `db:"column_default" gorm:"column_default"`     // 默认值
+	Extra           string `db:"extra" gorm:"extra"`                       // 扩展信息(mysql)
+	OrdinalPosition int    `db:"ordinal_position" gorm:"ordinal_position"` // 字段序号
+	Charset         string `db:"charset" gorm:"charset"`                   // 字符集
+	Collation       string `db:"collation" gorm:"collation"`               // 排序规则
 }
 
 type SQLiteTableColumn struct {
 	ColumnName      string `db:"name" gorm:"name"`
 	DataType        string `db:"type" gorm:"type"`
-	IsNullable      int8   `db:"notnull" gorm:"notnull"` // 可否为null
-	PrimaryKey      int8   `db:"pk" gorm:"pk"`           // 是否为主键
-	OrdinalPosition int    `db:"cid" gorm:"cid"`         // 字段序号
+	NotNull         int8   `db:"notnull" gorm:"notnull"`       // 是否非空
+	DefaultValue    string `db:"dflt_value" gorm:"dflt_value"` // 默认值
+	PrimaryKey      int8   `db:"pk" gorm:"pk"`                 // 是否为主键
+	OrdinalPosition int    `db:"cid" gorm:"cid"`               // 字段序号
+}
+
+type SQLiteForeignKey struct {
+	ID         int    `db:"id" gorm:"id"`   // 外键序号
+	Seq        int    `db:"seq" gorm:"seq"` // 列在外键中的序号
+	RefTable   string `db:"table" gorm:"table"`
+	ColumnName string `db:"from" gorm:"from"`
+	RefColumn  string `db:"to" gorm:"to"`
+	OnUpdate   string `db:"on_update" gorm:"on_update"`
+	OnDelete   string `db:"on_delete" gorm:"on_delete"`
+}
+
+type SQLiteIndex struct {
+	IndexName string `db:"name" gorm:"name"`
+	IsUnique  int8   `db:"unique" gorm:"unique"`   // 是否唯一
+	Origin    string `db:"origin" gorm:"origin"`   // 来源 c:create index u:唯一约束 pk:主键
+	IsPartial int8   `db:"partial" gorm:"partial"` // 是否部分索引
+}
+
+type SQLiteIndexColumn struct {
+	SeqNo      int    `db:"seqno" gorm:"seqno"` // 列在索引中的序号
+	Cid        int    `db:"cid" gorm:"cid"`     // 列序号，-2为表达式
+	ColumnName string `db:"name" gorm:"name"`
+	IsDesc     int8   `db:"desc" gorm:"desc"` // 是否降序
+	IsKey      int8   `db:"key" gorm:"key"`   // 是否为键列
 }
 
 type LogicDBInfo struct {
@@ -84,6 +204,9 @@ type LogicDBInfo struct {
 type TableInfo struct {
 	TableName string // 列名
 	Comment   string // 注释
+	RowCount  int64  // 估算行数，取自数据库统计信息，-1 表示未知
+	Charset   string // 默认字符集，数据库级别的字符集时为数据库的字符集
+	Collation string // 默认排序规则，为空时为数据库的默认排序规则
 }
 
 type TableColInfo struct {
@@ -96,7 +219,46 @@ type ColumnInfo struct {
 	Comment         string // 注释
 	DataType        string // 数据类型
 	IsNullable      bool   // 可否为null
+	DefaultValue    string // 默认值
 	OrdinalPosition int    // 字段序号
+	IsAutoIncrement bool   // 是否自增列(auto_increment/identity/序列)
+	NextValue       int64  // 自增列下一个值
+	Charset         string // 字符集，为空时同表的默认字符集
+	Collation       string // 排序规则，为空时同表的默认排序规则
+}
+
+type IndexInfo struct {
+	IndexName string         // 索引名
+	IsUnique  bool           // 是否唯一
+	Columns   []*IndexColumn // 索引列，按索引中的顺序排列
+	Filter    string         // 部分索引过滤条件，源库写法
+}
+
+type IndexColumn struct {
+	ColumnName string `json:"column_name,omitempty" yaml:"column_name,omitempty"` // 列名，函数索引时为空
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`   // 函数索引表达式，源库写法
+	IsDesc     bool   `json:"is_desc,omitempty" yaml:"is_desc,omitempty"`         // 是否降序
+}
+
+type ForeignKeyInfo struct {
+	ConstraintName string   // 约束名
+	Columns        []string // 外键列
+	RefSchemaName  string   // 被引用表所在模式
+	RefTableName   string   // 被引用表
+	RefColumns     []string // 被引用列，与外键列一一对应
+	OnDelete       string   // 删除时动作，已归一化，见 NormalizeReferentialAction
+	OnUpdate       string   // 更新时动作，已归一化
+}
+
+type ViewInfo struct {
+	ViewName       string // 视图名
+	Definition     string // 视图查询语句
+	IsMaterialized bool   // 是否物化视图
+}
+
+type CheckInfo struct {
+	ConstraintName string // 约束名
+	Expression     string // 检查条件，不含外层 check()
 }
 
 // Pagination 分页结构体（该分页只适合数据量很少的情况）
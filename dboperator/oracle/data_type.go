@@ -22,7 +22,9 @@ func (o OracleOperator) Trans2CommonField(dataType string) *dboperator.Field {
 			extraStr := lowerWords[lIndex+1 : rIndex]
 			extra = strings.Split(extraStr, ",")
 			for i, s := range extra {
-				extra[i] = strings.TrimSpace(s)
+				// VARCHAR2(10 CHAR)、VARCHAR2(10 BYTE) 的长度语义不影响长度
+				s = strings.TrimSpace(s)
+				extra[i] = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(s, " char"), " byte"))
 			}
 		}
 	}
@@ -57,19 +59,35 @@ func (o OracleOperator) Trans2CommonField(dataType string) *dboperator.Field {
 	case "number", "numeric", "decimal", "dec":
 		field = *dboperator.Float64Field
 		field.IsFixedNumber = true
+		// NUMBER(p) 按精度映射为整数，超出bigint位数的保持定点数
 		if typeStr == "number" && len(extra) == 1 {
-			if extra[0] <= "32" {
-				field.Type = dboperator.INT32
-				field.IsFixedNumber = false
-			} else {
-				field.Type = dboperator.INT64
-				field.IsFixedNumber = false
+			precision, err := strconv.Atoi(extra[0])
+			switch {
+			case err != nil:
+			case precision <= 9:
+				field.Type, field.IsFixedNumber = dboperator.INT32, false
+			case precision <= 18:
+				field.Type, field.IsFixedNumber = dboperator.INT64, false
 			}
 		}
 	case "double precision", "double", "binary_double":
 		field = *dboperator.Float64Field
 	case "boolean", "bool":
 		field = *dboperator.BoolField
+	case "json":
+		field = *dboperator.JSONField
+	case "xmltype":
+		field = *dboperator.XMLField
+	case "sdo_geometry":
+		field = *dboperator.GeometryField
+	case "interval year", "interval day":
+		// data_type: INTERVAL YEAR(2) TO MONTH、INTERVAL DAY(2) TO SECOND(6)
+		field = *dboperator.IntervalField
+		field.IntervalType = dboperator.IntervalDaySecond
+		if typeStr == "interval year" {
+			field.IntervalType = dboperator.IntervalYearMonth
+		}
+		extra = nil
 	default:
 		log.DefaultLogger().Warn("handle with default oracle type:%s", dataType)
 		field = *dboperator.StringField
@@ -102,6 +120,10 @@ func (o OracleOperator) Trans2CommonField(dataType string) *dboperator.Field {
 }
 
 func (o OracleOperator) Trans2DataType(field *dboperator.Field) string {
+	if field.TargetType != "" {
+		return field.TargetType
+	}
+	field = dboperator.WidenUnsigned(field)
 	switch field.Type {
 	case dboperator.BYTES:
 		fallthrough
@@ -137,7 +159,8 @@ func (o OracleOperator) Trans2DataType(field *dboperator.Field) string {
 		}
 		return "BINARY_DOUBLE"
 	case dboperator.BOOL:
-		return "BOOLEAN"
+		// BOOLEAN 仅 Oracle 23ai 起支持，以 NUMBER(1) 保存，取值由检查约束限定为0、1
+		return "NUMBER(1)"
 	case dboperator.STRING:
 		if field.IsText {
 			return "CLOB"
@@ -147,7 +170,8 @@ func (o OracleOperator) Trans2DataType(field *dboperator.Field) string {
 		} else if field.Length == -1 {
 			return "VARCHAR2(*)"
 		} else {
-			return fmt.Sprintf("VARCHAR2(%d)", field.Length)
+			// 长度为字符数(探查时取 CHAR_LENGTH，其他数据库的 varchar 亦按字符计)，以 CHAR 语义建表
+			return fmt.Sprintf("VARCHAR2(%d CHAR)", field.Length)
 		}
 	case dboperator.TIME:
 		var timeType string
@@ -169,6 +193,26 @@ func (o OracleOperator) Trans2DataType(field *dboperator.Field) string {
 		} else {
 			return fmt.Sprintf("timestamp(%d)", field.Length)
 		}
+	case dboperator.JSON, dboperator.ARRAY:
+		// 数组以json数组保存
+		return "CLOB"
+	case dboperator.UUID:
+		return "VARCHAR2(36)"
+	case dboperator.ENUM, dboperator.SET:
+		// ENUM的可选值由检查约束限定
+		if length := dboperator.EnumLength(field); length <= 4000 {
+			return fmt.Sprintf("VARCHAR2(%d)", length)
+		}
+		return "CLOB"
+	case dboperator.INTERVAL:
+		if field.IntervalType == dboperator.IntervalYearMonth {
+			return "INTERVAL YEAR(9) TO MONTH"
+		}
+		return "INTERVAL DAY(9) TO SECOND(6)"
+	case dboperator.XML:
+		return "XMLTYPE"
+	case dboperator.GEOMETRY:
+		return "SDO_GEOMETRY"
 	default:
 		log.DefaultLogger().Warn("handle with to default oracle type:%s", field.Type)
 		return "CLOB"
@@ -195,3 +239,41 @@ func getTypeSuffix(field *dboperator.Field) string {
 	}
 	return ""
 }
+
+// getColumnOption 生成字段默认值(自增列为自增属性)、非空约束及检查约束
+func getColumnOption(field *dboperator.Field) (option string) {
+	if field.IsAutoIncrement {
+		option += fmt.Sprintf(" generated by default as identity (start with %d)", field.AutoIncrementStart)
+	} else if defaultValue := getDefaultValue(field); defaultValue != "" {
+		option += " default " + defaultValue
+	}
+	if !field.ISNullable {
+		option += " not null"
+	}
+	if expression := dboperator.EnumCheckExpression(field, identifier); expression != "" {
+		option += " check (" + expression + ")"
+	}
+	if field.Type == dboperator.BOOL {
+		option += " check (" + identifier.Name(field.ColumnName) + " in (0,1))"
+	}
+	// json以CLOB保存，以 is json 约束校验内容
+	if field.Type == dboperator.JSON {
+		option += " check (" + identifier.Name(field.ColumnName) + " is json)"
+	}
+	return
+}
+
+// getDefaultValue 将通用默认值翻译为oracle写法
+func getDefaultValue(field *dboperator.Field) string {
+	switch field.DefaultValue {
+	case dboperator.DefaultTrue:
+		return "1"
+	case dboperator.DefaultFalse:
+		return "0"
+	case dboperator.DefaultCurrentDate:
+		return "TRUNC(SYSDATE)"
+	case dboperator.DefaultCurrentTime:
+		return dboperator.DefaultCurrentTimestamp
+	}
+	return field.DefaultValue
+}
@@ -3,8 +3,10 @@ package oracle
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/jasonlabz/dbutil/dboperator"
 	"github.com/jasonlabz/dbutil/dbx"
 )
 
@@ -34,3 +36,28 @@ func TestOP(t *testing.T) {
 	}
 	println(columnsUnderTables)
 }
+
+func TestBoolDataType(t *testing.T) {
+	operator := NewOracleOperator()
+	field := &dboperator.Field{Type: dboperator.BOOL, ColumnName: "ENABLED", DefaultValue: dboperator.DefaultTrue}
+	if dataType := operator.Trans2DataType(field); dataType != "NUMBER(1)" {
+		t.Fatalf("expected NUMBER(1), got %s", dataType)
+	}
+	option := getColumnOption(field)
+	if !strings.Contains(option, " default 1") {
+		t.Fatalf("expected default 1, got %s", option)
+	}
+	if expected := " check (" + identifier.Name("ENABLED") + " in (0,1))"; !strings.Contains(option, expected) {
+		t.Fatalf("expected %s, got %s", expected, option)
+	}
+}
+
+func TestCharLengthDataType(t *testing.T) {
+	operator := NewOracleOperator()
+	for _, dataType := range []string{"VARCHAR2(10)", "VARCHAR2(10 CHAR)", "VARCHAR2(10 BYTE)"} {
+		field := operator.Trans2CommonField(dataType)
+		if target := operator.Trans2DataType(field); target != "VARCHAR2(10 CHAR)" {
+			t.Errorf("%s => %s, expected VARCHAR2(10 CHAR)", dataType, target)
+		}
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/jasonlabz/dbutil/core/utils"
 	"github.com/jasonlabz/dbutil/dboperator"
 	"github.com/jasonlabz/dbutil/dbx"
+	"github.com/jasonlabz/dbutil/log"
 )
 
 func NewOracleOperator() dboperator.IOperator {
@@ -17,6 +19,9 @@ func NewOracleOperator() dboperator.IOperator {
 
 type OracleOperator struct{}
 
+// identifier 标识符规则，见 dboperator.GetIdentifierPolicy
+var identifier = dboperator.GetIdentifierPolicy(dbx.DBTypeOracle)
+
 func (o OracleOperator) GetDB(name string) (*dbx.DBWrapper, error) {
 	return dbx.GetDB(name)
 }
@@ -45,15 +50,19 @@ func (o OracleOperator) GetDataBySQL(ctx context.Context, dbName, sqlStatement s
 	return
 }
 
+func (o OracleOperator) GetSchema(ctx context.Context, dbName, schemaName string) (schema *dboperator.Schema, err error) {
+	return dboperator.InspectSchema(ctx, o, dbx.DBTypeOracle, dbName, schemaName)
+}
+
 func (o OracleOperator) GetTableData(ctx context.Context, dbName, schemaName, tableName string, pageInfo *dboperator.Pagination) (rows []map[string]interface{}, err error) {
 	rows = make([]map[string]interface{}, 0)
 	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
-	queryTable := fmt.Sprintf("\"%s\"", tableName)
+	queryTable := identifier.Quote(tableName)
 	if schemaName != "" {
-		queryTable = fmt.Sprintf("\"%s\".\"%s\"", schemaName, tableName)
+		queryTable = identifier.Quote(schemaName) + "." + queryTable
 	}
 	var count int64
 	tx := db.DB.WithContext(ctx).
@@ -84,13 +93,16 @@ func (o OracleOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 		return
 	}
 	err = db.DB.WithContext(ctx).
-		Raw("SELECT OWNER as table_schema, " +
-			"TABLE_NAME as table_name, " +
-			"COMMENTS as comments " +
-			"FROM all_tab_comments " +
-			"WHERE TABLE_TYPE = 'TABLE' AND OWNER IN " +
+		Raw("SELECT c.OWNER as table_schema, " +
+			"c.TABLE_NAME as table_name, " +
+			"c.COMMENTS as comments, " +
+			"NVL(t.NUM_ROWS, -1) as row_count, " +
+			"(SELECT VALUE FROM NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_CHARACTERSET') as charset " +
+			"FROM all_tab_comments c " +
+			"LEFT JOIN all_tables t ON t.OWNER = c.OWNER AND t.TABLE_NAME = c.TABLE_NAME " +
+			"WHERE c.TABLE_TYPE = 'TABLE' AND c.OWNER IN " +
 			"(" + strings.Join(schemas, ",") + ") " +
-			"ORDER BY OWNER, TABLE_NAME").
+			"ORDER BY c.OWNER, c.TABLE_NAME").
 		Find(&gormDBTables).Error
 	if err != nil {
 		return
@@ -105,6 +117,8 @@ func (o OracleOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Charset:   row.Charset,
 				}},
 			}
 		} else {
@@ -112,6 +126,8 @@ func (o OracleOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Charset:   row.Charset,
 				})
 		}
 	}
@@ -130,13 +146,15 @@ func (o OracleOperator) GetTablesUnderDB(ctx context.Context, dbName string) (db
 		return
 	}
 	err = db.DB.WithContext(ctx).
-		Raw("SELECT OWNER as table_schema, " +
-			"TABLE_NAME as table_name, " +
-			"COMMENTS as comments " +
-			"FROM all_tab_comments " +
-			"WHERE TABLE_TYPE = 'TABLE' AND OWNER IN " +
+		Raw("SELECT c.OWNER as table_schema, " +
+			"c.TABLE_NAME as table_name, " +
+			"c.COMMENTS as comments, " +
+			"NVL(t.NUM_ROWS, -1) as row_count " +
+			"FROM all_tab_comments c " +
+			"LEFT JOIN all_tables t ON t.OWNER = c.OWNER AND t.TABLE_NAME = c.TABLE_NAME " +
+			"WHERE c.TABLE_TYPE = 'TABLE' AND c.OWNER IN " +
 			"(select SYS_CONTEXT('USERENV','CURRENT_SCHEMA') CURRENT_SCHEMA from dual) " +
-			"ORDER BY OWNER, TABLE_NAME").
+			"ORDER BY c.OWNER, c.TABLE_NAME").
 		Find(&gormDBTables).Error
 	if err != nil {
 		return
@@ -151,6 +169,7 @@ func (o OracleOperator) GetTablesUnderDB(ctx context.Context, dbName string) (db
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				}},
 			}
 		} else {
@@ -158,6 +177,7 @@ func (o OracleOperator) GetTablesUnderDB(ctx context.Context, dbName string) (db
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				})
 		}
 	}
@@ -202,7 +222,9 @@ func (o OracleOperator) GetColumns(ctx context.Context, dbName string) (dbTableC
 			"        'true' " +
 			"    else " +
 			"        'false' " +
-			"end as is_nullable " +
+			"end as is_nullable, " +
+			"atc.DATA_DEFAULT as column_default, " +
+			"atc.COLUMN_ID as ordinal_position " +
 			"FROM ALL_TAB_COLUMNS atc " +
 			"left join all_col_comments acc " +
 			"on acc.OWNER=atc.OWNER and acc.TABLE_NAME = atc.TABLE_NAME and acc.COLUMN_NAME = atc.COLUMN_NAME " +
@@ -222,9 +244,12 @@ func (o OracleOperator) GetColumns(ctx context.Context, dbName string) (dbTableC
 				row.TableName: {
 					TableName: row.TableName,
 					ColumnInfoList: []*dboperator.ColumnInfo{{
-						ColumnName: row.ColumnName,
-						Comment:    row.Comments,
-						DataType:   row.DataType,
+						ColumnName:      row.ColumnName,
+						Comment:         row.Comments,
+						DataType:        row.DataType,
+						IsNullable:      row.IsNullable,
+						DefaultValue:    row.ColumnDefault,
+						OrdinalPosition: row.OrdinalPosition,
 					}},
 				},
 			}
@@ -232,19 +257,31 @@ func (o OracleOperator) GetColumns(ctx context.Context, dbName string) (dbTableC
 			dbTableColInfoMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    row.ColumnDefault,
+					OrdinalPosition: row.OrdinalPosition,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    row.ColumnDefault,
+				OrdinalPosition: row.OrdinalPosition,
 			})
 		}
 	}
+	for schemaName, tableColMap := range dbTableColMap {
+		err = o.fillAutoIncrement(ctx, db, schemaName, tableColMap)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -291,7 +328,9 @@ func (o OracleOperator) GetColumnsUnderTables(ctx context.Context, dbName, logic
 			"        'true' "+
 			"    else "+
 			"        'false' "+
-			"end as is_nullable "+
+			"end as is_nullable, "+
+			"atc.DATA_DEFAULT as column_default, "+
+			"atc.COLUMN_ID as ordinal_position "+
 			"FROM ALL_TAB_COLUMNS atc "+
 			"left join all_col_comments acc "+
 			"on  acc.OWNER=atc.OWNER and acc.TABLE_NAME = atc.TABLE_NAME and acc.COLUMN_NAME = atc.COLUMN_NAME "+
@@ -311,19 +350,128 @@ func (o OracleOperator) GetColumnsUnderTables(ctx context.Context, dbName, logic
 			tableColMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    row.ColumnDefault,
+					OrdinalPosition: row.OrdinalPosition,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    row.ColumnDefault,
+				OrdinalPosition: row.OrdinalPosition,
 			})
 		}
 	}
+	err = o.fillAutoIncrement(ctx, db, logicDBName, tableColMap)
+	return
+}
+
+// fillAutoIncrement 标记identity列(12c及以上)、以序列作默认值的列及由 before insert 触发器从序列取值的列，下一个值取序列的LAST_NUMBER
+func (o OracleOperator) fillAutoIncrement(ctx context.Context, db *dbx.DBWrapper, schemaName string, tableColMap map[string]*dboperator.TableColInfo) (err error) {
+	if len(tableColMap) == 0 {
+		return
+	}
+	autoIncrements := make([]*dboperator.TableAutoIncrement, 0)
+	identityErr := db.DB.WithContext(ctx).Raw(`SELECT c.TABLE_NAME AS table_name, c.COLUMN_NAME AS column_name, s.LAST_NUMBER AS next_value
+FROM ALL_TAB_IDENTITY_COLS c
+JOIN ALL_SEQUENCES s ON s.SEQUENCE_OWNER = c.OWNER AND s.SEQUENCE_NAME = c.SEQUENCE_NAME
+WHERE c.OWNER = ? AND c.TABLE_NAME IN ?`, schemaName, utils.SortedKeys(tableColMap)).
+		Scan(&autoIncrements).Error
+	if identityErr != nil {
+		// 12c 之前不支持identity列
+		log.GetLogger(ctx).Warn("query identity columns error: %s", identityErr.Error())
+		autoIncrements = autoIncrements[:0]
+	}
+
+	// 12c之前以 before insert 触发器从序列取值
+	triggers := make([]*dboperator.TableTrigger, 0)
+	triggerErr := db.DB.WithContext(ctx).Raw(`SELECT TABLE_NAME AS table_name, TRIGGER_NAME AS trigger_name, TRIGGER_BODY AS trigger_body
+FROM ALL_TRIGGERS
+WHERE TABLE_OWNER = ? AND TABLE_NAME IN ? AND TRIGGER_TYPE = 'BEFORE EACH ROW' AND TRIGGERING_EVENT LIKE '%INSERT%' AND STATUS = 'ENABLED'`,
+		schemaName, utils.SortedKeys(tableColMap)).Scan(&triggers).Error
+	if triggerErr != nil {
+		log.GetLogger(ctx).Warn("query insert triggers error: %s", triggerErr.Error())
+		triggers = triggers[:0]
+	}
+
+	// 以序列作默认值或由触发器从序列取值的列，下一个值取序列的LAST_NUMBER
+	for _, column := range dboperator.SequenceColumns(schemaName, tableColMap, triggers) {
+		lastNumbers := make([]int64, 0)
+		err = db.DB.WithContext(ctx).Raw(`SELECT LAST_NUMBER FROM ALL_SEQUENCES WHERE SEQUENCE_OWNER = ? AND SEQUENCE_NAME = ?`, column.Owner, column.SequenceName).
+			Scan(&lastNumbers).Error
+		if err != nil {
+			return
+		}
+		if len(lastNumbers) == 0 {
+			log.GetLogger(ctx).Warn("sequence %s.%s of column %s.%s not found", column.Owner, column.SequenceName, column.TableName, column.ColumnName)
+			continue
+		}
+		autoIncrements = append(autoIncrements, &dboperator.TableAutoIncrement{
+			TableName:  column.TableName,
+			ColumnName: column.ColumnName,
+			NextValue:  lastNumbers[0],
+		})
+	}
+	dboperator.FillAutoIncrement(tableColMap, autoIncrements)
+	return
+}
+
+func (o OracleOperator) GetViewsUnderSchema(ctx context.Context, dbName string, schemas []string) (schemaViewMap map[string][]*dboperator.ViewInfo, err error) {
+	if dbName == "" || len(schemas) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	// 定义为LONG类型，不能与其他查询union，分别查询
+	gormDBViews := make([]*dboperator.GormDBView, 0)
+	err = db.DB.WithContext(ctx).Raw(`select OWNER as schema_name, VIEW_NAME as view_name, TEXT as definition
+    from ALL_VIEWS where OWNER in ? order by OWNER, VIEW_NAME`, schemas).
+		Scan(&gormDBViews).Error
+	if err != nil {
+		return
+	}
+	materializedViews := make([]*dboperator.GormDBView, 0)
+	err = db.DB.WithContext(ctx).Raw(`select OWNER as schema_name, MVIEW_NAME as view_name, QUERY as definition, 1 as is_materialized
+    from ALL_MVIEWS where OWNER in ? order by OWNER, MVIEW_NAME`, schemas).
+		Scan(&materializedViews).Error
+	if err != nil {
+		return
+	}
+	schemaViewMap = dboperator.GroupViews(append(gormDBViews, materializedViews...))
+	return
+}
+
+func (o OracleOperator) CreateViews(ctx context.Context, dbName, schemaName string, views []*dboperator.ViewInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, o.RenderViews(ctx, schemaName, views))
+}
+
+// RenderViews 语句逐条执行故不带分号
+func (o OracleOperator) RenderViews(ctx context.Context, schemaName string, views []*dboperator.ViewInfo) (statements []string) {
+	for _, view := range views {
+		viewFullName := identifier.QualifiedName(schemaName, view.ViewName)
+		viewStr := fmt.Sprintf("create or replace view %s as %s", viewFullName, identifier.QuoteExpression(view.Definition))
+		if view.IsMaterialized {
+			viewStr = fmt.Sprintf("create materialized view %s as %s", viewFullName, identifier.QuoteExpression(view.Definition))
+		}
+		statements = append(statements, viewStr)
+	}
 	return
 }
 
@@ -340,7 +488,7 @@ func (o OracleOperator) CreateSchema(ctx context.Context, dbName, schemaName, co
 		return
 	}
 	var count int64
-	err = db.DB.WithContext(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM ALL_USERS WHERE UPPER(USERNAME) = '%s'", schemaName)).Scan(&count).Error
+	err = db.DB.WithContext(ctx).Raw("SELECT COUNT(*) FROM ALL_USERS WHERE USERNAME = ?", identifier.Fold(schemaName)).Scan(&count).Error
 	if err != nil {
 		return
 	}
@@ -348,11 +496,11 @@ func (o OracleOperator) CreateSchema(ctx context.Context, dbName, schemaName, co
 		return
 	}
 
-	err = db.DB.WithContext(ctx).Exec(fmt.Sprintf("create user %s identified by %s", schemaName, schemaName)).Error
+	err = db.DB.WithContext(ctx).Exec(fmt.Sprintf("create user %s identified by %s", identifier.Name(schemaName), schemaName)).Error
 	if err != nil {
 		return
 	}
-	err = db.DB.WithContext(ctx).Exec(fmt.Sprintf("grant connect, resource to %s", schemaName)).Error
+	err = db.DB.WithContext(ctx).Exec(fmt.Sprintf("grant connect, resource to %s", identifier.Name(schemaName))).Error
 	if err != nil {
 		return
 	}
@@ -423,8 +571,128 @@ func (o OracleOperator) GetTableUniqueKeys(ctx context.Context, dbName string, s
 	return
 }
 
+func (o OracleOperator) GetTableIndexes(ctx context.Context, dbName string, schemaName string, tables []string) (indexInfo map[string][]*dboperator.IndexInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableIndexColumns := make([]*dboperator.TableIndexColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`select ic.TABLE_OWNER as schema_name, ic.TABLE_NAME as table_name, ic.INDEX_NAME as index_name,
+    case when ai.UNIQUENESS = 'UNIQUE' then 1 else 0 end as is_unique,
+    ic.COLUMN_POSITION as column_position, ic.COLUMN_NAME as column_name,
+    case when ic.DESCEND = 'DESC' then 1 else 0 end as is_desc,
+    ie.COLUMN_EXPRESSION as expression
+    from ALL_IND_COLUMNS ic
+    join ALL_INDEXES ai on ai.OWNER = ic.INDEX_OWNER and ai.INDEX_NAME = ic.INDEX_NAME
+    left join ALL_IND_EXPRESSIONS ie on ie.INDEX_OWNER = ic.INDEX_OWNER and ie.INDEX_NAME = ic.INDEX_NAME and ie.COLUMN_POSITION = ic.COLUMN_POSITION
+    where ai.INDEX_TYPE in ('NORMAL', 'FUNCTION-BASED NORMAL')
+    and not exists (select 1 from ALL_CONSTRAINTS ac where ac.OWNER = ai.TABLE_OWNER and ac.INDEX_NAME = ai.INDEX_NAME and ac.CONSTRAINT_TYPE in ('P', 'U'))
+    and ic.TABLE_OWNER = ? and ic.TABLE_NAME in ?
+    order by ic.TABLE_NAME, ic.INDEX_NAME, ic.COLUMN_POSITION`, schemaName, tables).
+		Scan(&tableIndexColumns).Error
+	if err != nil {
+		return
+	}
+	indexInfo = dboperator.GroupIndexColumns(tableIndexColumns)
+	return
+}
+
+func (o OracleOperator) CreateIndexes(ctx context.Context, dbName, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, o.RenderIndexes(ctx, schemaName, indexesMap))
+}
+
+func (o OracleOperator) RenderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (statements []string) {
+	indexNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.IndexNames(indexesMap))
+	for _, tableName := range utils.SortedKeys(indexesMap) {
+		for _, index := range indexesMap[tableName] {
+			if index.Filter != "" {
+				log.GetLogger(ctx).Warn("oracle does not support partial index, skip index %s on %s", index.IndexName, tableName)
+				continue
+			}
+			columns := make([]string, 0, len(index.Columns))
+			for _, column := range index.Columns {
+				columnStr := utils.IsTrueOrNot(column.Expression != "", column.Expression, identifier.Name(column.ColumnName))
+				columns = append(columns, columnStr+utils.IsTrueOrNot(column.IsDesc, " desc", ""))
+			}
+			indexName := indexNames.Get(tableName, index.IndexName)
+			statements = append(statements, fmt.Sprintf("create %sindex %s on %s (%s)",
+				utils.IsTrueOrNot(index.IsUnique, "unique ", ""), identifier.QualifiedName(schemaName, indexName),
+				identifier.QualifiedName(schemaName, tableName), strings.Join(columns, ",")))
+		}
+	}
+	return
+}
+
+func (o OracleOperator) GetTableForeignKeys(ctx context.Context, dbName string, schemaName string, tables []string) (foreignKeyInfo map[string][]*dboperator.ForeignKeyInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableForeignKeyColumns := make([]*dboperator.TableForeignKeyColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`select c.OWNER as schema_name, c.TABLE_NAME as table_name, c.CONSTRAINT_NAME as constraint_name,
+    cc.COLUMN_NAME as column_name, r.OWNER as ref_schema_name, r.TABLE_NAME as ref_table_name,
+    rc.COLUMN_NAME as ref_column_name, cc.POSITION as column_position, c.DELETE_RULE as on_delete
+    from ALL_CONSTRAINTS c
+    join ALL_CONS_COLUMNS cc on cc.OWNER = c.OWNER and cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+    join ALL_CONSTRAINTS r on r.OWNER = c.R_OWNER and r.CONSTRAINT_NAME = c.R_CONSTRAINT_NAME
+    join ALL_CONS_COLUMNS rc on rc.OWNER = r.OWNER and rc.CONSTRAINT_NAME = r.CONSTRAINT_NAME and rc.POSITION = cc.POSITION
+    where c.CONSTRAINT_TYPE = 'R' and c.OWNER = ? and c.TABLE_NAME in ?
+    order by c.TABLE_NAME, c.CONSTRAINT_NAME, cc.POSITION`, schemaName, tables).
+		Scan(&tableForeignKeyColumns).Error
+	if err != nil {
+		return
+	}
+	foreignKeyInfo = dboperator.GroupForeignKeyColumns(tableForeignKeyColumns)
+	return
+}
+
+func (o OracleOperator) GetTableCheckConstraints(ctx context.Context, dbName string, schemaName string, tables []string) (checkInfo map[string][]*dboperator.CheckInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableCheckConstraints := make([]*dboperator.TableCheckConstraint, 0)
+	err = db.DB.WithContext(ctx).Raw(`select c.OWNER as schema_name, c.TABLE_NAME as table_name, c.CONSTRAINT_NAME as constraint_name,
+    c.SEARCH_CONDITION as expression
+    from ALL_CONSTRAINTS c
+    where c.CONSTRAINT_TYPE = 'C' and c.OWNER = ? and c.TABLE_NAME in ?
+    order by c.TABLE_NAME, c.CONSTRAINT_NAME`, schemaName, tables).
+		Scan(&tableCheckConstraints).Error
+	if err != nil {
+		return
+	}
+	// 非空约束同样以检查约束存储，已由字段可空性体现
+	checks := make([]*dboperator.TableCheckConstraint, 0, len(tableCheckConstraints))
+	for _, check := range tableCheckConstraints {
+		if !notNullCheckReg.MatchString(check.Expression) {
+			checks = append(checks, check)
+		}
+	}
+	checkInfo = dboperator.GroupCheckConstraints(checks)
+	return
+}
+
+var notNullCheckReg = regexp.MustCompile(`(?i)^\s*"?[^"\s]+"?\s+is\s+not\s+null\s*$`)
+
 func (o OracleOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string,
-	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field) (ddlSQL string, err error) {
+	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (ddlSQL string, err error) {
 	if dbName == "" {
 		err = errors.New("empty dnName")
 		return
@@ -433,25 +701,32 @@ func (o OracleOperator) ExecuteDDL(ctx context.Context, dbName, schemaName strin
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, o.RenderDDL(ctx, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap))
+}
 
-	//ddlSQL := ""
+// RenderDDL 语句逐条执行故不带分号
+func (o OracleOperator) RenderDDL(ctx context.Context, schemaName string, primaryKeysMap map[string][]string,
+	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (statements []string) {
 	ddlTemplate := `
 create table %s (
     %s
 )`
-	for tableName, fields := range tableFieldsMap {
+	uniqueNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.UniqueKeyNames(uniqueKeysMap))
+	checkNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.CheckNames(checksMap))
+	for _, tableName := range dboperator.TableCreationOrder(ctx, tableFieldsMap, foreignKeysMap) {
+		fields := tableFieldsMap[tableName]
 		var includeField string
 		for _, field := range fields {
 			if field == nil {
 				continue
 			}
 			dataType := o.Trans2DataType(field)
-			includeField += fmt.Sprintf("	%s %s,", utils.QuotaName(field.ColumnName), dataType) + fmt.Sprintln()
+			includeField += fmt.Sprintf("	%s %s%s,", identifier.Name(field.ColumnName), dataType, getColumnOption(field)) + fmt.Sprintln()
 		}
 		if len(primaryKeysMap) > 0 {
-			keys := primaryKeysMap[tableName]
-			for i, key := range keys {
-				keys[i] = utils.QuotaName(key)
+			keys := make([]string, 0, len(primaryKeysMap[tableName]))
+			for _, key := range primaryKeysMap[tableName] {
+				keys = append(keys, identifier.Name(key))
 			}
 			if len(keys) > 0 {
 				includeField += fmt.Sprintf("	primary key (%s),", strings.Join(keys, ",")) + fmt.Sprintln()
@@ -459,27 +734,129 @@ create table %s (
 		}
 		if len(uniqueKeysMap) > 0 {
 			uniqueKeys := uniqueKeysMap[tableName]
-			for _, columns := range uniqueKeys {
-				for i, column := range columns {
-					columns[i] = utils.QuotaName(column)
+			for _, uniqueName := range utils.SortedKeys(uniqueKeys) {
+				uniqueColumns := uniqueKeys[uniqueName]
+				columns := make([]string, 0, len(uniqueColumns))
+				for _, column := range uniqueColumns {
+					columns = append(columns, identifier.Name(column))
 				}
-				includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+				if uniqueName == "" {
+					includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+					continue
+				}
+				constraintName := uniqueNames.Get(tableName, uniqueName)
+				includeField += fmt.Sprintf("	constraint %s unique (%s),", identifier.Name(constraintName), strings.Join(columns, ",")) + fmt.Sprintln()
 			}
 		}
 
+		for _, check := range checksMap[tableName] {
+			checkName := checkNames.Get(tableName, check.ConstraintName)
+			includeField += fmt.Sprintf("	constraint %s check (%s),", identifier.Name(checkName), identifier.QuoteExpression(check.Expression)) + fmt.Sprintln()
+		}
+
 		includeField = strings.TrimSpace(includeField)
 		includeField = strings.Trim(includeField, ",")
 
-		ddlStr := fmt.Sprintf(ddlTemplate, fmt.Sprintf("%s.%s", utils.QuotaName(schemaName), utils.QuotaName(tableName)), includeField)
-		err = db.DB.WithContext(ctx).Exec(ddlStr).Error
-		if err != nil {
-			return
+		tableFullName := identifier.QualifiedName(schemaName, tableName)
+		statements = append(statements, fmt.Sprintf(ddlTemplate, tableFullName, includeField))
+		statements = append(statements, getCommentDDL(tableFullName, tableCommentMap[tableName], fields)...)
+	}
+	foreignKeyNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.ForeignKeyNames(foreignKeysMap))
+	for _, tableName := range utils.SortedKeys(foreignKeysMap) {
+		if _, ok := tableFieldsMap[tableName]; !ok {
+			continue
+		}
+		for _, foreignKey := range foreignKeysMap[tableName] {
+			constraintName := foreignKeyNames.Get(tableName, foreignKey.ConstraintName)
+			statements = append(statements, getForeignKeyDDL(schemaName, tableName, constraintName, foreignKey))
+		}
+	}
+	return
+}
+
+func (o OracleOperator) ExecuteAlterations(ctx context.Context, dbName, schemaName string, alterations []*dboperator.TableAlteration) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, o.RenderAlterations(ctx, schemaName, alterations))
+}
+
+// RenderAlterations 语句逐条执行故不带分号；modify 指定的非空与现状相同时会报错，仅在可空变化时指定
+func (o OracleOperator) RenderAlterations(ctx context.Context, schemaName string, alterations []*dboperator.TableAlteration) (statements []string) {
+	for _, alteration := range alterations {
+		tableFullName := identifier.QualifiedQuote(schemaName, alteration.TableName)
+		template := "alter table " + tableFullName + " %s"
+		switch alteration.Action {
+		case dboperator.AlterAddColumn:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add (%s %s%s)", identifier.Quote(alteration.Field.ColumnName),
+				o.Trans2DataType(alteration.Field), getColumnOption(alteration.Field))))
+			statements = append(statements, getCommentDDL(tableFullName, "", []*dboperator.Field{alteration.Field})...)
+		case dboperator.AlterDropColumn:
+			statements = append(statements, fmt.Sprintf(template, "drop column "+identifier.Quote(alteration.ColumnName)))
+		case dboperator.AlterModifyColumn:
+			definition := identifier.Quote(alteration.Field.ColumnName)
+			if alteration.TypeChanged {
+				definition += " " + o.Trans2DataType(alteration.Field)
+			}
+			if alteration.NullableChanged {
+				definition += utils.IsTrueOrNot(alteration.Field.ISNullable, " null", " not null")
+			}
+			statements = append(statements, fmt.Sprintf(template, "modify ("+definition+")"))
+		case dboperator.AlterAddPrimaryKey:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add primary key (%s)", identifier.QuoteList(alteration.Columns))))
+		case dboperator.AlterDropPrimaryKey:
+			statements = append(statements, fmt.Sprintf(template, "drop primary key"))
+		case dboperator.AlterAddUnique:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add unique (%s)", identifier.QuoteList(alteration.Columns))))
+		case dboperator.AlterDropUnique:
+			statements = append(statements, fmt.Sprintf(template, "drop constraint "+identifier.Quote(alteration.ConstraintName)))
+		}
+	}
+	return
+}
+
+// getForeignKeyDDL 生成添加外键约束语句，oracle仅支持 on delete cascade/set null
+func getForeignKeyDDL(schemaName, tableName, constraintName string, foreignKey *dboperator.ForeignKeyInfo) string {
+	columns := make([]string, 0, len(foreignKey.Columns))
+	for _, column := range foreignKey.Columns {
+		columns = append(columns, identifier.Name(column))
+	}
+	refColumns := make([]string, 0, len(foreignKey.RefColumns))
+	for _, column := range foreignKey.RefColumns {
+		refColumns = append(refColumns, identifier.Name(column))
+	}
+	foreignKeySQL := fmt.Sprintf("alter table %s add constraint %s foreign key (%s) references %s (%s)",
+		identifier.QualifiedName(schemaName, tableName), identifier.Name(constraintName), strings.Join(columns, ","),
+		identifier.QualifiedName(schemaName, foreignKey.RefTableName), strings.Join(refColumns, ","))
+	switch foreignKey.OnDelete {
+	case dboperator.ActionCascade, dboperator.ActionSetNull:
+		foreignKeySQL += " on delete " + strings.ToLower(foreignKey.OnDelete)
+	case dboperator.ActionSetDefault:
+		log.DefaultLogger().Warn("oracle does not support on delete set default, ignore it on foreign key %s", foreignKey.ConstraintName)
+	}
+	// 未指定、NO ACTION、RESTRICT 均为禁止更新被引用的键，与oracle的默认行为相同
+	switch foreignKey.OnUpdate {
+	case dboperator.ActionCascade, dboperator.ActionSetNull, dboperator.ActionSetDefault:
+		log.DefaultLogger().Warn("oracle does not support on update %s, ignore it on foreign key %s", strings.ToLower(foreignKey.OnUpdate), foreignKey.ConstraintName)
+	}
+	return foreignKeySQL
+}
+
+// getCommentDDL 生成表及字段注释语句，逐条执行故不带分号
+func getCommentDDL(tableFullName, tableComment string, fields []*dboperator.Field) (commentSQLs []string) {
+	if tableComment != "" {
+		commentSQLs = append(commentSQLs, fmt.Sprintf("comment on table %s is %s", tableFullName, utils.QuotaString(tableComment)))
+	}
+	for _, field := range fields {
+		if field == nil || field.Comment == "" {
+			continue
 		}
-		ddlSQL += ddlStr + fmt.Sprintln()
+		commentSQLs = append(commentSQLs, fmt.Sprintf("comment on column %s.%s is %s", tableFullName, identifier.Name(field.ColumnName), utils.QuotaString(field.Comment)))
 	}
-	//err = db.DB.WithContext(ctx).Exec(ddlSQL).Error
-	//if err != nil {
-	//	return
-	//}
 	return
 }
@@ -14,6 +14,12 @@ func (p PGOperator) Trans2CommonField(dataType string) *dboperator.Field {
 	var field dboperator.Field
 	lowerWords := strings.ToLower(dataType)
 	typeStr := lowerWords
+	// 数组类型: udt_name 为 _int4，或 integer[]
+	if strings.HasPrefix(lowerWords, "_") || strings.HasSuffix(lowerWords, "[]") {
+		field = *dboperator.ArrayField
+		field.ElementType = p.Trans2CommonField(strings.TrimSuffix(strings.TrimPrefix(dataType, "_"), "[]"))
+		return &field
+	}
 	var extra []string
 	if strings.Contains(dataType, ")") {
 		lIndex := strings.Index(dataType, "(")
@@ -68,6 +74,25 @@ func (p PGOperator) Trans2CommonField(dataType string) *dboperator.Field {
 		field = *dboperator.Float64Field
 	case "boolean", "bool":
 		field = *dboperator.BoolField
+	case "json", "jsonb":
+		field = *dboperator.JSONField
+	case "uuid":
+		field = *dboperator.UUIDField
+	case "enum":
+		// 枚举类型的字段查询时已展开为 enum('a','b')
+		field = *dboperator.EnumField
+		field.EnumValues = dboperator.ParseEnumValues(dataType)
+		extra = nil
+	case "interval":
+		field = *dboperator.IntervalField
+	case "interval year to month", "interval day to second":
+		field = *dboperator.IntervalField
+		field.IntervalType = utils.IsTrueOrNot(typeStr == "interval year to month", dboperator.IntervalYearMonth, dboperator.IntervalDaySecond)
+	case "xml":
+		field = *dboperator.XMLField
+	case "geometry", "geography":
+		field = *dboperator.GeometryField
+		field.GeometryType, _ = dboperator.GeometryType(typeStr)
 	default:
 		log.DefaultLogger().Warn("handle with default postgresql type:%s", dataType)
 		field = *dboperator.StringField
@@ -100,6 +125,10 @@ func (p PGOperator) Trans2CommonField(dataType string) *dboperator.Field {
 }
 
 func (p PGOperator) Trans2DataType(field *dboperator.Field) string {
+	if field.TargetType != "" {
+		return field.TargetType
+	}
+	field = dboperator.WidenUnsigned(field)
 	switch field.Type {
 	case dboperator.BYTES:
 		fallthrough
@@ -144,6 +173,37 @@ func (p PGOperator) Trans2DataType(field *dboperator.Field) string {
 			timeType = "timestamp"
 		}
 		return utils.IsTrueOrNot(field.Length == 0, timeType, fmt.Sprintf("%s(%d)", timeType, field.Length))
+	case dboperator.JSON:
+		return "jsonb"
+	case dboperator.UUID:
+		return "uuid"
+	case dboperator.ENUM, dboperator.SET:
+		// 枚举需单独建类型，以varchar保存，ENUM的可选值由检查约束限定
+		return fmt.Sprintf("varchar(%d)", dboperator.EnumLength(field))
+	case dboperator.ARRAY:
+		if field.ElementType == nil {
+			return "text[]"
+		}
+		return p.Trans2DataType(field.ElementType) + "[]"
+	case dboperator.INTERVAL:
+		switch field.IntervalType {
+		case dboperator.IntervalYearMonth:
+			return "interval year to month"
+		case dboperator.IntervalDaySecond:
+			return "interval day to second"
+		}
+		return "interval"
+	case dboperator.XML:
+		return "xml"
+	case dboperator.GEOMETRY:
+		// 需安装postgis扩展
+		switch field.GeometryType {
+		case "":
+			return "geometry"
+		case "geography":
+			return "geography"
+		}
+		return fmt.Sprintf("geometry(%s)", field.GeometryType)
 	default:
 		log.DefaultLogger().Warn("handle with to default postgresql type:%s", field.Type)
 		return "text"
@@ -159,3 +219,39 @@ func getTypeSuffix(field *dboperator.Field) string {
 	}
 	return ""
 }
+
+// getColumnOption 生成字段排序规则、默认值(自增列为自增属性)、非空约束及枚举检查约束
+func getColumnOption(field *dboperator.Field) (option string) {
+	if field.Collation != "" {
+		option += " collate " + identifier.Quote(field.Collation)
+	}
+	if field.IsAutoIncrement {
+		option += fmt.Sprintf(" generated by default as identity (start with %d)", field.AutoIncrementStart)
+	} else if defaultValue := getDefaultValue(field); defaultValue != "" {
+		option += " default " + defaultValue
+	}
+	if !field.ISNullable {
+		option += " not null"
+	}
+	if expression := dboperator.EnumCheckExpression(field, identifier); expression != "" {
+		option += " check (" + expression + ")"
+	}
+	return
+}
+
+// getDefaultValue 将通用默认值翻译为postgresql写法
+func getDefaultValue(field *dboperator.Field) string {
+	switch field.DefaultValue {
+	case dboperator.DefaultTrue, dboperator.DefaultFalse:
+		return strings.ToLower(field.DefaultValue)
+	}
+	if field.Type == dboperator.BOOL {
+		switch field.DefaultValue {
+		case "1", "'1'":
+			return "true"
+		case "0", "'0'":
+			return "false"
+		}
+	}
+	return field.DefaultValue
+}
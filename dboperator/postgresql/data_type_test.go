@@ -13,3 +13,29 @@ func TestDataType(t *testing.T) {
 	trans2DataType := operator.Trans2DataType(field)
 	fmt.Println(trans2DataType)
 }
+
+func TestArrayAndEnumType(t *testing.T) {
+	operator := NewPGOperator()
+	cases := map[string]string{
+		"_int4":             "integer[]",
+		"varchar(20)[]":     "varchar(20)[]",
+		"enum('a','bcd')":   "varchar(3)",
+		"jsonb":             "jsonb",
+		"geography":         "geography",
+		"character varying": "varchar",
+	}
+	for dataType, expected := range cases {
+		if actual := operator.Trans2DataType(operator.Trans2CommonField(dataType)); actual != expected {
+			t.Errorf("%s => %s, expected %s", dataType, actual, expected)
+		}
+	}
+}
+
+func TestCollateOption(t *testing.T) {
+	operator := NewPGOperator()
+	field := operator.Trans2CommonField("varchar(20)")
+	field.Collation, field.ISNullable = "C", false
+	if option := getColumnOption(field); option != ` collate "C" not null` {
+		t.Errorf("column option = %q", option)
+	}
+}
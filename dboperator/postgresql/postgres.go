@@ -17,6 +17,9 @@ func NewPGOperator() dboperator.IOperator {
 
 type PGOperator struct{}
 
+// identifier 标识符规则，见 dboperator.GetIdentifierPolicy
+var identifier = dboperator.GetIdentifierPolicy(dbx.DBTypePostgres)
+
 func (p PGOperator) GetDB(name string) (*dbx.DBWrapper, error) {
 	return dbx.GetDB(name)
 }
@@ -45,15 +48,19 @@ func (p PGOperator) GetDataBySQL(ctx context.Context, dbName, sqlStatement strin
 	return
 }
 
+func (p PGOperator) GetSchema(ctx context.Context, dbName, schemaName string) (schema *dboperator.Schema, err error) {
+	return dboperator.InspectSchema(ctx, p, dbx.DBTypePostgres, dbName, schemaName)
+}
+
 func (p PGOperator) GetTableData(ctx context.Context, dbName, schemaName, tableName string, pageInfo *dboperator.Pagination) (rows []map[string]interface{}, err error) {
 	rows = make([]map[string]interface{}, 0)
 	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
-	queryTable := fmt.Sprintf("\"%s\"", tableName)
+	queryTable := identifier.Quote(tableName)
 	if schemaName != "" {
-		queryTable = fmt.Sprintf("\"%s\".\"%s\"", schemaName, tableName)
+		queryTable = identifier.Quote(schemaName) + "." + queryTable
 	}
 	var count int64
 	tx := db.DB.WithContext(ctx).
@@ -86,9 +93,12 @@ func (p PGOperator) GetTablesUnderSchema(ctx context.Context, dbName string, sch
 	err = db.DB.WithContext(ctx).
 		Raw("SELECT distinct tb.schemaname as table_schema, " +
 			"tb.tablename as table_name, " +
-			"d.description as comments " +
+			"d.description as comments, " +
+			"c.reltuples::bigint as row_count, " +
+			"pg_encoding_to_char((SELECT encoding FROM pg_database WHERE datname = current_database())) as charset " +
 			"FROM pg_tables tb " +
-			"JOIN pg_class c ON c.relname = tb.tablename " +
+			"JOIN pg_namespace n ON n.nspname = tb.schemaname " +
+			"JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = tb.tablename " +
 			"LEFT JOIN pg_description d ON d.objoid = c.oid AND d.objsubid = '0' " +
 			"WHERE schemaname in (" + strings.Join(schemas, ",") + ") " +
 			"AND tablename NOT LIKE 'pg%' " +
@@ -106,6 +116,8 @@ func (p PGOperator) GetTablesUnderSchema(ctx context.Context, dbName string, sch
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Charset:   row.Charset,
 				}},
 			}
 		} else {
@@ -113,6 +125,8 @@ func (p PGOperator) GetTablesUnderSchema(ctx context.Context, dbName string, sch
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Charset:   row.Charset,
 				})
 		}
 	}
@@ -133,9 +147,11 @@ func (p PGOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbTabl
 	err = db.DB.WithContext(ctx).
 		Raw("SELECT  distinct  tb.schemaname as table_schema, " +
 			"tb.tablename as table_name, " +
-			"d.description as comments " +
+			"d.description as comments, " +
+			"c.reltuples::bigint as row_count " +
 			"FROM pg_tables tb " +
-			"JOIN pg_class c ON c.relname = tb.tablename " +
+			"JOIN pg_namespace n ON n.nspname = tb.schemaname " +
+			"JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = tb.tablename " +
 			"LEFT JOIN pg_description d ON d.objoid = c.oid AND d.objsubid = '0' " +
 			"WHERE schemaname <> 'information_schema' " +
 			"AND tablename NOT LIKE 'pg%' " +
@@ -153,6 +169,7 @@ func (p PGOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbTabl
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				}},
 			}
 		} else {
@@ -160,6 +177,7 @@ func (p PGOperator) GetTablesUnderDB(ctx context.Context, dbName string) (dbTabl
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				})
 		}
 	}
@@ -188,8 +206,16 @@ func (p PGOperator) GetColumns(ctx context.Context, dbName string) (dbTableColMa
 			"		ic.udt_name || '(' || ic.numeric_precision || ',' || ic.numeric_scale || ')'" +
 			"	when ic.udt_name='timestamp' and ic.datetime_precision <> 0 then" +
 			"		ic.udt_name || '(' || ic.datetime_precision || ')'" +
-			"	else ic.udt_name" +
+			"	else coalesce((select 'enum(' || string_agg(quote_literal(e.enumlabel), ',' order by e.enumsortorder) || ')' " +
+			"		from pg_type t join pg_namespace tn on tn.oid = t.typnamespace join pg_enum e on e.enumtypid = t.oid " +
+			"		where t.typname = ic.udt_name and tn.nspname = ic.udt_schema), ic.udt_name) " +
 			"end as data_type," +
+			"case" +
+			"	when ic.is_nullable = 'YES' then true" +
+			"	else false " +
+			"end as is_nullable," +
+			"ic.column_default as column_default," +
+			"ic.ordinal_position as ordinal_position," +
 			"d.description as comments " +
 			"from " +
 			"information_schema.columns ic " +
@@ -215,9 +241,12 @@ func (p PGOperator) GetColumns(ctx context.Context, dbName string) (dbTableColMa
 				row.TableName: {
 					TableName: row.TableName,
 					ColumnInfoList: []*dboperator.ColumnInfo{{
-						ColumnName: row.ColumnName,
-						Comment:    row.Comments,
-						DataType:   row.DataType,
+						ColumnName:      row.ColumnName,
+						Comment:         row.Comments,
+						DataType:        row.DataType,
+						IsNullable:      row.IsNullable,
+						DefaultValue:    row.ColumnDefault,
+						OrdinalPosition: row.OrdinalPosition,
 					}},
 				},
 			}
@@ -225,19 +254,31 @@ func (p PGOperator) GetColumns(ctx context.Context, dbName string) (dbTableColMa
 			dbTableColInfoMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    row.ColumnDefault,
+					OrdinalPosition: row.OrdinalPosition,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    row.ColumnDefault,
+				OrdinalPosition: row.OrdinalPosition,
 			})
 		}
 	}
+	for schemaName, tableColMap := range dbTableColMap {
+		err = p.fillAutoIncrement(ctx, db, schemaName, tableColMap)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -268,7 +309,9 @@ func (p PGOperator) GetColumnsUnderTables(ctx context.Context, dbName, logicDBNa
 			"		ic.udt_name || '(' || ic.numeric_precision || ',' || ic.numeric_scale || ')' "+
 			"	when ic.udt_name='timestamp' and ic.datetime_precision <> 0 then "+
 			"		ic.udt_name || '(' || ic.datetime_precision || ')' "+
-			"	else ic.udt_name "+
+			"	else coalesce((select 'enum(' || string_agg(quote_literal(e.enumlabel), ',' order by e.enumsortorder) || ')' "+
+			"		from pg_type t join pg_namespace tn on tn.oid = t.typnamespace join pg_enum e on e.enumtypid = t.oid "+
+			"		where t.typname = ic.udt_name and tn.nspname = ic.udt_schema), ic.udt_name) "+
 			"end as data_type,d.description as comments, "+
 			"case "+
 			" 	when ic.is_nullable = 'YES' then "+
@@ -276,8 +319,9 @@ func (p PGOperator) GetColumnsUnderTables(ctx context.Context, dbName, logicDBNa
 			" 	else "+
 			"   	false "+
 			"end as is_nullable,"+
-			"d.description as comments,"+
-			"ic.ordinal_position "+
+			"ic.column_default as column_default,"+
+			"ic.ordinal_position, "+
+			"coalesce(ic.collation_name, '') as collation "+
 			"from "+
 			"information_schema.columns ic "+
 			"JOIN pg_class c ON c.relname = ic.table_name "+
@@ -300,19 +344,96 @@ func (p PGOperator) GetColumnsUnderTables(ctx context.Context, dbName, logicDBNa
 			tableColMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    row.ColumnDefault,
+					OrdinalPosition: row.OrdinalPosition,
+					Collation:       row.Collation,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    row.ColumnDefault,
+				OrdinalPosition: row.OrdinalPosition,
+				Collation:       row.Collation,
 			})
 		}
 	}
+	err = p.fillAutoIncrement(ctx, db, logicDBName, tableColMap)
+	return
+}
+
+// fillAutoIncrement 标记identity及serial(以序列作默认值)列，下一个值取自关联序列
+func (p PGOperator) fillAutoIncrement(ctx context.Context, db *dbx.DBWrapper, schemaName string, tableColMap map[string]*dboperator.TableColInfo) (err error) {
+	if len(tableColMap) == 0 {
+		return
+	}
+	autoIncrements := make([]*dboperator.TableAutoIncrement, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT c.table_name AS table_name, c.column_name AS column_name,
+       COALESCE(pg_sequence_last_value(COALESCE(
+           pg_get_serial_sequence(quote_ident(c.table_schema) || '.' || quote_ident(c.table_name), c.column_name),
+           substring(c.column_default from 'nextval\(''(.+)''::regclass\)'))::regclass), 0) + 1 AS next_value
+  FROM information_schema.columns c
+ WHERE c.table_schema = ? AND c.table_name IN ?
+   AND (c.is_identity = 'YES' OR c.column_default LIKE 'nextval(%')`, schemaName, utils.SortedKeys(tableColMap)).
+		Scan(&autoIncrements).Error
+	if err != nil {
+		return
+	}
+	dboperator.FillAutoIncrement(tableColMap, autoIncrements)
+	return
+}
+
+func (p PGOperator) GetViewsUnderSchema(ctx context.Context, dbName string, schemas []string) (schemaViewMap map[string][]*dboperator.ViewInfo, err error) {
+	if dbName == "" || len(schemas) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	gormDBViews := make([]*dboperator.GormDBView, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT schemaname AS schema_name, viewname AS view_name, definition, false AS is_materialized
+  FROM pg_views WHERE schemaname IN ?
+ UNION ALL
+SELECT schemaname AS schema_name, matviewname AS view_name, definition, true AS is_materialized
+  FROM pg_matviews WHERE schemaname IN ?
+ ORDER BY schema_name, view_name`, schemas, schemas).
+		Scan(&gormDBViews).Error
+	if err != nil {
+		return
+	}
+	schemaViewMap = dboperator.GroupViews(gormDBViews)
+	return
+}
+
+func (p PGOperator) CreateViews(ctx context.Context, dbName, schemaName string, views []*dboperator.ViewInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, p.RenderViews(ctx, schemaName, views))
+}
+
+func (p PGOperator) RenderViews(ctx context.Context, schemaName string, views []*dboperator.ViewInfo) (statements []string) {
+	for _, view := range views {
+		viewFullName := identifier.QualifiedName(schemaName, view.ViewName)
+		if view.IsMaterialized {
+			statements = append(statements, fmt.Sprintf("create materialized view if not exists %s as %s", viewFullName, identifier.QuoteExpression(view.Definition)))
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("create or replace view %s as %s", viewFullName, identifier.QuoteExpression(view.Definition)))
+	}
 	return
 }
 
@@ -328,11 +449,11 @@ func (p PGOperator) CreateSchema(ctx context.Context, dbName, schemaName, commen
 	if err != nil {
 		return
 	}
-	err = db.DB.WithContext(ctx).Exec("create schema if not exists " + utils.QuotaName(schemaName)).Error
+	err = db.DB.WithContext(ctx).Exec("create schema if not exists " + identifier.Name(schemaName)).Error
 	if err != nil {
 		return
 	}
-	commentStr := fmt.Sprintf("comment on schema %s is '%s'", utils.QuotaName(schemaName), commentInfo)
+	commentStr := fmt.Sprintf("comment on schema %s is '%s'", identifier.Name(schemaName), commentInfo)
 	err = db.DB.WithContext(ctx).Exec(commentStr).Error
 	if err != nil {
 		return
@@ -413,7 +534,41 @@ func (p PGOperator) GetTableUniqueKeys(ctx context.Context, dbName string, schem
 	return
 }
 
-func (p PGOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field) (ddlSQL string, err error) {
+func (p PGOperator) GetTableIndexes(ctx context.Context, dbName string, schemaName string, tables []string) (indexInfo map[string][]*dboperator.IndexInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableIndexColumns := make([]*dboperator.TableIndexColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT n.nspname AS schema_name, t.relname AS table_name, i.relname AS index_name,
+       ix.indisunique AS is_unique, k.ord AS column_position,
+       COALESCE(a.attname, '') AS column_name,
+       CASE WHEN k.attnum = 0 THEN pg_get_indexdef(ix.indexrelid, k.ord::int, true) ELSE '' END AS expression,
+       (ix.indoption[k.ord - 1] & 1) = 1 AS is_desc,
+       COALESCE(pg_get_expr(ix.indpred, ix.indrelid), '') AS filter
+  FROM pg_index ix
+  JOIN pg_class t ON t.oid = ix.indrelid
+  JOIN pg_class i ON i.oid = ix.indexrelid
+  JOIN pg_namespace n ON n.oid = t.relnamespace
+ CROSS JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+  LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+ WHERE NOT ix.indisprimary
+   AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid AND con.contype IN ('p', 'u', 'x'))
+   AND k.ord <= ix.indnkeyatts
+   AND n.nspname = ? AND t.relname IN ?
+ ORDER BY t.relname, i.relname, k.ord`, schemaName, tables).
+		Scan(&tableIndexColumns).Error
+	if err != nil {
+		return
+	}
+	indexInfo = dboperator.GroupIndexColumns(tableIndexColumns)
+	return
+}
+
+func (p PGOperator) CreateIndexes(ctx context.Context, dbName, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (ddlSQL string, err error) {
 	if dbName == "" {
 		err = errors.New("empty dnName")
 		return
@@ -422,25 +577,120 @@ func (p PGOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, p
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, p.RenderIndexes(ctx, schemaName, indexesMap))
+}
+
+func (p PGOperator) RenderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (statements []string) {
+	indexNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.IndexNames(indexesMap))
+	for _, tableName := range utils.SortedKeys(indexesMap) {
+		for _, index := range indexesMap[tableName] {
+			columns := make([]string, 0, len(index.Columns))
+			for _, column := range index.Columns {
+				columnStr := utils.IsTrueOrNot(column.Expression != "", "("+column.Expression+")", identifier.Name(column.ColumnName))
+				columns = append(columns, columnStr+utils.IsTrueOrNot(column.IsDesc, " desc", ""))
+			}
+			indexName := indexNames.Get(tableName, index.IndexName)
+			statements = append(statements, fmt.Sprintf("create %sindex if not exists %s on %s (%s)%s;",
+				utils.IsTrueOrNot(index.IsUnique, "unique ", ""), identifier.Name(indexName),
+				identifier.QualifiedName(schemaName, tableName), strings.Join(columns, ","),
+				utils.IsTrueOrNot(index.Filter != "", " where "+index.Filter, "")))
+		}
+	}
+	return
+}
 
-	//ddlSQL := ""
+func (p PGOperator) GetTableForeignKeys(ctx context.Context, dbName string, schemaName string, tables []string) (foreignKeyInfo map[string][]*dboperator.ForeignKeyInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableForeignKeyColumns := make([]*dboperator.TableForeignKeyColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT n.nspname AS schema_name, t.relname AS table_name, con.conname AS constraint_name,
+       a.attname AS column_name, rn.nspname AS ref_schema_name, rt.relname AS ref_table_name,
+       ra.attname AS ref_column_name, k.ord AS column_position,
+       CASE con.confdeltype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL'
+            WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END AS on_delete,
+       CASE con.confupdtype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL'
+            WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END AS on_update
+  FROM pg_constraint con
+  JOIN pg_class t ON t.oid = con.conrelid
+  JOIN pg_namespace n ON n.oid = t.relnamespace
+  JOIN pg_class rt ON rt.oid = con.confrelid
+  JOIN pg_namespace rn ON rn.oid = rt.relnamespace
+ CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, ref_attnum, ord)
+  JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+  JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.ref_attnum
+ WHERE con.contype = 'f' AND n.nspname = ? AND t.relname IN ?
+ ORDER BY t.relname, con.conname, k.ord`, schemaName, tables).
+		Scan(&tableForeignKeyColumns).Error
+	if err != nil {
+		return
+	}
+	foreignKeyInfo = dboperator.GroupForeignKeyColumns(tableForeignKeyColumns)
+	return
+}
+
+func (p PGOperator) GetTableCheckConstraints(ctx context.Context, dbName string, schemaName string, tables []string) (checkInfo map[string][]*dboperator.CheckInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableCheckConstraints := make([]*dboperator.TableCheckConstraint, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT n.nspname AS schema_name, t.relname AS table_name, con.conname AS constraint_name,
+       pg_get_expr(con.conbin, con.conrelid) AS expression
+  FROM pg_constraint con
+  JOIN pg_class t ON t.oid = con.conrelid
+  JOIN pg_namespace n ON n.oid = t.relnamespace
+ WHERE con.contype = 'c' AND n.nspname = ? AND t.relname IN ?
+ ORDER BY t.relname, con.conname`, schemaName, tables).
+		Scan(&tableCheckConstraints).Error
+	if err != nil {
+		return
+	}
+	checkInfo = dboperator.GroupCheckConstraints(tableCheckConstraints)
+	return
+}
+
+func (p PGOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, p.RenderDDL(ctx, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap))
+}
+
+func (p PGOperator) RenderDDL(ctx context.Context, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (statements []string) {
 	ddlTemplate := `
 create table if not exists %s (
 	%s 
 );`
-	for tableName, fields := range tableFieldsMap {
+	uniqueNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.UniqueKeyNames(uniqueKeysMap))
+	checkNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.CheckNames(checksMap))
+	foreignKeyNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.ForeignKeyNames(foreignKeysMap))
+	for _, tableName := range dboperator.TableCreationOrder(ctx, tableFieldsMap, foreignKeysMap) {
+		fields := tableFieldsMap[tableName]
 		var includeField string
 		for _, field := range fields {
 			if field == nil {
 				continue
 			}
 			dataType := p.Trans2DataType(field)
-			includeField += fmt.Sprintf("	%s %s,", utils.QuotaName(field.ColumnName), dataType) + fmt.Sprintln()
+			includeField += fmt.Sprintf("	%s %s%s,", identifier.Name(field.ColumnName), dataType, getColumnOption(field)) + fmt.Sprintln()
 		}
 		if len(primaryKeysMap) > 0 {
-			keys := primaryKeysMap[tableName]
-			for i, key := range keys {
-				keys[i] = utils.QuotaName(key)
+			keys := make([]string, 0, len(primaryKeysMap[tableName]))
+			for _, key := range primaryKeysMap[tableName] {
+				keys = append(keys, identifier.Name(key))
 			}
 			if len(keys) > 0 {
 				includeField += fmt.Sprintf("	primary key (%s),", strings.Join(keys, ",")) + fmt.Sprintln()
@@ -448,24 +698,129 @@ create table if not exists %s (
 		}
 		if len(uniqueKeysMap) > 0 {
 			uniqueKeys := uniqueKeysMap[tableName]
-			for _, columns := range uniqueKeys {
-				for i, column := range columns {
-					columns[i] = utils.QuotaName(column)
+			for _, uniqueName := range utils.SortedKeys(uniqueKeys) {
+				uniqueColumns := uniqueKeys[uniqueName]
+				columns := make([]string, 0, len(uniqueColumns))
+				for _, column := range uniqueColumns {
+					columns = append(columns, identifier.Name(column))
+				}
+				if uniqueName == "" {
+					includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+					continue
 				}
-				includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+				constraintName := uniqueNames.Get(tableName, uniqueName)
+				includeField += fmt.Sprintf("	constraint %s unique (%s),", identifier.Name(constraintName), strings.Join(columns, ",")) + fmt.Sprintln()
 			}
 		}
 
+		for _, check := range checksMap[tableName] {
+			includeField += fmt.Sprintf("	constraint %s check (%s),", identifier.Name(checkNames.Get(tableName, check.ConstraintName)), identifier.QuoteExpression(check.Expression)) + fmt.Sprintln()
+		}
+
 		includeField = strings.TrimSpace(includeField)
 		includeField = strings.Trim(includeField, ",")
 
-		ddlStr := fmt.Sprintf(ddlTemplate, fmt.Sprintf("%s.%s", utils.QuotaName(schemaName), utils.QuotaName(tableName)), includeField)
-		ddlSQL += ddlStr + fmt.Sprintln()
+		tableFullName := identifier.QualifiedName(schemaName, tableName)
+		statements = append(statements, fmt.Sprintf(ddlTemplate, tableFullName, includeField))
+		statements = append(statements, getCommentDDL(tableFullName, tableCommentMap[tableName], fields)...)
 	}
+	for _, tableName := range utils.SortedKeys(foreignKeysMap) {
+		if _, ok := tableFieldsMap[tableName]; !ok {
+			continue
+		}
+		for _, foreignKey := range foreignKeysMap[tableName] {
+			statements = append(statements, getForeignKeyDDL(schemaName, tableName, foreignKeyNames.Get(tableName, foreignKey.ConstraintName), foreignKey))
+		}
+	}
+	return
+}
 
-	err = db.DB.WithContext(ctx).Exec(ddlSQL).Error
+func (p PGOperator) ExecuteAlterations(ctx context.Context, dbName, schemaName string, alterations []*dboperator.TableAlteration) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, p.RenderAlterations(ctx, schemaName, alterations))
+}
+
+// RenderAlterations 新增列时附带列注释；主键名由系统生成，删除时以匿名块查询
+func (p PGOperator) RenderAlterations(ctx context.Context, schemaName string, alterations []*dboperator.TableAlteration) (statements []string) {
+	for _, alteration := range alterations {
+		tableFullName := identifier.QualifiedQuote(schemaName, alteration.TableName)
+		template := "alter table " + tableFullName + " %s;"
+		switch alteration.Action {
+		case dboperator.AlterAddColumn:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add column %s %s%s", identifier.Quote(alteration.Field.ColumnName),
+				p.Trans2DataType(alteration.Field), getColumnOption(alteration.Field))))
+			statements = append(statements, getCommentDDL(tableFullName, "", []*dboperator.Field{alteration.Field})...)
+		case dboperator.AlterDropColumn:
+			statements = append(statements, fmt.Sprintf(template, "drop column "+identifier.Quote(alteration.ColumnName)))
+		case dboperator.AlterModifyColumn:
+			columnName := identifier.Quote(alteration.Field.ColumnName)
+			actions := make([]string, 0, 2)
+			if alteration.TypeChanged {
+				dataType := p.Trans2DataType(alteration.Field)
+				actions = append(actions, fmt.Sprintf("alter column %s type %s using %s::%s", columnName, dataType, columnName, dataType))
+			}
+			if alteration.NullableChanged {
+				actions = append(actions, fmt.Sprintf("alter column %s %s not null", columnName, utils.IsTrueOrNot(alteration.Field.ISNullable, "drop", "set")))
+			}
+			statements = append(statements, fmt.Sprintf(template, strings.Join(actions, ", ")))
+		case dboperator.AlterAddPrimaryKey:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add primary key (%s)", identifier.QuoteList(alteration.Columns))))
+		case dboperator.AlterDropPrimaryKey:
+			statements = append(statements, fmt.Sprintf(`do $$ declare pk_name text; begin
+select conname into pk_name from pg_constraint where contype = 'p' and conrelid = %s::regclass;
+if pk_name is not null then execute 'alter table ' || %s || ' drop constraint ' || quote_ident(pk_name); end if;
+end $$;`, utils.QuotaString(tableFullName), utils.QuotaString(tableFullName)))
+		case dboperator.AlterAddUnique:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add unique (%s)", identifier.QuoteList(alteration.Columns))))
+		case dboperator.AlterDropUnique:
+			statements = append(statements, fmt.Sprintf(template, "drop constraint "+identifier.Quote(alteration.ConstraintName)))
+		}
+	}
+	return
+}
+
+// getForeignKeyDDL 生成添加外键约束语句，constraintName 为分配的外键名
+func getForeignKeyDDL(schemaName, tableName, constraintName string, foreignKey *dboperator.ForeignKeyInfo) string {
+	columns := make([]string, 0, len(foreignKey.Columns))
+	for _, column := range foreignKey.Columns {
+		columns = append(columns, identifier.Name(column))
+	}
+	refColumns := make([]string, 0, len(foreignKey.RefColumns))
+	for _, column := range foreignKey.RefColumns {
+		refColumns = append(refColumns, identifier.Name(column))
+	}
+	tableFullName := identifier.QualifiedName(schemaName, tableName)
+	foreignKeySQL := fmt.Sprintf("alter table %s add constraint %s foreign key (%s) references %s (%s)",
+		tableFullName, identifier.Name(constraintName), strings.Join(columns, ","),
+		identifier.QualifiedName(schemaName, foreignKey.RefTableName), strings.Join(refColumns, ","))
+	if foreignKey.OnDelete != "" {
+		foreignKeySQL += " on delete " + strings.ToLower(foreignKey.OnDelete)
+	}
+	if foreignKey.OnUpdate != "" {
+		foreignKeySQL += " on update " + strings.ToLower(foreignKey.OnUpdate)
+	}
+	// 约束不支持 if not exists，以匿名块判断是否已存在
+	return fmt.Sprintf("do $$ begin if not exists (select 1 from pg_constraint where conname = %s and conrelid = %s::regclass) then %s; end if; end $$;",
+		utils.QuotaString(identifier.Fold(constraintName)), utils.QuotaString(tableFullName), foreignKeySQL)
+}
+
+// getCommentDDL 生成表及字段注释语句
+func getCommentDDL(tableFullName, tableComment string, fields []*dboperator.Field) (commentSQLs []string) {
+	if tableComment != "" {
+		commentSQLs = append(commentSQLs, fmt.Sprintf("comment on table %s is %s;", tableFullName, utils.QuotaString(tableComment)))
+	}
+	for _, field := range fields {
+		if field == nil || field.Comment == "" {
+			continue
+		}
+		commentSQLs = append(commentSQLs, fmt.Sprintf("comment on column %s.%s is %s;", tableFullName, identifier.Name(field.ColumnName), utils.QuotaString(field.Comment)))
+	}
 	return
 }
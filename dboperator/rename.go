@@ -0,0 +1,223 @@
+package dboperator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/bytedance/sonic"
+	"gopkg.in/yaml.v3"
+)
+
+// RenameCase 重命名时的大小写转换
+type RenameCase string
+
+const (
+	RenameCasePreserve RenameCase = ""      // 保持原样
+	RenameCaseLower    RenameCase = "lower" // 转小写
+	RenameCaseUpper    RenameCase = "upper" // 转大写
+	RenameCaseSnake    RenameCase = "snake" // 驼峰转小写下划线，如 OrderItemID 为 order_item_id
+)
+
+// RenameSpec 表名、列名重命名规则，名称均为源库名称
+type RenameSpec struct {
+	Tables  RenameRules `json:"tables" yaml:"tables"`
+	Columns RenameRules `json:"columns" yaml:"columns"`
+}
+
+// RenameRules 名称先按 Mapping 精确映射，命中即止；未命中时依次按 Rules 改写，再按 Case 转换大小写
+type RenameRules struct {
+	Mapping map[string]string `json:"mapping" yaml:"mapping"` // 精确映射，列名的键可写为 表名.列名 以限定源表
+	Rules   []*RenameRule     `json:"rules" yaml:"rules"`
+	Case    RenameCase        `json:"case" yaml:"case"`
+}
+
+// RenameRule 正则改写规则，名称中所有匹配处替换为 Replacement，可引用分组，如 ${1}
+type RenameRule struct {
+	Pattern     string `json:"pattern" yaml:"pattern"`
+	Replacement string `json:"replacement" yaml:"replacement"`
+
+	reg *regexp.Regexp
+}
+
+// LoadRenameSpec 读取重命名规则文件，.json 按json解析，其余按yaml解析
+func LoadRenameSpec(filePath string) (spec *RenameSpec, err error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return
+	}
+	spec = &RenameSpec{}
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		err = sonic.Unmarshal(content, spec)
+	} else {
+		err = yaml.Unmarshal(content, spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = spec.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Compile 校验规则并编译正则，直接构造规则时需先调用，datasource 中使用前会自动调用
+func (s *RenameSpec) Compile() (err error) {
+	for _, kind := range []string{"table", "column"} {
+		rules := &s.Tables
+		if kind == "column" {
+			rules = &s.Columns
+		}
+		switch rules.Case {
+		case RenameCasePreserve, RenameCaseLower, RenameCaseUpper, RenameCaseSnake:
+		default:
+			return fmt.Errorf("unsupported %s rename case %s", kind, rules.Case)
+		}
+		for i, rule := range rules.Rules {
+			if rule == nil || rule.Pattern == "" {
+				return fmt.Errorf("%s rename rule #%d has no pattern", kind, i+1)
+			}
+			rule.reg, err = regexp.Compile(rule.Pattern)
+			if err != nil {
+				return fmt.Errorf("%s rename rule #%d: %w", kind, i+1, err)
+			}
+		}
+	}
+	return
+}
+
+// TableName 源表名对应的新表名
+func (s *RenameSpec) TableName(tableName string) string {
+	if s == nil {
+		return tableName
+	}
+	if name, ok := s.Tables.Mapping[tableName]; ok {
+		return name
+	}
+	return s.Tables.rewrite(tableName)
+}
+
+// ColumnName 源表 tableName 中源列名对应的新列名，表名.列名 的精确映射优先于仅列名的映射
+func (s *RenameSpec) ColumnName(tableName, columnName string) string {
+	if s == nil {
+		return columnName
+	}
+	if name, ok := s.Columns.Mapping[tableName+"."+columnName]; ok {
+		return name
+	}
+	if name, ok := s.Columns.Mapping[columnName]; ok {
+		return name
+	}
+	return s.Columns.rewrite(columnName)
+}
+
+// RenameExpression 将已归一化表达式(见 NormalizeCheckExpression)中源表 tableName 的列名替换为新列名
+func (s *RenameSpec) RenameExpression(tableName, expression string) string {
+	if s == nil {
+		return expression
+	}
+	return RewriteIdentifiers(expression, func(name string) string {
+		return `"` + strings.ReplaceAll(s.ColumnName(tableName, name), `"`, `""`) + `"`
+	})
+}
+
+// RenameViewDefinition 改写已归一化的视图查询(见 NormalizeViewDefinition)：targetSchema 限定的表及作为限定名的表中，
+// 属于 tableNames 的改为新表名，被引用的视图不在 tableNames 中，保持原名；其余标识符(列名、别名)按列名规则改写，
+// 表名.列名 的映射取 tableNames 中首个命中的表
+func (s *RenameSpec) RenameViewDefinition(definition, targetSchema string, tableNames []string) string {
+	if s == nil {
+		return definition
+	}
+	tokens, err := tokenizeSQL(definition)
+	if err != nil {
+		return definition
+	}
+	tables := make(map[string]bool)
+	for _, tableName := range tableNames {
+		tables[tableName] = true
+	}
+	isQualifier := func(i int) bool {
+		return i+2 < len(tokens) && tokens[i+1].value == "." && tokens[i+2].kind == sqlTokenIdent
+	}
+	schemaQualified := func(i int) bool {
+		return i >= 2 && tokens[i-1].value == "." && tokens[i-2].kind == sqlTokenIdent && tokens[i-2].value == targetSchema
+	}
+	// 被引用的表及视图，均以目标模式限定
+	objects := make(map[string]bool)
+	for i, token := range tokens {
+		if token.kind == sqlTokenIdent && schemaQualified(i) {
+			objects[token.value] = true
+		}
+	}
+	renamed := make([]string, len(tokens))
+	for i, token := range tokens {
+		if token.kind != sqlTokenIdent {
+			continue
+		}
+		switch {
+		case token.value == targetSchema && isQualifier(i) && !schemaQualified(i):
+			renamed[i] = token.value
+		case schemaQualified(i) || objects[token.value] && isQualifier(i):
+			renamed[i] = token.value
+			if tables[token.value] {
+				renamed[i] = s.TableName(token.value)
+			}
+		default:
+			renamed[i] = s.viewColumnName(tableNames, token.value)
+		}
+	}
+	for i, name := range renamed {
+		if tokens[i].kind == sqlTokenIdent {
+			tokens[i].value = name
+		}
+	}
+	return renderSQLTokens(tokens, viewFunctions)
+}
+
+// viewColumnName 视图中的列名或别名对应的新名称
+func (s *RenameSpec) viewColumnName(tableNames []string, name string) string {
+	for _, tableName := range tableNames {
+		if _, ok := s.Columns.Mapping[tableName+"."+name]; ok {
+			return s.ColumnName(tableName, name)
+		}
+	}
+	return s.ColumnName("", name)
+}
+
+func (r *RenameRules) rewrite(name string) string {
+	for _, rule := range r.Rules {
+		if rule.reg != nil {
+			name = rule.reg.ReplaceAllString(name, rule.Replacement)
+		}
+	}
+	switch r.Case {
+	case RenameCaseLower:
+		return strings.ToLower(name)
+	case RenameCaseUpper:
+		return strings.ToUpper(name)
+	case RenameCaseSnake:
+		return SnakeCase(name)
+	}
+	return name
+}
+
+// SnakeCase 驼峰转小写下划线，连续大写视为缩写，如 HTTPServerID 为 http_server_id，已有的下划线保留
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
@@ -0,0 +1,82 @@
+package dboperator
+
+import (
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestRenameSpec(t *testing.T) {
+	spec := &RenameSpec{
+		Tables: RenameRules{
+			Mapping: map[string]string{"T_LEGACY": "orders"},
+			Rules:   []*RenameRule{{Pattern: `^T_`, Replacement: ""}},
+			Case:    RenameCaseSnake,
+		},
+		Columns: RenameRules{
+			Mapping: map[string]string{"T_USER.NAME": "user_name", "ID": "id"},
+			Case:    RenameCaseLower,
+		},
+	}
+	if err := spec.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tables := map[string]string{"T_LEGACY": "orders", "T_OrderItem": "order_item", "T_HTTPLog": "http_log", "plain": "plain"}
+	for name, expected := range tables {
+		if renamed := spec.TableName(name); renamed != expected {
+			t.Errorf("TableName(%s) = %s, expected %s", name, renamed, expected)
+		}
+	}
+	columns := [][3]string{
+		{"T_USER", "NAME", "user_name"},
+		{"T_ROLE", "NAME", "name"},
+		{"T_ROLE", "ID", "id"},
+		{"T_ROLE", "CreatedAt", "createdat"},
+	}
+	for _, column := range columns {
+		if renamed := spec.ColumnName(column[0], column[1]); renamed != column[2] {
+			t.Errorf("ColumnName(%s, %s) = %s, expected %s", column[0], column[1], renamed, column[2])
+		}
+	}
+
+	expression := spec.RenameExpression("T_USER", `"NAME" <> 'NAME' AND "AGE" > 0`)
+	if expected := `"user_name" <> 'NAME' AND "age" > 0`; expression != expected {
+		t.Errorf("RenameExpression = %s, expected %s", expression, expected)
+	}
+
+	var empty *RenameSpec
+	if renamed := empty.TableName("T_USER"); renamed != "T_USER" {
+		t.Errorf("nil spec renamed T_USER to %s", renamed)
+	}
+
+	invalid := &RenameSpec{Columns: RenameRules{Case: "camel"}}
+	if err := invalid.Compile(); err == nil {
+		t.Error("expected error for unsupported case")
+	}
+}
+
+func TestRenameViewDefinition(t *testing.T) {
+	spec := &RenameSpec{
+		Tables:  RenameRules{Rules: []*RenameRule{{Pattern: `^T_`, Replacement: ""}}, Case: RenameCaseSnake},
+		Columns: RenameRules{Mapping: map[string]string{"T_USER.NAME": "user_name"}, Case: RenameCaseLower},
+	}
+	if err := spec.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	definition, refTables, err := NormalizeViewDefinition(
+		"select u.NAME, u.AGE, v.TOTAL from T_USER u join V_TOTAL v on v.USER_ID = u.ID where T_USER.AGE > 18",
+		dbx.DBTypeMySQL, "legacy", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refTables) != 2 {
+		t.Fatalf("unexpected refTables %v", refTables)
+	}
+	renamed := spec.RenameViewDefinition(definition, "app", []string{"T_USER"})
+	expected := `SELECT "u"."user_name", "u"."age", "v"."total" FROM "app"."user" "u" JOIN "app"."V_TOTAL" "v" ` +
+		`ON "v"."user_id" = "u"."id" WHERE "user"."age" > 18`
+	if renamed != expected {
+		t.Errorf("RenameViewDefinition = %s, expected %s", renamed, expected)
+	}
+}
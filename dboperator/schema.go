@@ -0,0 +1,315 @@
+package dboperator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// SchemaVersion 模式快照的格式版本，结构发生不兼容变更时递增
+const SchemaVersion = 1
+
+// ConstraintType 约束类型
+type ConstraintType string
+
+const (
+	ConstraintPrimaryKey ConstraintType = "primary_key"
+	ConstraintUnique     ConstraintType = "unique"
+	ConstraintForeignKey ConstraintType = "foreign_key"
+	ConstraintCheck      ConstraintType = "check"
+)
+
+// Schema 模式快照，包含模式下全部表的列、约束、索引及视图。
+// 字段类型、默认值、表达式均为 DBType 数据库的写法，可经 ITransfer.Trans2CommonField 转为通用字段
+type Schema struct {
+	Version int        `json:"version" yaml:"version"`
+	DBType  dbx.DBType `json:"db_type" yaml:"db_type"`
+	Name    string     `json:"name" yaml:"name"`
+	Tables  []*Table   `json:"tables" yaml:"tables"` // 按表名排序
+	Views   []*View    `json:"views,omitempty" yaml:"views,omitempty"`
+}
+
+// Table 表
+type Table struct {
+	Name        string        `json:"name" yaml:"name"`
+	Comment     string        `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Charset     string        `json:"charset,omitempty" yaml:"charset,omitempty"`         // 默认字符集
+	Collation   string        `json:"collation,omitempty" yaml:"collation,omitempty"`     // 默认排序规则
+	Columns     []*Column     `json:"columns" yaml:"columns"`                             // 按字段序号排序
+	Constraints []*Constraint `json:"constraints,omitempty" yaml:"constraints,omitempty"` // 主键在前，其余按类型、名称排序
+	Indexes     []*Index      `json:"indexes,omitempty" yaml:"indexes,omitempty"`         // 普通索引，不含主键及唯一约束
+}
+
+// Column 列
+type Column struct {
+	Name          string `json:"name" yaml:"name"`
+	DataType      string `json:"data_type" yaml:"data_type"`
+	Nullable      bool   `json:"nullable" yaml:"nullable"`
+	Default       string `json:"default,omitempty" yaml:"default,omitempty"`
+	Comment       string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Position      int    `json:"position" yaml:"position"`
+	AutoIncrement bool   `json:"auto_increment,omitempty" yaml:"auto_increment,omitempty"`
+	NextValue     int64  `json:"next_value,omitempty" yaml:"next_value,omitempty"` // 自增列下一个值
+	Charset       string `json:"charset,omitempty" yaml:"charset,omitempty"`       // 字符集，为空时同表
+	Collation     string `json:"collation,omitempty" yaml:"collation,omitempty"`   // 排序规则，为空时同表
+}
+
+// Constraint 约束，Ref* 及 On* 仅外键有值，Expression 仅检查约束有值
+type Constraint struct {
+	Name       string         `json:"name,omitempty" yaml:"name,omitempty"` // 主键名部分数据库未返回，为空
+	Type       ConstraintType `json:"type" yaml:"type"`
+	Columns    []string       `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Expression string         `json:"expression,omitempty" yaml:"expression,omitempty"`
+	RefSchema  string         `json:"ref_schema,omitempty" yaml:"ref_schema,omitempty"`
+	RefTable   string         `json:"ref_table,omitempty" yaml:"ref_table,omitempty"`
+	RefColumns []string       `json:"ref_columns,omitempty" yaml:"ref_columns,omitempty"`
+	OnDelete   string         `json:"on_delete,omitempty" yaml:"on_delete,omitempty"`
+	OnUpdate   string         `json:"on_update,omitempty" yaml:"on_update,omitempty"`
+}
+
+// Index 索引
+type Index struct {
+	Name    string         `json:"name" yaml:"name"`
+	Unique  bool           `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Columns []*IndexColumn `json:"columns" yaml:"columns"`
+	Filter  string         `json:"filter,omitempty" yaml:"filter,omitempty"`
+}
+
+// View 视图
+type View struct {
+	Name         string `json:"name" yaml:"name"`
+	Definition   string `json:"definition" yaml:"definition"`
+	Materialized bool   `json:"materialized,omitempty" yaml:"materialized,omitempty"`
+}
+
+// InspectSchema 以 explorer 的各探查方法读取模式快照，供各数据库实现 GetSchema
+func InspectSchema(ctx context.Context, explorer IOperator, dbType dbx.DBType, dbName, schemaName string) (schema *Schema, err error) {
+	schema = &Schema{Version: SchemaVersion, DBType: dbType, Name: schemaName, Tables: make([]*Table, 0)}
+	tableMap, err := explorer.GetTablesUnderSchema(ctx, dbName, []string{schemaName})
+	if err != nil {
+		return nil, err
+	}
+	tableIndex := make(map[string]*Table)
+	tableNames := make([]string, 0)
+	for _, logicDBInfo := range tableMap {
+		for _, tableInfo := range logicDBInfo.TableInfoList {
+			table := &Table{Name: tableInfo.TableName, Comment: tableInfo.Comment, Charset: tableInfo.Charset, Collation: tableInfo.Collation,
+				Columns: make([]*Column, 0)}
+			tableIndex[table.Name] = table
+			tableNames = append(tableNames, table.Name)
+			schema.Tables = append(schema.Tables, table)
+		}
+	}
+	if len(tableNames) > 0 {
+		err = fillTables(ctx, explorer, dbName, schemaName, tableNames, tableIndex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	viewMap, err := explorer.GetViewsUnderSchema(ctx, dbName, []string{schemaName})
+	if err != nil {
+		return nil, err
+	}
+	for _, views := range viewMap {
+		for _, view := range views {
+			schema.Views = append(schema.Views, &View{Name: view.ViewName, Definition: view.Definition, Materialized: view.IsMaterialized})
+		}
+	}
+	schema.Sort()
+	return
+}
+
+func fillTables(ctx context.Context, explorer IOperator, dbName, schemaName string, tableNames []string, tableIndex map[string]*Table) (err error) {
+	tableColumns, err := explorer.GetColumnsUnderTables(ctx, dbName, schemaName, tableNames)
+	if err != nil {
+		return
+	}
+	for tableName, colInfo := range tableColumns {
+		table, ok := tableIndex[tableName]
+		if !ok {
+			continue
+		}
+		for _, columnInfo := range colInfo.ColumnInfoList {
+			table.Columns = append(table.Columns, &Column{
+				Name:          columnInfo.ColumnName,
+				DataType:      columnInfo.DataType,
+				Nullable:      columnInfo.IsNullable,
+				Default:       columnInfo.DefaultValue,
+				Comment:       columnInfo.Comment,
+				Position:      columnInfo.OrdinalPosition,
+				AutoIncrement: columnInfo.IsAutoIncrement,
+				NextValue:     columnInfo.NextValue,
+				Charset:       columnInfo.Charset,
+				Collation:     columnInfo.Collation,
+			})
+		}
+	}
+
+	primaryKeys, err := explorer.GetTablePrimeKeys(ctx, dbName, schemaName, tableNames)
+	if err != nil {
+		return
+	}
+	for tableName, columns := range primaryKeys {
+		if table, ok := tableIndex[tableName]; ok && len(columns) > 0 {
+			table.Constraints = append(table.Constraints, &Constraint{Type: ConstraintPrimaryKey, Columns: columns})
+		}
+	}
+	uniqueKeys, err := explorer.GetTableUniqueKeys(ctx, dbName, schemaName, tableNames)
+	if err != nil {
+		return
+	}
+	for tableName, constraints := range uniqueKeys {
+		if table, ok := tableIndex[tableName]; ok {
+			for constraintName, columns := range constraints {
+				table.Constraints = append(table.Constraints, &Constraint{Name: constraintName, Type: ConstraintUnique, Columns: columns})
+			}
+		}
+	}
+	foreignKeys, err := explorer.GetTableForeignKeys(ctx, dbName, schemaName, tableNames)
+	if err != nil {
+		return
+	}
+	for tableName, infos := range foreignKeys {
+		if table, ok := tableIndex[tableName]; ok {
+			for _, info := range infos {
+				table.Constraints = append(table.Constraints, &Constraint{
+					Name:       info.ConstraintName,
+					Type:       ConstraintForeignKey,
+					Columns:    info.Columns,
+					RefSchema:  info.RefSchemaName,
+					RefTable:   info.RefTableName,
+					RefColumns: info.RefColumns,
+					OnDelete:   info.OnDelete,
+					OnUpdate:   info.OnUpdate,
+				})
+			}
+		}
+	}
+	checks, err := explorer.GetTableCheckConstraints(ctx, dbName, schemaName, tableNames)
+	if err != nil {
+		return
+	}
+	for tableName, infos := range checks {
+		if table, ok := tableIndex[tableName]; ok {
+			for _, info := range infos {
+				table.Constraints = append(table.Constraints, &Constraint{Name: info.ConstraintName, Type: ConstraintCheck, Expression: info.Expression})
+			}
+		}
+	}
+	indexes, err := explorer.GetTableIndexes(ctx, dbName, schemaName, tableNames)
+	if err != nil {
+		return
+	}
+	for tableName, infos := range indexes {
+		if table, ok := tableIndex[tableName]; ok {
+			for _, info := range infos {
+				table.Indexes = append(table.Indexes, &Index{Name: info.IndexName, Unique: info.IsUnique, Columns: info.Columns, Filter: info.Filter})
+			}
+		}
+	}
+	return
+}
+
+// constraintOrder 约束排序，主键在前
+var constraintOrder = map[ConstraintType]int{
+	ConstraintPrimaryKey: 0,
+	ConstraintUnique:     1,
+	ConstraintForeignKey: 2,
+	ConstraintCheck:      3,
+}
+
+// Sort 按名称排序表、约束、索引及视图，列按字段序号排序，使快照内容稳定
+func (s *Schema) Sort() {
+	sort.Slice(s.Tables, func(i, j int) bool { return s.Tables[i].Name < s.Tables[j].Name })
+	sort.Slice(s.Views, func(i, j int) bool { return s.Views[i].Name < s.Views[j].Name })
+	for _, table := range s.Tables {
+		sort.SliceStable(table.Columns, func(i, j int) bool { return table.Columns[i].Position < table.Columns[j].Position })
+		sort.Slice(table.Constraints, func(i, j int) bool {
+			left, right := table.Constraints[i], table.Constraints[j]
+			if left.Type != right.Type {
+				return constraintOrder[left.Type] < constraintOrder[right.Type]
+			}
+			return left.Name < right.Name
+		})
+		sort.Slice(table.Indexes, func(i, j int) bool { return table.Indexes[i].Name < table.Indexes[j].Name })
+	}
+}
+
+// Table 按名称查找表，不存在时返回 nil
+func (s *Schema) Table(name string) *Table {
+	for _, table := range s.Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+// Column 按名称查找列，不存在时返回 nil
+func (t *Table) Column(name string) *Column {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// ConstraintsOf 指定类型的约束
+func (t *Table) ConstraintsOf(constraintType ConstraintType) (constraints []*Constraint) {
+	for _, constraint := range t.Constraints {
+		if constraint.Type == constraintType {
+			constraints = append(constraints, constraint)
+		}
+	}
+	return
+}
+
+// WriteFile 将快照写入文件，.json 以json格式写入，其余以yaml格式写入
+func (s *Schema) WriteFile(filePath string) error {
+	return writeDocument(filePath, s)
+}
+
+// writeDocument .json 以json格式写入，其余以yaml格式写入
+func writeDocument(filePath string, document any) (err error) {
+	var content []byte
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		content, err = sonic.ConfigStd.MarshalIndent(document, "", "  ")
+	} else {
+		content, err = yaml.Marshal(document)
+	}
+	if err != nil {
+		return
+	}
+	return os.WriteFile(filePath, content, 0644)
+}
+
+// LoadSchema 读取模式快照文件，.json 按json解析，其余按yaml解析；版本高于 SchemaVersion 时返回错误
+func LoadSchema(filePath string) (schema *Schema, err error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return
+	}
+	schema = &Schema{}
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		err = sonic.Unmarshal(content, schema)
+	} else {
+		err = yaml.Unmarshal(content, schema)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if schema.Version <= 0 || schema.Version > SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema snapshot version %d, expected 1 to %d", schema.Version, SchemaVersion)
+	}
+	return
+}
@@ -0,0 +1,298 @@
+package dboperator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// DiffKind 差异类型，以 from 为基准：added 为仅 to 中存在，removed 为仅 from 中存在
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// diffSymbols 文本报告中各差异类型的前缀
+var diffSymbols = map[DiffKind]string{DiffAdded: "+", DiffRemoved: "-", DiffChanged: "~"}
+
+// SchemaDiff 两个模式快照间的差异
+type SchemaDiff struct {
+	FromDBType dbx.DBType      `json:"from_db_type"`
+	FromSchema string          `json:"from_schema"`
+	ToDBType   dbx.DBType      `json:"to_db_type"`
+	ToSchema   string          `json:"to_schema"`
+	Changes    []*SchemaChange `json:"changes"`
+}
+
+// SchemaChange 单项差异，ObjectType 为 table、column、index 或约束类型(见 ConstraintType)
+type SchemaChange struct {
+	Kind       DiffKind `json:"kind"`
+	ObjectType string   `json:"object_type"`
+	TableName  string   `json:"table_name"`
+	Name       string   `json:"name,omitempty"`      // 列名、约束名或索引名，表差异时为空
+	Attribute  string   `json:"attribute,omitempty"` // 发生变化的属性，如 type、nullable、definition
+	From       string   `json:"from,omitempty"`
+	To         string   `json:"to,omitempty"`
+}
+
+// DiffSchema 比较两个模式快照的表、列、类型、可空、主键、唯一键、外键及索引。
+// 表、列等名称忽略大小写匹配；两者数据库类型不同时，字段类型经各自的 ITransfer 转为通用字段后比较，
+// 相同时直接比较类型写法。检查约束、默认值为各数据库写法，不参与比较
+func DiffSchema(from, to *Schema, fromTransfer, toTransfer ITransfer) *SchemaDiff {
+	diff := &SchemaDiff{
+		FromDBType: from.DBType,
+		FromSchema: from.Name,
+		ToDBType:   to.DBType,
+		ToSchema:   to.Name,
+		Changes:    make([]*SchemaChange, 0),
+	}
+	comparer := &schemaComparer{diff: diff, from: from, to: to, fromTransfer: fromTransfer, toTransfer: toTransfer}
+	toTables := make(map[string]*Table)
+	for _, table := range to.Tables {
+		toTables[strings.ToLower(table.Name)] = table
+	}
+	for _, fromTable := range from.Tables {
+		key := strings.ToLower(fromTable.Name)
+		toTable, ok := toTables[key]
+		if !ok {
+			comparer.add(DiffRemoved, "table", fromTable.Name, "", "", "", "")
+			continue
+		}
+		delete(toTables, key)
+		comparer.compareTable(fromTable, toTable)
+	}
+	for _, toTable := range to.Tables {
+		if _, ok := toTables[strings.ToLower(toTable.Name)]; ok {
+			comparer.add(DiffAdded, "table", toTable.Name, "", "", "", "")
+		}
+	}
+	return diff
+}
+
+type schemaComparer struct {
+	diff                     *SchemaDiff
+	from, to                 *Schema
+	fromTransfer, toTransfer ITransfer
+}
+
+func (c *schemaComparer) add(kind DiffKind, objectType, tableName, name, attribute, from, to string) {
+	c.diff.Changes = append(c.diff.Changes, &SchemaChange{
+		Kind:       kind,
+		ObjectType: objectType,
+		TableName:  tableName,
+		Name:       name,
+		Attribute:  attribute,
+		From:       from,
+		To:         to,
+	})
+}
+
+func (c *schemaComparer) compareTable(fromTable, toTable *Table) {
+	toColumns := make(map[string]*Column)
+	for _, column := range toTable.Columns {
+		toColumns[strings.ToLower(column.Name)] = column
+	}
+	for _, fromColumn := range fromTable.Columns {
+		key := strings.ToLower(fromColumn.Name)
+		toColumn, ok := toColumns[key]
+		if !ok {
+			c.add(DiffRemoved, "column", fromTable.Name, fromColumn.Name, "", fromColumn.DataType, "")
+			continue
+		}
+		delete(toColumns, key)
+		if fromType, toType := c.columnTypes(fromColumn, toColumn); fromType != toType {
+			c.add(DiffChanged, "column", fromTable.Name, fromColumn.Name, "type", fromColumn.DataType, toColumn.DataType)
+		}
+		if fromColumn.Nullable != toColumn.Nullable {
+			c.add(DiffChanged, "column", fromTable.Name, fromColumn.Name, "nullable", fmt.Sprint(fromColumn.Nullable), fmt.Sprint(toColumn.Nullable))
+		}
+	}
+	for _, toColumn := range toTable.Columns {
+		if _, ok := toColumns[strings.ToLower(toColumn.Name)]; ok {
+			c.add(DiffAdded, "column", fromTable.Name, toColumn.Name, "", "", toColumn.DataType)
+		}
+	}
+
+	for _, constraintType := range []ConstraintType{ConstraintPrimaryKey, ConstraintUnique, ConstraintForeignKey} {
+		c.compareObjects(fromTable.Name, string(constraintType), describeConstraints(fromTable.ConstraintsOf(constraintType)),
+			describeConstraints(toTable.ConstraintsOf(constraintType)))
+	}
+	c.compareObjects(fromTable.Name, "index", describeIndexes(fromTable.Indexes), describeIndexes(toTable.Indexes))
+}
+
+// compareObjects 约束名、索引名在不同数据库中常由系统生成，按定义匹配；定义不同但名称相同时视为变更
+func (c *schemaComparer) compareObjects(tableName, objectType string, fromObjects, toObjects map[string]string) {
+	unmatched := make(map[string]string)
+	for name, definition := range toObjects {
+		unmatched[definition] = name
+	}
+	for _, name := range sortedNames(fromObjects) {
+		definition := fromObjects[name]
+		if _, ok := unmatched[definition]; ok {
+			delete(unmatched, definition)
+			continue
+		}
+		if toDefinition, ok := toObjects[name]; ok && unmatched[toDefinition] == name {
+			delete(unmatched, toDefinition)
+			c.add(DiffChanged, objectType, tableName, displayName(name), "definition", definition, toDefinition)
+			continue
+		}
+		c.add(DiffRemoved, objectType, tableName, displayName(name), "", definition, "")
+	}
+	for _, name := range sortedNames(toObjects) {
+		if definition := toObjects[name]; unmatched[definition] == name {
+			c.add(DiffAdded, objectType, tableName, displayName(name), "", "", definition)
+		}
+	}
+}
+
+// columnTypes 用于比较的字段类型，数据库类型相同时为类型写法，不同时为通用字段的说明
+func (c *schemaComparer) columnTypes(fromColumn, toColumn *Column) (fromType, toType string) {
+	if c.from.DBType == c.to.DBType || c.fromTransfer == nil || c.toTransfer == nil {
+		return normalizeDataType(fromColumn.DataType), normalizeDataType(toColumn.DataType)
+	}
+	fromField, toField := c.fromTransfer.Trans2CommonField(fromColumn.DataType), c.toTransfer.Trans2CommonField(toColumn.DataType)
+	if fromField == nil || toField == nil {
+		return normalizeDataType(fromColumn.DataType), normalizeDataType(toColumn.DataType)
+	}
+	return describeNumber(c.from.DBType, fromField), describeNumber(c.to.DBType, toField)
+}
+
+func normalizeDataType(dataType string) string {
+	return strings.Join(strings.Fields(strings.ToLower(dataType)), " ")
+}
+
+// describeConstraints 约束名 -> 定义，定义中的名称转小写，便于跨数据库比较
+func describeConstraints(constraints []*Constraint) map[string]string {
+	result := make(map[string]string)
+	for i, constraint := range constraints {
+		definition := "(" + strings.ToLower(strings.Join(constraint.Columns, ",")) + ")"
+		if constraint.Type == ConstraintForeignKey {
+			definition += fmt.Sprintf(" references %s (%s)", strings.ToLower(constraint.RefTable), strings.ToLower(strings.Join(constraint.RefColumns, ",")))
+			if constraint.OnDelete != "" {
+				definition += " on delete " + strings.ToLower(constraint.OnDelete)
+			}
+			if constraint.OnUpdate != "" {
+				definition += " on update " + strings.ToLower(constraint.OnUpdate)
+			}
+		}
+		result[constraintKey(constraint.Name, i)] = definition
+	}
+	return result
+}
+
+// describeIndexes 索引名 -> 定义
+func describeIndexes(indexes []*Index) map[string]string {
+	result := make(map[string]string)
+	for i, index := range indexes {
+		columns := make([]string, 0, len(index.Columns))
+		for _, column := range index.Columns {
+			name := strings.ToLower(column.ColumnName)
+			if column.Expression != "" {
+				name = column.Expression
+			}
+			if column.IsDesc {
+				name += " desc"
+			}
+			columns = append(columns, name)
+		}
+		definition := "(" + strings.Join(columns, ",") + ")"
+		if index.Unique {
+			definition = "unique " + definition
+		}
+		if index.Filter != "" {
+			definition += " where " + index.Filter
+		}
+		result[constraintKey(index.Name, i)] = definition
+	}
+	return result
+}
+
+// constraintKey 未命名的约束(如部分数据库的主键)以序号区分，名称忽略大小写
+func constraintKey(name string, i int) string {
+	if name == "" {
+		return fmt.Sprintf("#%d", i+1)
+	}
+	return strings.ToLower(name)
+}
+
+func displayName(key string) string {
+	if strings.HasPrefix(key, "#") {
+		return ""
+	}
+	return key
+}
+
+func sortedNames(objects map[string]string) []string {
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Count 指定类型的差异数量
+func (d *SchemaDiff) Count(kind DiffKind) (count int) {
+	for _, change := range d.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return
+}
+
+// WriteText 以文本格式输出差异，每项一行，+ 为新增、- 为删除、~ 为变更
+func (d *SchemaDiff) WriteText(w io.Writer) (err error) {
+	_, err = fmt.Fprintf(w, "--- %s %s\n+++ %s %s\n", d.FromDBType, d.FromSchema, d.ToDBType, d.ToSchema)
+	if err != nil {
+		return
+	}
+	for _, change := range d.Changes {
+		line := fmt.Sprintf("%s %s %s", diffSymbols[change.Kind], change.ObjectType, change.TableName)
+		if change.Name != "" {
+			line += "." + change.Name
+		}
+		switch {
+		case change.Kind == DiffChanged:
+			line += fmt.Sprintf(" %s: %s -> %s", change.Attribute, change.From, change.To)
+		case change.From != "":
+			line += " " + change.From
+		case change.To != "":
+			line += " " + change.To
+		}
+		_, err = fmt.Fprintln(w, line)
+		if err != nil {
+			return
+		}
+	}
+	_, err = fmt.Fprintf(w, "%d added, %d removed, %d changed\n", d.Count(DiffAdded), d.Count(DiffRemoved), d.Count(DiffChanged))
+	return
+}
+
+// WriteFile 将差异写入文件，.json 以json格式写入，其余以文本格式写入
+func (d *SchemaDiff) WriteFile(filePath string) (err error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		content, marshalErr := sonic.ConfigStd.MarshalIndent(d, "", "  ")
+		if marshalErr != nil {
+			return marshalErr
+		}
+		return os.WriteFile(filePath, content, 0644)
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	return d.WriteText(file)
+}
@@ -0,0 +1,89 @@
+package dboperator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+// mapTransfer 按类型写法查表的类型转换，仅用于测试
+type mapTransfer map[string]*Field
+
+func (m mapTransfer) Trans2CommonField(dataType string) *Field {
+	return m[strings.ToLower(dataType)]
+}
+
+func (m mapTransfer) Trans2DataType(field *Field) string {
+	return ""
+}
+
+func TestDiffSchema(t *testing.T) {
+	from := &Schema{DBType: dbx.DBTypeMySQL, Name: "app", Tables: []*Table{
+		{
+			Name: "orders",
+			Columns: []*Column{
+				{Name: "id", DataType: "bigint"},
+				{Name: "amount", DataType: "decimal(10,2)", Nullable: true},
+				{Name: "memo", DataType: "varchar(100)", Nullable: true},
+				{Name: "legacy", DataType: "int", Nullable: true},
+			},
+			Constraints: []*Constraint{
+				{Name: "PRIMARY", Type: ConstraintPrimaryKey, Columns: []string{"id"}},
+				{Name: "fk_orders_user", Type: ConstraintForeignKey, Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+			},
+			Indexes: []*Index{{Name: "idx_memo", Columns: []*IndexColumn{{ColumnName: "memo"}}}},
+		},
+		{Name: "audit_log", Columns: []*Column{{Name: "id", DataType: "bigint"}}},
+	}}
+	to := &Schema{DBType: dbx.DBTypePostgres, Name: "public", Tables: []*Table{
+		{
+			Name: "ORDERS",
+			Columns: []*Column{
+				{Name: "ID", DataType: "int8"},
+				{Name: "AMOUNT", DataType: "numeric(12,2)"},
+				{Name: "MEMO", DataType: "character varying(100)", Nullable: true},
+				{Name: "CREATED_AT", DataType: "timestamp"},
+			},
+			Constraints: []*Constraint{
+				{Type: ConstraintPrimaryKey, Columns: []string{"ID"}},
+				{Name: "orders_user_id_fkey", Type: ConstraintForeignKey, Columns: []string{"USER_ID"}, RefTable: "USERS", RefColumns: []string{"ID"}},
+			},
+			Indexes: []*Index{{Name: "idx_memo", Columns: []*IndexColumn{{ColumnName: "memo", IsDesc: true}}}},
+		},
+		{Name: "users", Columns: []*Column{{Name: "id", DataType: "int8"}}},
+	}}
+	fromTransfer := mapTransfer{
+		"bigint":        {Type: INT64},
+		"int":           {Type: INT32},
+		"decimal(10,2)": {Type: FLOAT64, IsFixedNumber: true, Precision: 10, Scale: 2},
+		"varchar(100)":  {Type: STRING, Length: 100},
+	}
+	toTransfer := mapTransfer{
+		"int8":                   {Type: INT64},
+		"numeric(12,2)":          {Type: FLOAT64, IsFixedNumber: true, Precision: 12, Scale: 2},
+		"character varying(100)": {Type: STRING, Length: 100},
+		"timestamp":              {Type: TIME, TimeType: "timestamp"},
+	}
+
+	diff := DiffSchema(from, to, fromTransfer, toTransfer)
+	var text bytes.Buffer
+	if err := diff.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	expected := `--- mysql app
++++ postgres public
+~ column orders.amount type: decimal(10,2) -> numeric(12,2)
+~ column orders.amount nullable: true -> false
+- column orders.legacy int
++ column orders.CREATED_AT timestamp
+~ index orders.idx_memo definition: (memo) -> (memo desc)
+- table audit_log
++ table users
+2 added, 2 removed, 3 changed
+`
+	if text.String() != expected {
+		t.Errorf("WriteText =\n%s\nexpected\n%s", text.String(), expected)
+	}
+}
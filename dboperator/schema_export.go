@@ -0,0 +1,302 @@
+package dboperator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JSONSchemaDialect 生成的 JSON Schema 版本
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// OpenAPIVersion 生成的 OpenAPI 文档版本，可空以 nullable 表示
+const OpenAPIVersion = "3.0.3"
+
+// JSONSchema JSON Schema 或 OpenAPI 的模式对象，仅包含由表结构生成的关键字
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty" yaml:"title,omitempty"`
+	Description          string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty" yaml:"type,omitempty"` // 类型，JSON Schema 中可空时为 [类型, "null"]
+	Format               string                 `json:"format,omitempty" yaml:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty" yaml:"contentEncoding,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty" yaml:"nullable,omitempty"` // OpenAPI 3.0 的可空
+	Items                *JSONSchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty" yaml:"$defs,omitempty"`
+}
+
+// OpenAPIDocument 仅包含组件模式的 OpenAPI 文档，可合并到已有的接口定义中
+type OpenAPIDocument struct {
+	OpenAPI string `json:"openapi" yaml:"openapi"`
+	Info    struct {
+		Title   string `json:"title" yaml:"title"`
+		Version string `json:"version" yaml:"version"`
+	} `json:"info" yaml:"info"`
+	Paths      map[string]any `json:"paths" yaml:"paths"`
+	Components struct {
+		Schemas map[string]*JSONSchema `json:"schemas" yaml:"schemas"`
+	} `json:"components" yaml:"components"`
+}
+
+// GenerateJSONSchema 生成 JSON Schema(draft 2020-12)文档，每张表为 $defs 中以表名为键的对象模式：
+// 列为属性，非空列为必填，可空列的类型含 null，字符串长度为 maxLength，表及列注释为 description
+func GenerateJSONSchema(tables []*Table, transfer ITransfer) *JSONSchema {
+	document := &JSONSchema{Schema: JSONSchemaDialect, Defs: make(map[string]*JSONSchema)}
+	for _, table := range tables {
+		document.Defs[table.Name] = tableJSONSchema(table, transfer, false)
+	}
+	return document
+}
+
+// GenerateOpenAPI 生成 OpenAPI 3.0 文档，每张表为 components.schemas 中以表名为键的对象模式，可空列以 nullable 表示，其余同 GenerateJSONSchema
+func GenerateOpenAPI(tables []*Table, transfer ITransfer, title string) *OpenAPIDocument {
+	document := &OpenAPIDocument{OpenAPI: OpenAPIVersion, Paths: make(map[string]any)}
+	document.Info.Title, document.Info.Version = title, "1.0.0"
+	document.Components.Schemas = make(map[string]*JSONSchema)
+	for _, table := range tables {
+		document.Components.Schemas[table.Name] = tableJSONSchema(table, transfer, true)
+	}
+	return document
+}
+
+func tableJSONSchema(table *Table, transfer ITransfer, openAPI bool) *JSONSchema {
+	additionalProperties := false
+	schema := &JSONSchema{
+		Title:                table.Name,
+		Description:          table.Comment,
+		Type:                 "object",
+		Properties:           make(map[string]*JSONSchema),
+		AdditionalProperties: &additionalProperties,
+	}
+	for _, column := range table.Columns {
+		property := fieldJSONSchema(transfer.Trans2CommonField(column.DataType), openAPI)
+		property.Description = column.Comment
+		if column.Nullable {
+			setNullable(property, openAPI)
+		} else {
+			schema.Required = append(schema.Required, column.Name)
+		}
+		schema.Properties[column.Name] = property
+	}
+	return schema
+}
+
+// fieldJSONSchema 通用字段对应的模式，json 类型不限定类型，无法识别的类型为字符串
+func fieldJSONSchema(field *Field, openAPI bool) *JSONSchema {
+	schema := &JSONSchema{Type: "string"}
+	if field == nil {
+		return schema
+	}
+	switch field.Type {
+	case INT8, INT16, INT32, INT64:
+		schema.Type = "integer"
+		schema.Format = "int32"
+		if field.Type == INT64 || field.Type == INT32 && field.IsUnsigned {
+			schema.Format = "int64"
+		}
+		if field.IsUnsigned {
+			minimum := 0
+			schema.Minimum = &minimum
+		}
+	case FLOAT32:
+		schema.Type, schema.Format = "number", "float"
+	case FLOAT64:
+		schema.Type, schema.Format = "number", "double"
+		if field.IsFixedNumber {
+			schema.Format = ""
+		}
+	case BOOL:
+		schema.Type = "boolean"
+	case TIME:
+		switch field.TimeType {
+		case "date":
+			schema.Format = "date"
+		case "time", "timetz":
+			schema.Format = "time"
+		case "year":
+			schema.Type = "integer"
+		default:
+			schema.Format = "date-time"
+		}
+	case BYTES, RUNES:
+		if openAPI {
+			schema.Format = "byte"
+		} else {
+			schema.ContentEncoding = "base64"
+		}
+	case UUID:
+		schema.Format = "uuid"
+	case ENUM:
+		for _, value := range field.EnumValues {
+			schema.Enum = append(schema.Enum, value)
+		}
+	case JSON:
+		schema.Type = nil
+	case ARRAY:
+		schema.Type = "array"
+		schema.Items = fieldJSONSchema(field.ElementType, openAPI)
+	case STRING:
+		if !field.IsText && field.Length > 0 {
+			maxLength := field.Length
+			schema.MaxLength = &maxLength
+		}
+	}
+	return schema
+}
+
+// setNullable 可空列：OpenAPI 3.0 以 nullable 表示，JSON Schema 的类型及枚举值加入 null
+func setNullable(schema *JSONSchema, openAPI bool) {
+	if openAPI {
+		schema.Nullable = schema.Type != nil
+		return
+	}
+	if schema.Type == nil {
+		return
+	}
+	schema.Type = []string{schema.Type.(string), "null"}
+	if len(schema.Enum) > 0 {
+		schema.Enum = append(schema.Enum, nil)
+	}
+}
+
+// WriteFile 将 JSON Schema 写入文件，.json 以json格式写入，其余以yaml格式写入
+func (s *JSONSchema) WriteFile(filePath string) error {
+	return writeDocument(filePath, s)
+}
+
+// WriteFile 将 OpenAPI 文档写入文件，.json 以json格式写入，其余以yaml格式写入
+func (d *OpenAPIDocument) WriteFile(filePath string) error {
+	return writeDocument(filePath, d)
+}
+
+// ProtoOptions 生成 proto3 消息的可选配置
+type ProtoOptions struct {
+	Package   string // proto 包名，为空时不声明
+	GoPackage string // go_package 选项，为空时不声明
+}
+
+// GenerateProto 生成 proto3 文件，每张表为一个消息，字段名为小写的列名，编号按列的顺序；
+// 可空的标量字段为 optional，时间为 google.protobuf.Timestamp，定点数为 string 以免丢失精度，
+// 注释包含列注释、类型写法及可空
+func GenerateProto(tables []*Table, transfer ITransfer, options *ProtoOptions) []byte {
+	if options == nil {
+		options = &ProtoOptions{}
+	}
+	sorted := append([]*Table(nil), tables...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var body bytes.Buffer
+	usesTimestamp := false
+	messageNames := make(map[string]bool)
+	for _, table := range sorted {
+		messageName := uniqueGoName(GoName(table.Name), messageNames)
+		if table.Comment != "" {
+			fmt.Fprintf(&body, "// %s\n", singleLine(table.Comment))
+		}
+		fmt.Fprintf(&body, "message %s {\n", messageName)
+		fieldNames := make(map[string]bool)
+		for i, column := range table.Columns {
+			protoType := fieldProtoType(transfer.Trans2CommonField(column.DataType))
+			if protoType == "google.protobuf.Timestamp" {
+				usesTimestamp = true
+			}
+			label := ""
+			switch {
+			case strings.HasPrefix(protoType, "repeated "):
+			case column.Nullable && !strings.Contains(protoType, "."):
+				label = "optional "
+			}
+			description := []string{column.DataType}
+			if column.Nullable {
+				description = append(description, "nullable")
+			}
+			if column.Comment != "" {
+				description = append([]string{singleLine(column.Comment)}, description...)
+			}
+			fmt.Fprintf(&body, "  // %s\n", strings.Join(description, ", "))
+			fmt.Fprintf(&body, "  %s%s %s = %d;\n", label, protoType, uniqueGoName(protoFieldName(column.Name), fieldNames), i+1)
+		}
+		body.WriteString("}\n\n")
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("// Code generated by dbutil. DO NOT EDIT.\n\nsyntax = \"proto3\";\n\n")
+	if options.Package != "" {
+		fmt.Fprintf(&buffer, "package %s;\n\n", options.Package)
+	}
+	if usesTimestamp {
+		buffer.WriteString("import \"google/protobuf/timestamp.proto\";\n\n")
+	}
+	if options.GoPackage != "" {
+		fmt.Fprintf(&buffer, "option go_package = %q;\n\n", options.GoPackage)
+	}
+	buffer.Write(bytes.TrimSuffix(body.Bytes(), []byte("\n")))
+	return buffer.Bytes()
+}
+
+// fieldProtoType 通用字段对应的 proto3 类型，数组为 repeated，无法识别的类型为 string
+func fieldProtoType(field *Field) string {
+	if field == nil {
+		return "string"
+	}
+	switch field.Type {
+	case INT8, INT16, INT32:
+		if field.IsUnsigned {
+			return "uint32"
+		}
+		return "int32"
+	case INT64:
+		if field.IsUnsigned {
+			return "uint64"
+		}
+		return "int64"
+	case FLOAT32:
+		return "float"
+	case FLOAT64:
+		if field.IsFixedNumber {
+			return "string"
+		}
+		return "double"
+	case BOOL:
+		return "bool"
+	case BYTES, RUNES:
+		return "bytes"
+	case TIME:
+		switch field.TimeType {
+		case "date", "time", "timetz":
+			return "string"
+		case "year":
+			return "int32"
+		}
+		return "google.protobuf.Timestamp"
+	case ARRAY:
+		if elementType := fieldProtoType(field.ElementType); !strings.HasPrefix(elementType, "repeated ") {
+			return "repeated " + elementType
+		}
+	}
+	return "string"
+}
+
+// protoFieldName 小写的列名，非字母数字的字符改为下划线，非字母开头时加前缀 f_
+func protoFieldName(name string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			builder.WriteRune(r)
+			continue
+		}
+		builder.WriteByte('_')
+	}
+	fieldName := builder.String()
+	if fieldName == "" || fieldName[0] < 'a' || fieldName[0] > 'z' {
+		fieldName = "f_" + fieldName
+	}
+	return fieldName
+}
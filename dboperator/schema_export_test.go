@@ -0,0 +1,99 @@
+package dboperator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func exportTestTables() []*Table {
+	return []*Table{
+		{
+			Name:    "users",
+			Comment: "用户",
+			Columns: []*Column{
+				{Name: "id", DataType: "bigint unsigned", AutoIncrement: true},
+				{Name: "user_name", DataType: "varchar(50)", Comment: "登录名"},
+				{Name: "status", DataType: "enum('on','off')", Nullable: true},
+				{Name: "balance", DataType: "decimal(10,2)", Nullable: true},
+				{Name: "login_at", DataType: "datetime", Nullable: true},
+				{Name: "profile", DataType: "json", Nullable: true},
+				{Name: "tags", DataType: "text[]"},
+			},
+		},
+	}
+}
+
+var exportTestTransfer = mapTransfer{
+	"bigint unsigned":  {Type: INT64, IsUnsigned: true},
+	"varchar(50)":      {Type: STRING, Length: 50},
+	"enum('on','off')": {Type: ENUM, EnumValues: []string{"on", "off"}},
+	"decimal(10,2)":    {Type: FLOAT64, IsFixedNumber: true, Precision: 10, Scale: 2},
+	"datetime":         {Type: TIME, TimeType: "datetime"},
+	"json":             {Type: JSON},
+	"text[]":           {Type: ARRAY, ElementType: &Field{Type: STRING, IsText: true}},
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	document := GenerateJSONSchema(exportTestTables(), exportTestTransfer)
+	if document.Schema != JSONSchemaDialect {
+		t.Errorf("$schema = %q", document.Schema)
+	}
+	users := document.Defs["users"]
+	if users == nil || users.Type != "object" || users.Description != "用户" {
+		t.Fatalf("unexpected table schema %+v", users)
+	}
+	if !reflect.DeepEqual(users.Required, []string{"id", "user_name", "tags"}) {
+		t.Errorf("required = %v", users.Required)
+	}
+	properties := users.Properties
+	if id := properties["id"]; id.Type != "integer" || id.Format != "int64" || id.Minimum == nil || *id.Minimum != 0 {
+		t.Errorf("unexpected id %+v", id)
+	}
+	if name := properties["user_name"]; name.Type != "string" || name.MaxLength == nil || *name.MaxLength != 50 || name.Description != "登录名" {
+		t.Errorf("unexpected user_name %+v", name)
+	}
+	if status := properties["status"]; !reflect.DeepEqual(status.Type, []string{"string", "null"}) || !reflect.DeepEqual(status.Enum, []any{"on", "off", nil}) {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if loginAt := properties["login_at"]; loginAt.Format != "date-time" || !reflect.DeepEqual(loginAt.Type, []string{"string", "null"}) {
+		t.Errorf("unexpected login_at %+v", loginAt)
+	}
+	if profile := properties["profile"]; profile.Type != nil {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+	if tags := properties["tags"]; tags.Type != "array" || tags.Items == nil || tags.Items.Type != "string" || tags.Items.MaxLength != nil {
+		t.Errorf("unexpected tags %+v", tags)
+	}
+
+	openAPI := GenerateOpenAPI(exportTestTables(), exportTestTransfer, "app")
+	if openAPI.OpenAPI != OpenAPIVersion || openAPI.Info.Title != "app" {
+		t.Errorf("unexpected document %+v", openAPI)
+	}
+	status := openAPI.Components.Schemas["users"].Properties["status"]
+	if status.Type != "string" || !status.Nullable || len(status.Enum) != 2 {
+		t.Errorf("unexpected openapi status %+v", status)
+	}
+	if profile := openAPI.Components.Schemas["users"].Properties["profile"]; profile.Nullable {
+		t.Errorf("unexpected openapi profile %+v", profile)
+	}
+}
+
+func TestGenerateProto(t *testing.T) {
+	proto := string(GenerateProto(exportTestTables(), exportTestTransfer, &ProtoOptions{Package: "app.v1", GoPackage: "example.com/app/v1"}))
+	for _, expected := range []string{
+		"syntax = \"proto3\";\n\npackage app.v1;\n\nimport \"google/protobuf/timestamp.proto\";\n\noption go_package = \"example.com/app/v1\";\n\n// 用户\nmessage Users {\n",
+		"  // bigint unsigned\n  uint64 id = 1;\n",
+		"  // 登录名, varchar(50)\n  string user_name = 2;\n",
+		"  // decimal(10,2), nullable\n  optional string balance = 4;\n",
+		"  google.protobuf.Timestamp login_at = 5;\n",
+		"  repeated string tags = 7;\n}\n",
+	} {
+		if !strings.Contains(proto, expected) {
+			t.Errorf("missing %q in:\n%s", expected, proto)
+		}
+	}
+	if protoFieldName("2FA-Code") != "f_2fa_code" {
+		t.Errorf("protoFieldName = %s", protoFieldName("2FA-Code"))
+	}
+}
@@ -0,0 +1,59 @@
+package dboperator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestSchemaFile(t *testing.T) {
+	schema := &Schema{
+		Version: SchemaVersion,
+		DBType:  dbx.DBTypeMySQL,
+		Name:    "app",
+		Tables: []*Table{
+			{
+				Name: "orders",
+				Columns: []*Column{
+					{Name: "user_id", DataType: "bigint", Position: 2},
+					{Name: "id", DataType: "bigint", Position: 1, AutoIncrement: true, NextValue: 100},
+				},
+				Constraints: []*Constraint{
+					{Name: "fk_orders_user", Type: ConstraintForeignKey, Columns: []string{"user_id"}, RefSchema: "app", RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"},
+					{Type: ConstraintPrimaryKey, Columns: []string{"id"}},
+				},
+				Indexes: []*Index{{Name: "idx_user", Columns: []*IndexColumn{{ColumnName: "user_id", IsDesc: true}}}},
+			},
+			{Name: "users", Comment: "用户", Columns: []*Column{{Name: "id", DataType: "bigint", Position: 1}}},
+		},
+	}
+	schema.Sort()
+	if schema.Tables[0].Columns[0].Name != "id" || schema.Tables[0].Constraints[0].Type != ConstraintPrimaryKey {
+		t.Fatalf("Sort did not order columns by position and primary key first")
+	}
+
+	for _, name := range []string{"schema.json", "schema.yaml"} {
+		filePath := filepath.Join(t.TempDir(), name)
+		if err := schema.WriteFile(filePath); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadSchema(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(schema, loaded) {
+			t.Errorf("%s round trip mismatch", name)
+		}
+	}
+
+	filePath := filepath.Join(t.TempDir(), "future.yaml")
+	if err := os.WriteFile(filePath, []byte("version: 99\nname: app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSchema(filePath); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+}
@@ -0,0 +1,156 @@
+package dboperator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type sqlTokenKind int
+
+const (
+	sqlTokenString sqlTokenKind = iota
+	sqlTokenIdent
+	sqlTokenWord
+	sqlTokenNumber
+	sqlTokenSymbol
+)
+
+type sqlToken struct {
+	kind  sqlTokenKind
+	value string
+}
+
+var sqlSymbols = []string{"::", "<>", "!=", "<=", ">=", "||", "(", ")", "[", "]", ",", "=", "<", ">", "+", "-", "*", "/", "%", "."}
+
+// tokenizeSQL 将SQL片段切分为字符串、标识符、单词、数值及符号，标识符去掉引号
+func tokenizeSQL(expression string) (tokens []sqlToken, err error) {
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			end := i + 1
+			for ; end < len(expression); end++ {
+				if expression[end] == '\'' {
+					if end+1 < len(expression) && expression[end+1] == '\'' {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			if end >= len(expression) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenString, value: expression[i : end+1]})
+			i = end + 1
+		case c == '"' || c == '`' || (c == '[' && !isArrayBracket(tokens, expression[i+1:])):
+			closeChar := map[byte]byte{'"': '"', '`': '`', '[': ']'}[c]
+			end := strings.IndexByte(expression[i+1:], closeChar)
+			if end == -1 {
+				return nil, errors.New("unterminated identifier")
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenIdent, value: expression[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(expression) && expression[i+1] >= '0' && expression[i+1] <= '9':
+			end := i
+			for end < len(expression) && (expression[end] >= '0' && expression[end] <= '9' || expression[end] == '.' ||
+				expression[end] == 'e' || expression[end] == 'E') {
+				end++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenNumber, value: expression[i:end]})
+			i = end
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i
+			for end < len(expression) && (expression[end] == '_' || expression[end] == '$' || expression[end] >= 'a' && expression[end] <= 'z' ||
+				expression[end] >= 'A' && expression[end] <= 'Z' || expression[end] >= '0' && expression[end] <= '9') {
+				end++
+			}
+			word := expression[i:end]
+			i = end
+			// mysql字符集前缀 _utf8mb4'a'、sqlserver unicode前缀 N'a'
+			if i < len(expression) && expression[i] == '\'' && (strings.HasPrefix(word, "_") || word == "N") {
+				continue
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenWord, value: word})
+		default:
+			matched := false
+			for _, symbol := range sqlSymbols {
+				if strings.HasPrefix(expression[i:], symbol) {
+					tokens = append(tokens, sqlToken{kind: sqlTokenSymbol, value: symbol})
+					i += len(symbol)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unsupported character %q", c)
+			}
+		}
+	}
+	return
+}
+
+// isArrayBracket 区分postgresql数组的中括号与sqlserver的标识符中括号
+func isArrayBracket(tokens []sqlToken, rest string) bool {
+	if strings.HasPrefix(rest, "]") {
+		return true
+	}
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind == sqlTokenWord && strings.EqualFold(last.value, "array")
+}
+
+// dropTypeCasts 去掉postgresql的类型转换，如 (status)::text、'a'::character varying(10)[]
+func dropTypeCasts(tokens []sqlToken, keywords map[string]bool) []sqlToken {
+	result := make([]sqlToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].value != "::" || tokens[i].kind != sqlTokenSymbol {
+			result = append(result, tokens[i])
+			continue
+		}
+		j := i + 1
+		for j < len(tokens) && tokens[j].kind == sqlTokenWord && !keywords[strings.ToUpper(tokens[j].value)] {
+			j++
+		}
+		if j < len(tokens) && tokens[j].value == "(" {
+			end := j + 1
+			for end < len(tokens) && (tokens[end].kind == sqlTokenNumber || tokens[end].value == ",") {
+				end++
+			}
+			if end < len(tokens) && tokens[end].value == ")" {
+				j = end + 1
+			}
+		}
+		for j+1 < len(tokens) && tokens[j].value == "[" && tokens[j+1].value == "]" {
+			j += 2
+		}
+		i = j - 1
+	}
+	return result
+}
+
+// renderSQLTokens 拼接为SQL，标识符统一加双引号，functions中的函数名与括号间不加空格
+func renderSQLTokens(tokens []sqlToken, functions map[string]bool) string {
+	var builder strings.Builder
+	for i, token := range tokens {
+		if i > 0 {
+			prev := tokens[i-1]
+			isCall := token.value == "(" && prev.kind == sqlTokenWord && functions[prev.value]
+			isDot := token.value == "." || prev.value == "."
+			if prev.value != "(" && token.value != ")" && token.value != "," && !isCall && !isDot {
+				builder.WriteString(" ")
+			}
+		}
+		if token.kind == sqlTokenIdent {
+			builder.WriteString(`"` + token.value + `"`)
+			continue
+		}
+		builder.WriteString(token.value)
+	}
+	return builder.String()
+}
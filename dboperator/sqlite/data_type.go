@@ -38,9 +38,8 @@ func (s SQLiteOperator) Trans2CommonField(dataType string) *dboperator.Field {
 	case "mediumtext", "text", "longtext":
 		field = *dboperator.StringField
 		field.IsText = true
-	case "tinyint", "int1", "smallint", "int2":
-		field = *dboperator.Int32Field
-	case "mediumint", "int", "integer", "int3", "int4":
+	case "tinyint", "int1", "smallint", "int2", "mediumint", "int", "integer", "int3", "int4", "bigint", "int8":
+		// sqlite整数均以8字节保存
 		field = *dboperator.Int64Field
 	case "float", "double", "real":
 		field = *dboperator.Float64Field
@@ -51,6 +50,10 @@ func (s SQLiteOperator) Trans2CommonField(dataType string) *dboperator.Field {
 		field = *dboperator.BoolField
 	case "tinyblob", "blob", "mediumblob", "longblob":
 		field = *dboperator.BytesField
+	case "json":
+		field = *dboperator.JSONField
+	case "uuid":
+		field = *dboperator.UUIDField
 	default:
 		log.DefaultLogger().Warn("handle with default mysql type:%s", dataType)
 		field = *dboperator.StringField
@@ -83,6 +86,10 @@ func (s SQLiteOperator) Trans2CommonField(dataType string) *dboperator.Field {
 }
 
 func (s SQLiteOperator) Trans2DataType(field *dboperator.Field) string {
+	if field.TargetType != "" {
+		return field.TargetType
+	}
+	field = dboperator.WidenUnsigned(field)
 	switch field.Type {
 	case dboperator.RUNES, dboperator.BYTES:
 		return "BLOB"
@@ -113,6 +120,10 @@ func (s SQLiteOperator) Trans2DataType(field *dboperator.Field) string {
 			timeType = "VARCHAR(50)"
 		}
 		return timeType
+	case dboperator.JSON, dboperator.UUID, dboperator.ENUM, dboperator.SET, dboperator.ARRAY,
+		dboperator.INTERVAL, dboperator.XML, dboperator.GEOMETRY:
+		// 无对应类型，以文本保存，ENUM的可选值由检查约束限定
+		return "TEXT"
 	default:
 		log.DefaultLogger().Warn("handle with to default sqlite type:%s", field.Type)
 		return "TEXT"
@@ -131,3 +142,40 @@ func getTypeSuffix(field *dboperator.Field) string {
 	}
 	return ""
 }
+
+// getColumnOption 生成字段排序规则、默认值、非空约束及枚举检查约束
+func getColumnOption(field *dboperator.Field) (option string) {
+	if field.Collation != "" {
+		option += " collate " + field.Collation
+	}
+	if defaultValue := getDefaultValue(field); defaultValue != "" {
+		option += " default " + defaultValue
+	}
+	if !field.ISNullable {
+		option += " not null"
+	}
+	if expression := dboperator.EnumCheckExpression(field, identifier); expression != "" {
+		option += " check (" + expression + ")"
+	}
+	return
+}
+
+// getDefaultValue 将通用默认值翻译为sqlite写法，非常量表达式需用括号包裹
+func getDefaultValue(field *dboperator.Field) string {
+	value := field.DefaultValue
+	switch value {
+	case "", dboperator.DefaultCurrentTimestamp, dboperator.DefaultCurrentDate, dboperator.DefaultCurrentTime:
+		return value
+	case dboperator.DefaultTrue:
+		return "1"
+	case dboperator.DefaultFalse:
+		return "0"
+	}
+	if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "(" + value + ")"
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/jasonlabz/dbutil/core/utils"
 	"github.com/jasonlabz/dbutil/dboperator"
 	"github.com/jasonlabz/dbutil/dbx"
+	"github.com/jasonlabz/dbutil/log"
 )
 
 func NewSQLiteOperator() dboperator.IOperator {
@@ -17,6 +20,12 @@ func NewSQLiteOperator() dboperator.IOperator {
 
 type SQLiteOperator struct{}
 
+// identifier 标识符规则，见 dboperator.GetIdentifierPolicy
+var identifier = dboperator.GetIdentifierPolicy(dbx.DBTypeSQLite)
+
+// autoIncrementColumnReg 建表语句中的自增列声明，列名可用双引号、反引号或方括号引用
+var autoIncrementColumnReg = regexp.MustCompile("(?i)(\"[^\"]+\"|`[^`]+`|" + `\[[^\]]+\]|\w+)\s+integer\s+primary\s+key(?:\s+(?:asc|desc))?(?:\s+on\s+conflict\s+\w+)?\s+autoincrement`)
+
 var (
 	TableNameAllTables     = "INFORMATION_SCHEMA.TABLES"
 	TableNameAllTablesCols = "INFORMATION_SCHEMA.COLUMNS"
@@ -52,15 +61,19 @@ func (s SQLiteOperator) GetDataBySQL(ctx context.Context, dbName, sqlStatement s
 	return
 }
 
+func (s SQLiteOperator) GetSchema(ctx context.Context, dbName, schemaName string) (schema *dboperator.Schema, err error) {
+	return dboperator.InspectSchema(ctx, s, dbx.DBTypeSQLite, dbName, schemaName)
+}
+
 func (s SQLiteOperator) GetTableData(ctx context.Context, dbName, schemaName, tableName string, pageInfo *dboperator.Pagination) (rows []map[string]interface{}, err error) {
 	rows = make([]map[string]interface{}, 0)
 	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
-	queryTable := fmt.Sprintf("\"%s\"", tableName)
+	queryTable := identifier.Quote(tableName)
 	if schemaName != "" {
-		queryTable = fmt.Sprintf("\"%s\".\"%s\"", schemaName, tableName)
+		queryTable = identifier.Quote(schemaName) + "." + queryTable
 	}
 	var count int64
 	tx := db.DB.WithContext(ctx).
@@ -89,7 +102,7 @@ func (s SQLiteOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 		return
 	}
 	err = db.DB.WithContext(ctx).
-		Raw("SELECT name as table_name" +
+		Raw("SELECT name as table_name, -1 as row_count " +
 			"FROM sqlite_master " +
 			"WHERE type = 'table'").
 		Find(&gormDBTables).Error
@@ -106,6 +119,7 @@ func (s SQLiteOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				}},
 			}
 		} else {
@@ -113,6 +127,7 @@ func (s SQLiteOperator) GetTablesUnderSchema(ctx context.Context, dbName string,
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				})
 		}
 	}
@@ -132,7 +147,7 @@ func (s SQLiteOperator) GetTablesUnderDB(ctx context.Context, dbName string) (db
 		return
 	}
 	err = db.DB.WithContext(ctx).
-		Raw("SELECT name as table_name" +
+		Raw("SELECT name as table_name, -1 as row_count " +
 			"FROM sqlite_master " +
 			"WHERE type = 'table'").
 		Find(&gormDBTables).Error
@@ -149,6 +164,7 @@ func (s SQLiteOperator) GetTablesUnderDB(ctx context.Context, dbName string) (db
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				}},
 			}
 		} else {
@@ -156,6 +172,7 @@ func (s SQLiteOperator) GetTablesUnderDB(ctx context.Context, dbName string) (db
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				})
 		}
 	}
@@ -198,8 +215,11 @@ func (s SQLiteOperator) GetColumns(ctx context.Context, dbName string) (dbTableC
 						tableInfo.TableName: {
 							TableName: tableInfo.TableName,
 							ColumnInfoList: []*dboperator.ColumnInfo{{
-								ColumnName: row.ColumnName,
-								DataType:   row.DataType,
+								ColumnName:      row.ColumnName,
+								DataType:        row.DataType,
+								IsNullable:      row.NotNull == 0,
+								DefaultValue:    row.DefaultValue,
+								OrdinalPosition: row.OrdinalPosition,
 							}},
 						},
 					}
@@ -207,21 +227,36 @@ func (s SQLiteOperator) GetColumns(ctx context.Context, dbName string) (dbTableC
 					dbTableColInfoMap[tableInfo.TableName] = &dboperator.TableColInfo{
 						TableName: tableInfo.TableName,
 						ColumnInfoList: []*dboperator.ColumnInfo{{
-							ColumnName: row.ColumnName,
-							DataType:   row.DataType,
+							ColumnName:      row.ColumnName,
+							DataType:        row.DataType,
+							IsNullable:      row.NotNull == 0,
+							DefaultValue:    row.DefaultValue,
+							OrdinalPosition: row.OrdinalPosition,
 						}},
 					}
 				} else {
 					tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-						ColumnName: row.ColumnName,
-						DataType:   row.DataType,
+						ColumnName:      row.ColumnName,
+						DataType:        row.DataType,
+						IsNullable:      row.NotNull == 0,
+						DefaultValue:    row.DefaultValue,
+						OrdinalPosition: row.OrdinalPosition,
 					})
 				}
 			}
 		}
 
 	}
-
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	for _, tableColMap := range dbTableColMap {
+		err = s.fillAutoIncrement(ctx, db, tableColMap)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -254,19 +289,109 @@ func (s SQLiteOperator) GetColumnsUnderTables(ctx context.Context, dbName, schem
 				tableColMap[table] = &dboperator.TableColInfo{
 					TableName: table,
 					ColumnInfoList: []*dboperator.ColumnInfo{{
-						ColumnName: row.ColumnName,
-						DataType:   row.DataType,
+						ColumnName:      row.ColumnName,
+						DataType:        row.DataType,
+						IsNullable:      row.NotNull == 0,
+						DefaultValue:    row.DefaultValue,
+						OrdinalPosition: row.OrdinalPosition,
 					}},
 				}
 			} else {
 				tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-					ColumnName: row.ColumnName,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					DataType:        row.DataType,
+					IsNullable:      row.NotNull == 0,
+					DefaultValue:    row.DefaultValue,
+					OrdinalPosition: row.OrdinalPosition,
 				})
 			}
 		}
 	}
+	err = s.fillAutoIncrement(ctx, db, tableColMap)
+	return
+}
+
+// fillAutoIncrement 标记 integer primary key autoincrement 列，下一个值取自sqlite_sequence
+func (s SQLiteOperator) fillAutoIncrement(ctx context.Context, db *dbx.DBWrapper, tableColMap map[string]*dboperator.TableColInfo) (err error) {
+	autoIncrements := make([]*dboperator.TableAutoIncrement, 0)
+	for _, table := range utils.SortedKeys(tableColMap) {
+		var tableSQL string
+		err = db.DB.WithContext(ctx).
+			Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).
+			Scan(&tableSQL).Error
+		if err != nil {
+			return
+		}
+		columnName := parseAutoIncrementColumn(tableSQL)
+		if columnName == "" {
+			continue
+		}
+		seqs := make([]int64, 0)
+		err = db.DB.WithContext(ctx).
+			Raw("SELECT seq FROM sqlite_sequence WHERE name = ?", table).
+			Scan(&seqs).Error
+		if err != nil {
+			return
+		}
+		autoIncrement := &dboperator.TableAutoIncrement{TableName: table, ColumnName: columnName, NextValue: 1}
+		if len(seqs) > 0 {
+			autoIncrement.NextValue = seqs[0] + 1
+		}
+		autoIncrements = append(autoIncrements, autoIncrement)
+	}
+	dboperator.FillAutoIncrement(tableColMap, autoIncrements)
+	return
+}
+
+func (s SQLiteOperator) GetViewsUnderSchema(ctx context.Context, dbName string, schemas []string) (schemaViewMap map[string][]*dboperator.ViewInfo, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	defaultName := "sqlite_default"
+	gormDBViews := make([]*dboperator.GormDBView, 0)
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	err = db.DB.WithContext(ctx).
+		Raw("SELECT name as view_name, sql as definition " +
+			"FROM sqlite_master " +
+			"WHERE type = 'view' ORDER BY name").
+		Find(&gormDBViews).Error
+	if err != nil {
+		return
+	}
+	// sqlite_master中为完整的建视图语句
+	for _, view := range gormDBViews {
+		view.SchemaName = defaultName
+		view.Definition = dboperator.TrimCreateViewPrefix(view.Definition)
+	}
+	schemaViewMap = dboperator.GroupViews(gormDBViews)
+	return
+}
+
+// CreateViews sqlite不支持物化视图，创建为普通视图
+func (s SQLiteOperator) CreateViews(ctx context.Context, dbName, schemaName string, views []*dboperator.ViewInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderViews(ctx, schemaName, views))
+}
 
+func (s SQLiteOperator) RenderViews(ctx context.Context, schemaName string, views []*dboperator.ViewInfo) (statements []string) {
+	for _, view := range views {
+		if view.IsMaterialized {
+			log.GetLogger(ctx).Warn("sqlite does not support materialized view, create %s as view", view.ViewName)
+		}
+		statements = append(statements, fmt.Sprintf("create view if not exists %s as %s",
+			identifier.QualifiedName(schemaName, view.ViewName), identifier.QuoteExpression(view.Definition)))
+	}
 	return
 }
 
@@ -353,8 +478,202 @@ func (s SQLiteOperator) GetTableUniqueKeys(ctx context.Context, dbName string, s
 	return
 }
 
+func (s SQLiteOperator) GetTableIndexes(ctx context.Context, dbName string, schemaName string, tables []string) (indexInfo map[string][]*dboperator.IndexInfo, err error) {
+	if dbName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableIndexColumns := make([]*dboperator.TableIndexColumn, 0)
+	for _, table := range tables {
+		sqliteIndexes := make([]*dboperator.SQLiteIndex, 0)
+		err = db.DB.WithContext(ctx).
+			Raw("PRAGMA index_list(?)", table).
+			Find(&sqliteIndexes).Error
+		if err != nil {
+			return
+		}
+		sort.Slice(sqliteIndexes, func(i, j int) bool {
+			return sqliteIndexes[i].IndexName < sqliteIndexes[j].IndexName
+		})
+		for _, sqliteIndex := range sqliteIndexes {
+			if sqliteIndex.Origin != "c" {
+				continue
+			}
+			var indexSQL string
+			err = db.DB.WithContext(ctx).
+				Raw("SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?", sqliteIndex.IndexName).
+				Scan(&indexSQL).Error
+			if err != nil {
+				return
+			}
+			expressions, filter := parseIndexSQL(indexSQL)
+			sqliteIndexColumns := make([]*dboperator.SQLiteIndexColumn, 0)
+			err = db.DB.WithContext(ctx).
+				Raw("PRAGMA index_xinfo(?)", sqliteIndex.IndexName).
+				Find(&sqliteIndexColumns).Error
+			if err != nil {
+				return
+			}
+			for _, row := range sqliteIndexColumns {
+				if row.IsKey == 0 {
+					continue
+				}
+				indexColumn := &dboperator.TableIndexColumn{
+					SchemaName:     schemaName,
+					TableName:      table,
+					IndexName:      sqliteIndex.IndexName,
+					IsUnique:       sqliteIndex.IsUnique == 1,
+					ColumnName:     row.ColumnName,
+					IsDesc:         row.IsDesc == 1,
+					ColumnPosition: row.SeqNo,
+					Filter:         filter,
+				}
+				if row.Cid == -2 && row.SeqNo < len(expressions) {
+					indexColumn.Expression = expressions[row.SeqNo]
+				}
+				tableIndexColumns = append(tableIndexColumns, indexColumn)
+			}
+		}
+	}
+	indexInfo = dboperator.GroupIndexColumns(tableIndexColumns)
+	return
+}
+
+func (s SQLiteOperator) CreateIndexes(ctx context.Context, dbName, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderIndexes(ctx, schemaName, indexesMap))
+}
+
+func (s SQLiteOperator) RenderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (statements []string) {
+	indexNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.IndexNames(indexesMap))
+	for _, tableName := range utils.SortedKeys(indexesMap) {
+		for _, index := range indexesMap[tableName] {
+			columns := make([]string, 0, len(index.Columns))
+			for _, column := range index.Columns {
+				columnStr := utils.IsTrueOrNot(column.Expression != "", column.Expression, identifier.Name(column.ColumnName))
+				columns = append(columns, columnStr+utils.IsTrueOrNot(column.IsDesc, " desc", ""))
+			}
+			indexName := indexNames.Get(tableName, index.IndexName)
+			statements = append(statements, fmt.Sprintf("create %sindex if not exists %s on %s (%s)%s;",
+				utils.IsTrueOrNot(index.IsUnique, "unique ", ""), identifier.QualifiedName(schemaName, indexName),
+				identifier.Name(tableName), strings.Join(columns, ","),
+				utils.IsTrueOrNot(index.Filter != "", " where "+index.Filter, "")))
+		}
+	}
+	return
+}
+
+func (s SQLiteOperator) GetTableForeignKeys(ctx context.Context, dbName string, schemaName string, tables []string) (foreignKeyInfo map[string][]*dboperator.ForeignKeyInfo, err error) {
+	if dbName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableForeignKeyColumns := make([]*dboperator.TableForeignKeyColumn, 0)
+	for _, table := range tables {
+		sqliteForeignKeys := make([]*dboperator.SQLiteForeignKey, 0)
+		err = db.DB.WithContext(ctx).
+			Raw("PRAGMA foreign_key_list(?)", table).
+			Find(&sqliteForeignKeys).Error
+		if err != nil {
+			return
+		}
+		sort.Slice(sqliteForeignKeys, func(i, j int) bool {
+			if sqliteForeignKeys[i].ID != sqliteForeignKeys[j].ID {
+				return sqliteForeignKeys[i].ID < sqliteForeignKeys[j].ID
+			}
+			return sqliteForeignKeys[i].Seq < sqliteForeignKeys[j].Seq
+		})
+		for _, row := range sqliteForeignKeys {
+			refColumn := row.RefColumn
+			// 省略被引用列时引用的是被引用表的主键
+			if refColumn == "" {
+				refColumn, err = s.getPrimaryKeyColumn(ctx, db, row.RefTable, row.Seq)
+				if err != nil {
+					return
+				}
+			}
+			tableForeignKeyColumns = append(tableForeignKeyColumns, &dboperator.TableForeignKeyColumn{
+				SchemaName: schemaName,
+				TableName:  table,
+				// sqlite外键无名称，以表名及序号命名
+				ConstraintName: fmt.Sprintf("fk_%s_%d", table, row.ID),
+				ColumnName:     row.ColumnName,
+				RefSchemaName:  schemaName,
+				RefTableName:   row.RefTable,
+				RefColumnName:  refColumn,
+				OnDelete:       row.OnDelete,
+				OnUpdate:       row.OnUpdate,
+				ColumnPosition: row.Seq,
+			})
+		}
+	}
+	foreignKeyInfo = dboperator.GroupForeignKeyColumns(tableForeignKeyColumns)
+	return
+}
+
+// getPrimaryKeyColumn 查询主键中第seq列(从0开始)
+func (s SQLiteOperator) getPrimaryKeyColumn(ctx context.Context, db *dbx.DBWrapper, table string, seq int) (columnName string, err error) {
+	sqliteTableColumns := make([]*dboperator.SQLiteTableColumn, 0)
+	err = db.DB.WithContext(ctx).
+		Raw("PRAGMA table_info(?)", table).
+		Find(&sqliteTableColumns).Error
+	if err != nil {
+		return
+	}
+	for _, column := range sqliteTableColumns {
+		if int(column.PrimaryKey) == seq+1 {
+			columnName = column.ColumnName
+			return
+		}
+	}
+	return
+}
+
+func (s SQLiteOperator) GetTableCheckConstraints(ctx context.Context, dbName string, schemaName string, tables []string) (checkInfo map[string][]*dboperator.CheckInfo, err error) {
+	if dbName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	checkInfo = make(map[string][]*dboperator.CheckInfo)
+	for _, table := range tables {
+		var tableSQL string
+		err = db.DB.WithContext(ctx).
+			Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).
+			Scan(&tableSQL).Error
+		if err != nil {
+			return
+		}
+		// sqlite无约束字典，从建表语句中解析
+		for i, check := range parseCheckConstraints(tableSQL) {
+			if check.ConstraintName == "" {
+				check.ConstraintName = fmt.Sprintf("ck_%s_%d", table, i)
+			}
+			checkInfo[table] = append(checkInfo[table], check)
+		}
+	}
+	return
+}
+
+// ExecuteDDL sqlite不支持表及字段注释，tableCommentMap将被忽略；
+// sqlite不支持 alter table add constraint，外键在建表语句中声明，建表时不校验被引用表是否存在
 func (s SQLiteOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string,
-	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field) (ddlSQL string, err error) {
+	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (ddlSQL string, err error) {
 	if dbName == "" {
 		err = errors.New("empty dnName")
 		return
@@ -363,41 +682,260 @@ func (s SQLiteOperator) ExecuteDDL(ctx context.Context, dbName, schemaName strin
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderDDL(ctx, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap))
+}
 
-	//ddlSQL := ""
+func (s SQLiteOperator) RenderDDL(ctx context.Context, schemaName string, primaryKeysMap map[string][]string,
+	uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (statements []string) {
 	ddlTemplate := `
 create table if not exists %s (
 	%s
 );`
-	for tableName, fields := range tableFieldsMap {
+	uniqueNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.UniqueKeyNames(uniqueKeysMap))
+	checkNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.CheckNames(checksMap))
+	foreignKeyNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.ForeignKeyNames(foreignKeysMap))
+	for _, tableName := range dboperator.TableCreationOrder(ctx, tableFieldsMap, foreignKeysMap) {
+		fields := tableFieldsMap[tableName]
+		autoIncrementField := getAutoIncrementField(ctx, tableName, fields, primaryKeysMap[tableName])
 		var includeField string
 		for _, field := range fields {
 			if field == nil {
 				continue
 			}
+			if field == autoIncrementField {
+				includeField += fmt.Sprintf("	%s integer primary key autoincrement,", identifier.Name(field.ColumnName)) + fmt.Sprintln()
+				continue
+			}
 			dataType := s.Trans2DataType(field)
-			includeField += fmt.Sprintf("	%s %s,", utils.QuotaName(field.ColumnName), dataType) + fmt.Sprintln()
+			includeField += fmt.Sprintf("	%s %s%s,", identifier.Name(field.ColumnName), dataType, getColumnOption(field)) + fmt.Sprintln()
 		}
 		if len(primaryKeysMap) > 0 {
-			keys := primaryKeysMap[tableName]
-			for i, key := range keys {
-				keys[i] = utils.QuotaName(key)
+			keys := make([]string, 0, len(primaryKeysMap[tableName]))
+			for _, key := range primaryKeysMap[tableName] {
+				keys = append(keys, identifier.Name(key))
 			}
-			if len(keys) > 0 {
+			if len(keys) > 0 && autoIncrementField == nil {
 				includeField += fmt.Sprintf("	primary key (%s),", strings.Join(keys, ",")) + fmt.Sprintln()
 			}
 		}
+		uniqueKeys := uniqueKeysMap[tableName]
+		for _, uniqueName := range utils.SortedKeys(uniqueKeys) {
+			columns := make([]string, 0, len(uniqueKeys[uniqueName]))
+			for _, column := range uniqueKeys[uniqueName] {
+				columns = append(columns, identifier.Name(column))
+			}
+			if uniqueName == "" {
+				includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+				continue
+			}
+			includeField += fmt.Sprintf("	constraint %s unique (%s),", identifier.Name(uniqueNames.Get(tableName, uniqueName)), strings.Join(columns, ",")) + fmt.Sprintln()
+		}
+		for _, foreignKey := range foreignKeysMap[tableName] {
+			includeField += fmt.Sprintf("	%s,", getForeignKeyClause(foreignKeyNames.Get(tableName, foreignKey.ConstraintName), foreignKey)) + fmt.Sprintln()
+		}
+
+		for _, check := range checksMap[tableName] {
+			includeField += fmt.Sprintf("	constraint %s check (%s),", identifier.Name(checkNames.Get(tableName, check.ConstraintName)), identifier.QuoteExpression(check.Expression)) + fmt.Sprintln()
+		}
 
 		includeField = strings.TrimSpace(includeField)
 		includeField = strings.Trim(includeField, ",")
 
-		ddlStr := fmt.Sprintf(ddlTemplate, fmt.Sprintf("%s.%s", utils.QuotaName(schemaName), utils.QuotaName(tableName)), includeField)
-		ddlSQL += ddlStr + fmt.Sprintln()
+		statements = append(statements, fmt.Sprintf(ddlTemplate, identifier.QualifiedName(schemaName, tableName), includeField))
+		// 写入sqlite_sequence使自增从源库的下一个值开始，表已存在记录时不覆盖
+		if autoIncrementField != nil && autoIncrementField.AutoIncrementStart > 1 {
+			sequenceTable := identifier.QualifiedQuote(schemaName, "sqlite_sequence")
+			statements = append(statements, fmt.Sprintf("insert into %s (name, seq) select %s, %d where not exists (select 1 from %s where name = %s);",
+				sequenceTable, utils.QuotaString(identifier.Fold(tableName)), autoIncrementField.AutoIncrementStart-1,
+				sequenceTable, utils.QuotaString(identifier.Fold(tableName))))
+		}
 	}
+	return
+}
 
-	err = db.DB.WithContext(ctx).Exec(ddlSQL).Error
+func (s SQLiteOperator) ExecuteAlterations(ctx context.Context, dbName, schemaName string, alterations []*dboperator.TableAlteration) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderAlterations(ctx, schemaName, alterations))
+}
+
+// RenderAlterations sqlite的 alter table 仅支持新增、删除列(3.35.0起)，修改列及增删键需重建表，跳过并告警
+func (s SQLiteOperator) RenderAlterations(ctx context.Context, schemaName string, alterations []*dboperator.TableAlteration) (statements []string) {
+	for _, alteration := range alterations {
+		tableFullName := identifier.QualifiedQuote(schemaName, alteration.TableName)
+		switch alteration.Action {
+		case dboperator.AlterAddColumn:
+			statements = append(statements, fmt.Sprintf("alter table %s add column %s %s%s;", tableFullName, identifier.Quote(alteration.Field.ColumnName),
+				s.Trans2DataType(alteration.Field), getColumnOption(alteration.Field)))
+		case dboperator.AlterDropColumn:
+			statements = append(statements, fmt.Sprintf("alter table %s drop column %s;", tableFullName, identifier.Quote(alteration.ColumnName)))
+		default:
+			log.GetLogger(ctx).Warn("sqlite does not support %s on existing table %s, skip it: %s", alteration.Action, alteration.TableName, alteration.Reason)
+		}
+	}
+	return
+}
+
+// getAutoIncrementField sqlite仅 integer primary key 列可自增，自增列须为表的唯一主键列，否则忽略自增属性
+func getAutoIncrementField(ctx context.Context, tableName string, fields []*dboperator.Field, primaryKeys []string) *dboperator.Field {
+	for _, field := range fields {
+		if field == nil || !field.IsAutoIncrement {
+			continue
+		}
+		if len(primaryKeys) == 1 && strings.Trim(primaryKeys[0], `"`) == field.ColumnName {
+			return field
+		}
+		log.GetLogger(ctx).Warn("auto increment column %s.%s is not the only primary key column, autoincrement is ignored", tableName, field.ColumnName)
+	}
+	return nil
+}
+
+// getForeignKeyClause 生成建表语句中的外键声明，constraintName 为分配的外键名
+func getForeignKeyClause(constraintName string, foreignKey *dboperator.ForeignKeyInfo) string {
+	columns := make([]string, 0, len(foreignKey.Columns))
+	for _, column := range foreignKey.Columns {
+		columns = append(columns, identifier.Name(column))
+	}
+	refColumns := make([]string, 0, len(foreignKey.RefColumns))
+	for _, column := range foreignKey.RefColumns {
+		refColumns = append(refColumns, identifier.Name(column))
+	}
+	foreignKeySQL := fmt.Sprintf("constraint %s foreign key (%s) references %s (%s)", identifier.Name(constraintName),
+		strings.Join(columns, ","), identifier.Name(foreignKey.RefTableName), strings.Join(refColumns, ","))
+	if foreignKey.OnDelete != "" {
+		foreignKeySQL += " on delete " + strings.ToLower(foreignKey.OnDelete)
+	}
+	if foreignKey.OnUpdate != "" {
+		foreignKeySQL += " on update " + strings.ToLower(foreignKey.OnUpdate)
+	}
+	return foreignKeySQL
+}
+
+// parseIndexSQL 从建索引语句中解析各索引列的原始写法及部分索引条件
+func parseIndexSQL(indexSQL string) (expressions []string, filter string) {
+	lower := strings.ToLower(indexSQL)
+	onIndex := strings.Index(lower, " on ")
+	if onIndex == -1 {
+		return
+	}
+	start := strings.Index(indexSQL[onIndex:], "(")
+	if start == -1 {
+		return
+	}
+	start += onIndex
+	depth := 0
+	last := start + 1
+	for i := start; i < len(indexSQL); i++ {
+		switch indexSQL[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				expressions = append(expressions, trimIndexColumn(indexSQL[last:i]))
+				rest := strings.TrimSpace(indexSQL[i+1:])
+				if strings.HasPrefix(strings.ToLower(rest), "where ") {
+					filter = strings.TrimSpace(rest[len("where "):])
+				}
+				return
+			}
+		case ',':
+			if depth == 1 {
+				expressions = append(expressions, trimIndexColumn(indexSQL[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	return
+}
+
+// trimIndexColumn 去掉索引列的排序及排序规则修饰
+func trimIndexColumn(column string) string {
+	column = strings.TrimSpace(column)
+	lower := strings.ToLower(column)
+	for _, suffix := range []string{" asc", " desc"} {
+		if strings.HasSuffix(lower, suffix) {
+			column = strings.TrimSpace(column[:len(column)-len(suffix)])
+			lower = strings.ToLower(column)
+		}
+	}
+	if collateIndex := strings.LastIndex(lower, " collate "); collateIndex != -1 {
+		column = strings.TrimSpace(column[:collateIndex])
+	}
+	return column
+}
+
+// parseAutoIncrementColumn 从建表语句中解析 integer primary key autoincrement 列，不存在时返回空串
+func parseAutoIncrementColumn(tableSQL string) string {
+	matches := autoIncrementColumnReg.FindStringSubmatch(tableSQL)
+	if len(matches) != 2 {
+		return ""
+	}
+	return strings.Trim(matches[1], "\"`[]")
+}
+
+// parseCheckConstraints 从建表语句中解析检查约束，含列级约束，未命名的约束名称为空
+func parseCheckConstraints(tableSQL string) (checks []*dboperator.CheckInfo) {
+	words := make([]string, 0)
+	for i := 0; i < len(tableSQL); {
+		c := tableSQL[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closeChar := map[byte]byte{'\'': '\'', '"': '"', '`': '`', '[': ']'}[c]
+			end := strings.IndexByte(tableSQL[i+1:], closeChar)
+			if end == -1 {
+				return
+			}
+			words = append(words, tableSQL[i+1:i+1+end])
+			i += end + 2
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			end := i
+			for end < len(tableSQL) && (tableSQL[end] == '_' || tableSQL[end] >= 'a' && tableSQL[end] <= 'z' ||
+				tableSQL[end] >= 'A' && tableSQL[end] <= 'Z' || tableSQL[end] >= '0' && tableSQL[end] <= '9') {
+				end++
+			}
+			words = append(words, tableSQL[i:end])
+			i = end
+		case c == '(' && len(words) > 0 && strings.EqualFold(words[len(words)-1], "check"):
+			depth := 0
+			end := i
+			for ; end < len(tableSQL); end++ {
+				if tableSQL[end] == '\'' {
+					quotaEnd := strings.IndexByte(tableSQL[end+1:], '\'')
+					if quotaEnd == -1 {
+						return
+					}
+					end += quotaEnd + 1
+					continue
+				}
+				if tableSQL[end] == '(' {
+					depth++
+				} else if tableSQL[end] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if end >= len(tableSQL) {
+				return
+			}
+			check := &dboperator.CheckInfo{Expression: strings.TrimSpace(tableSQL[i+1 : end])}
+			if len(words) >= 3 && strings.EqualFold(words[len(words)-3], "constraint") {
+				check.ConstraintName = words[len(words)-2]
+			}
+			checks = append(checks, check)
+			words = words[:0]
+			i = end + 1
+		default:
+			i++
+		}
+	}
 	return
 }
@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIndexSQL(t *testing.T) {
+	expressions, filter := parseIndexSQL(`CREATE INDEX "idx_user" ON "user" (lower(name) DESC, "age" COLLATE NOCASE, substr(code, 1, 2)) WHERE age > 0`)
+	expected := []string{"lower(name)", `"age"`, "substr(code, 1, 2)"}
+	if !reflect.DeepEqual(expressions, expected) {
+		t.Errorf("expressions = %q, expected %q", expressions, expected)
+	}
+	if filter != "age > 0" {
+		t.Errorf("filter = %q, expected %q", filter, "age > 0")
+	}
+}
+
+func TestParseCheckConstraints(t *testing.T) {
+	checks := parseCheckConstraints(`CREATE TABLE "user" (
+	"age" integer check (age > 0),
+	"status" text,
+	constraint "ck_status" CHECK (status in ('a', 'b)'))
+)`)
+	if len(checks) != 2 {
+		t.Fatalf("len(checks) = %d, expected 2", len(checks))
+	}
+	if checks[0].ConstraintName != "" || checks[0].Expression != "age > 0" {
+		t.Errorf("checks[0] = %+v", *checks[0])
+	}
+	if checks[1].ConstraintName != "ck_status" || checks[1].Expression != "status in ('a', 'b)')" {
+		t.Errorf("checks[1] = %+v", *checks[1])
+	}
+}
+
+func TestParseAutoIncrementColumn(t *testing.T) {
+	cases := map[string]string{
+		`CREATE TABLE "user" ("user id" INTEGER PRIMARY KEY AUTOINCREMENT, name text)`: "user id",
+		"CREATE TABLE t (`id` integer primary key desc autoincrement)":                 "id",
+		`CREATE TABLE t (id integer primary key, name text)`:                           "",
+	}
+	for tableSQL, expected := range cases {
+		if actual := parseAutoIncrementColumn(tableSQL); actual != expected {
+			t.Errorf("parseAutoIncrementColumn(%q) = %q, expected %q", tableSQL, actual, expected)
+		}
+	}
+}
@@ -29,7 +29,7 @@ func (s SqlServerOperator) Trans2CommonField(dataType string) *dboperator.Field
 	}
 
 	switch typeStr {
-	case "char", "varchar", "nchar", "character", "nvarchar", "uniqueidentifier", "timestamp":
+	case "char", "varchar", "nchar", "character", "nvarchar", "timestamp":
 		// data_type: varchar、varchar(100)、etc...
 		field = *dboperator.StringField
 	case "date", "time", "smalldatetime", "datetime", "datetime2":
@@ -44,19 +44,23 @@ func (s SqlServerOperator) Trans2CommonField(dataType string) *dboperator.Field
 		default:
 			field.TimeType = "datetime"
 		}
-	case "ntext", "text", "xml":
+	case "ntext", "text":
 		field = *dboperator.StringField
 		field.IsText = true
 	case "tinyint":
 		field = *dboperator.Int8Field
+		field.IsUnsigned = true
 	case "smallint":
 		field = *dboperator.Int16Field
 	case "integer", "int":
 		field = *dboperator.Int32Field
 	case "bigint":
 		field = *dboperator.Int64Field
-	case "float", "real":
+	case "real":
 		field = *dboperator.Float32Field
+	case "float":
+		// float 默认为 float(53)，即双精度浮点数
+		field = *dboperator.Float64Field
 	case "numeric", "decimal", "money", "smallmoney":
 		field = *dboperator.Float64Field
 		field.IsFixedNumber = true
@@ -64,6 +68,13 @@ func (s SqlServerOperator) Trans2CommonField(dataType string) *dboperator.Field
 		field = *dboperator.BoolField
 	case "binary", "varbinary", "image":
 		field = *dboperator.BytesField
+	case "uniqueidentifier":
+		field = *dboperator.UUIDField
+	case "xml":
+		field = *dboperator.XMLField
+	case "geometry", "geography":
+		field = *dboperator.GeometryField
+		field.GeometryType, _ = dboperator.GeometryType(typeStr)
 	default:
 		log.DefaultLogger().Warn("handle with default sqlserver type:%s", dataType)
 		field = *dboperator.StringField
@@ -82,13 +93,20 @@ func (s SqlServerOperator) Trans2CommonField(dataType string) *dboperator.Field
 }
 
 func (s SqlServerOperator) Trans2DataType(field *dboperator.Field) string {
+	if field.TargetType != "" {
+		return field.TargetType
+	}
+	if field.Type != dboperator.INT8 {
+		field = dboperator.WidenUnsigned(field)
+	}
 	switch field.Type {
 	case dboperator.BYTES:
 		fallthrough
 	case dboperator.RUNES:
 		return "varbinary"
 	case dboperator.INT8:
-		return "tinyint"
+		// tinyint为无符号整数，有符号时扩大为smallint
+		return utils.IsTrueOrNot(field.IsUnsigned, "tinyint", "smallint")
 	case dboperator.INT16:
 		return "smallint"
 	case dboperator.INT32:
@@ -96,7 +114,7 @@ func (s SqlServerOperator) Trans2DataType(field *dboperator.Field) string {
 	case dboperator.INT64:
 		return "bigint"
 	case dboperator.FLOAT32:
-		return "float"
+		return "real"
 	case dboperator.FLOAT64:
 		if field.IsFixedNumber {
 			return fmt.Sprintf("decimal%s", getTypeSuffix(field))
@@ -122,6 +140,26 @@ func (s SqlServerOperator) Trans2DataType(field *dboperator.Field) string {
 			timeType = "datetime"
 		}
 		return timeType
+	case dboperator.JSON, dboperator.ARRAY:
+		// 无json类型，以json文本保存
+		return "nvarchar(max)"
+	case dboperator.UUID:
+		return "uniqueidentifier"
+	case dboperator.ENUM, dboperator.SET:
+		// ENUM的可选值由检查约束限定
+		if length := dboperator.EnumLength(field); length <= 4000 {
+			return fmt.Sprintf("nvarchar(%d)", length)
+		}
+		return "nvarchar(max)"
+	case dboperator.INTERVAL:
+		return "varchar(64)"
+	case dboperator.XML:
+		return "xml"
+	case dboperator.GEOMETRY:
+		if field.GeometryType == "geography" {
+			return "geography"
+		}
+		return "geometry"
 	default:
 		log.DefaultLogger().Warn("handle with to default sqlserver type:%s", field.Type)
 		return "text"
@@ -138,3 +176,43 @@ func getTypeSuffix(field *dboperator.Field) string {
 
 	return ""
 }
+
+// getColumnOption 生成字段排序规则、默认值(自增列为自增属性)、非空约束及枚举检查约束
+func getColumnOption(field *dboperator.Field) (option string) {
+	option = getCollateOption(field)
+	if field.IsAutoIncrement {
+		option += fmt.Sprintf(" identity(%d,1)", field.AutoIncrementStart)
+	} else if defaultValue := getDefaultValue(field); defaultValue != "" {
+		option += " default " + defaultValue
+	}
+	if !field.ISNullable {
+		option += " not null"
+	}
+	if expression := dboperator.EnumCheckExpression(field, identifier); expression != "" {
+		option += " check (" + expression + ")"
+	}
+	return
+}
+
+// getCollateOption 字段的排序规则，需紧跟数据类型
+func getCollateOption(field *dboperator.Field) string {
+	if field.Collation == "" {
+		return ""
+	}
+	return " collate " + field.Collation
+}
+
+// getDefaultValue 将通用默认值翻译为sqlserver写法
+func getDefaultValue(field *dboperator.Field) string {
+	switch field.DefaultValue {
+	case dboperator.DefaultTrue:
+		return "1"
+	case dboperator.DefaultFalse:
+		return "0"
+	case dboperator.DefaultCurrentDate:
+		return "CAST(GETDATE() AS DATE)"
+	case dboperator.DefaultCurrentTime:
+		return "CAST(GETDATE() AS TIME)"
+	}
+	return field.DefaultValue
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/jasonlabz/dbutil/dboperator"
 	"github.com/jasonlabz/dbutil/dbx"
+	"github.com/jasonlabz/dbutil/log"
 )
 
 func NewSqlserverOperator() dboperator.IOperator {
@@ -17,6 +18,9 @@ func NewSqlserverOperator() dboperator.IOperator {
 
 type SqlServerOperator struct{}
 
+// identifier 标识符规则，见 dboperator.GetIdentifierPolicy
+var identifier = dboperator.GetIdentifierPolicy(dbx.DBTypeSqlserver)
+
 func (s SqlServerOperator) GetDB(name string) (*dbx.DBWrapper, error) {
 	return dbx.GetDB(name)
 }
@@ -45,15 +49,19 @@ func (s SqlServerOperator) GetDataBySQL(ctx context.Context, dbName, sqlStatemen
 	return
 }
 
+func (s SqlServerOperator) GetSchema(ctx context.Context, dbName, schemaName string) (schema *dboperator.Schema, err error) {
+	return dboperator.InspectSchema(ctx, s, dbx.DBTypeSqlserver, dbName, schemaName)
+}
+
 func (s SqlServerOperator) GetTableData(ctx context.Context, dbName, schemaName, tableName string, pageInfo *dboperator.Pagination) (rows []map[string]interface{}, err error) {
 	rows = make([]map[string]interface{}, 0)
 	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
-	queryTable := fmt.Sprintf("\"%s\"", tableName)
+	queryTable := identifier.Quote(tableName)
 	if schemaName != "" {
-		queryTable = fmt.Sprintf("\"%s\".\"%s\"", schemaName, tableName)
+		queryTable = identifier.Quote(schemaName) + "." + queryTable
 	}
 	var count int64
 	tx := db.DB.WithContext(ctx).
@@ -87,12 +95,14 @@ func (s SqlServerOperator) GetTablesUnderSchema(ctx context.Context, dbName stri
 		Raw("select  " +
 			"a.name AS table_name, " +
 			"b.name as table_schema, " +
-			"CONVERT(NVARCHAR(100),isnull(c.[value],'-')) AS comments " +
+			"CONVERT(NVARCHAR(4000),isnull(c.[value],'')) AS comments, " +
+			"isnull((SELECT SUM(p.rows) FROM sys.partitions p WHERE p.object_id = a.object_id AND p.index_id IN (0, 1)), -1) AS row_count, " +
+			"CONVERT(NVARCHAR(128), DATABASEPROPERTYEX(DB_NAME(), 'Collation')) AS collation " +
 			"FROM sys.tables a " +
 			"LEFT JOIN sys.schemas b " +
 			"ON a.schema_id = b.schema_id " +
 			"LEFT JOIN sys.extended_properties c " +
-			"ON (a.object_id = c.major_id AND c.minor_id = 0) " +
+			"ON (a.object_id = c.major_id AND c.minor_id = 0 AND c.name = 'MS_Description') " +
 			"WHERE b.name IN (" + strings.Join(schemas, ",") + ") " +
 			"ORDER BY b.name,a.name").
 		Find(&gormDBTables)
@@ -106,6 +116,8 @@ func (s SqlServerOperator) GetTablesUnderSchema(ctx context.Context, dbName stri
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Collation: row.Collation,
 				}},
 			}
 		} else {
@@ -113,6 +125,8 @@ func (s SqlServerOperator) GetTablesUnderSchema(ctx context.Context, dbName stri
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
+					Collation: row.Collation,
 				})
 		}
 	}
@@ -134,12 +148,13 @@ func (s SqlServerOperator) GetTablesUnderDB(ctx context.Context, dbName string)
 		Raw("select  " +
 			"a.name AS table_name, " +
 			"b.name as table_schema, " +
-			"CONVERT(NVARCHAR(100),isnull(c.[value],'-')) AS comments " +
+			"CONVERT(NVARCHAR(4000),isnull(c.[value],'')) AS comments, " +
+			"isnull((SELECT SUM(p.rows) FROM sys.partitions p WHERE p.object_id = a.object_id AND p.index_id IN (0, 1)), -1) AS row_count " +
 			"FROM sys.tables a " +
 			"LEFT JOIN sys.schemas b " +
 			"ON a.schema_id = b.schema_id " +
 			"LEFT JOIN sys.extended_properties c " +
-			"ON (a.object_id = c.major_id AND c.minor_id = 0) " +
+			"ON (a.object_id = c.major_id AND c.minor_id = 0 AND c.name = 'MS_Description') " +
 			"WHERE b.name not like 'db_%' and  b.name NOT IN ('sys','INFORMATION_SCHEMA') " +
 			"ORDER BY b.name,a.name").
 		Find(&gormDBTables)
@@ -153,6 +168,7 @@ func (s SqlServerOperator) GetTablesUnderDB(ctx context.Context, dbName string)
 				TableInfoList: []*dboperator.TableInfo{{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				}},
 			}
 		} else {
@@ -160,6 +176,7 @@ func (s SqlServerOperator) GetTablesUnderDB(ctx context.Context, dbName string)
 				&dboperator.TableInfo{
 					TableName: row.TableName,
 					Comment:   row.Comments,
+					RowCount:  row.RowCount,
 				})
 		}
 	}
@@ -188,8 +205,16 @@ func (s SqlServerOperator) GetColumns(ctx context.Context, dbName string) (dbTab
 			"                DATA_TYPE + '(' + cast(NUMERIC_PRECISION as varchar)+','+cast(NUMERIC_SCALE as varchar) + ')' " +
 			"else " +
 			"    DATA_TYPE " +
-			"end  as data_type " +
-			"FROM INFORMATION_SCHEMA.Columns " +
+			"end  as data_type, " +
+			"case when IS_NULLABLE = 'YES' then 1 else 0 end as is_nullable, " +
+			"COLUMN_DEFAULT as column_default, " +
+			"ORDINAL_POSITION as ordinal_position, " +
+			"CONVERT(NVARCHAR(4000), ep.[value]) as comments " +
+			"FROM INFORMATION_SCHEMA.Columns ic " +
+			"LEFT JOIN sys.extended_properties ep " +
+			"ON ep.major_id = OBJECT_ID(QUOTENAME(ic.TABLE_SCHEMA) + '.' + QUOTENAME(ic.TABLE_NAME)) " +
+			"AND ep.minor_id = COLUMNPROPERTY(ep.major_id, ic.COLUMN_NAME, 'ColumnId') " +
+			"AND ep.name = 'MS_Description' " +
 			"WHERE TABLE_SCHEMA NOT IN ('sys','INFORMATION_SCHEMA') " +
 			"ORDER BY TABLE_NAME, COLUMN_NAME").
 		Find(&gormTableColumns)
@@ -203,9 +228,12 @@ func (s SqlServerOperator) GetColumns(ctx context.Context, dbName string) (dbTab
 				row.TableName: {
 					TableName: row.TableName,
 					ColumnInfoList: []*dboperator.ColumnInfo{{
-						ColumnName: row.ColumnName,
-						Comment:    row.Comments,
-						DataType:   row.DataType,
+						ColumnName:      row.ColumnName,
+						Comment:         row.Comments,
+						DataType:        row.DataType,
+						IsNullable:      row.IsNullable,
+						DefaultValue:    row.ColumnDefault,
+						OrdinalPosition: row.OrdinalPosition,
 					}},
 				},
 			}
@@ -213,19 +241,31 @@ func (s SqlServerOperator) GetColumns(ctx context.Context, dbName string) (dbTab
 			dbTableColInfoMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    row.ColumnDefault,
+					OrdinalPosition: row.OrdinalPosition,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    row.ColumnDefault,
+				OrdinalPosition: row.OrdinalPosition,
 			})
 		}
 	}
+	for schemaName, tableColMap := range dbTableColMap {
+		err = s.fillAutoIncrement(ctx, db, schemaName, tableColMap)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -257,8 +297,16 @@ func (s SqlServerOperator) GetColumnsUnderTables(ctx context.Context, dbName, lo
 			"else "+
 			"    DATA_TYPE "+
 			"end  as data_type, "+
-			"is_nullable "+
-			"FROM INFORMATION_SCHEMA.Columns "+
+			"case when IS_NULLABLE = 'YES' then 1 else 0 end as is_nullable, "+
+			"COLUMN_DEFAULT as column_default, "+
+			"ORDINAL_POSITION as ordinal_position, "+
+			"isnull(COLLATION_NAME, '') as collation, "+
+			"CONVERT(NVARCHAR(4000), ep.[value]) as comments "+
+			"FROM INFORMATION_SCHEMA.Columns ic "+
+			"LEFT JOIN sys.extended_properties ep "+
+			"ON ep.major_id = OBJECT_ID(QUOTENAME(ic.TABLE_SCHEMA) + '.' + QUOTENAME(ic.TABLE_NAME)) "+
+			"AND ep.minor_id = COLUMNPROPERTY(ep.major_id, ic.COLUMN_NAME, 'ColumnId') "+
+			"AND ep.name = 'MS_Description' "+
 			"WHERE TABLE_SCHEMA = ? "+
 			"AND TABLE_NAME IN ? "+
 			"ORDER BY TABLE_NAME, COLUMN_NAME", logicDBName, tableNames).
@@ -272,19 +320,110 @@ func (s SqlServerOperator) GetColumnsUnderTables(ctx context.Context, dbName, lo
 			tableColMap[row.TableName] = &dboperator.TableColInfo{
 				TableName: row.TableName,
 				ColumnInfoList: []*dboperator.ColumnInfo{{
-					ColumnName: row.ColumnName,
-					Comment:    row.Comments,
-					DataType:   row.DataType,
+					ColumnName:      row.ColumnName,
+					Comment:         row.Comments,
+					DataType:        row.DataType,
+					IsNullable:      row.IsNullable,
+					DefaultValue:    row.ColumnDefault,
+					OrdinalPosition: row.OrdinalPosition,
+					Collation:       row.Collation,
 				}},
 			}
 		} else {
 			tableColInfo.ColumnInfoList = append(tableColInfo.ColumnInfoList, &dboperator.ColumnInfo{
-				ColumnName: row.ColumnName,
-				Comment:    row.Comments,
-				DataType:   row.DataType,
+				ColumnName:      row.ColumnName,
+				Comment:         row.Comments,
+				DataType:        row.DataType,
+				IsNullable:      row.IsNullable,
+				DefaultValue:    row.ColumnDefault,
+				OrdinalPosition: row.OrdinalPosition,
+				Collation:       row.Collation,
 			})
 		}
 	}
+	err = s.fillAutoIncrement(ctx, db, logicDBName, tableColMap)
+	return
+}
+
+// fillAutoIncrement 标记identity列，下一个值为 当前值+步长，表中从未插入数据时为种子值
+func (s SqlServerOperator) fillAutoIncrement(ctx context.Context, db *dbx.DBWrapper, schemaName string, tableColMap map[string]*dboperator.TableColInfo) (err error) {
+	if len(tableColMap) == 0 {
+		return
+	}
+	autoIncrements := make([]*dboperator.TableAutoIncrement, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT t.name AS table_name, ic.name AS column_name,
+    COALESCE(CAST(ic.last_value AS bigint) + CAST(ic.increment_value AS bigint), CAST(ic.seed_value AS bigint)) AS next_value
+FROM sys.identity_columns ic
+    JOIN sys.tables t ON t.object_id = ic.object_id
+    JOIN sys.schemas sc ON sc.schema_id = t.schema_id
+WHERE sc.name = ? AND t.name IN ?`, schemaName, utils.SortedKeys(tableColMap)).
+		Scan(&autoIncrements).Error
+	if err != nil {
+		return
+	}
+	dboperator.FillAutoIncrement(tableColMap, autoIncrements)
+	return
+}
+
+func (s SqlServerOperator) GetViewsUnderSchema(ctx context.Context, dbName string, schemas []string) (schemaViewMap map[string][]*dboperator.ViewInfo, err error) {
+	if dbName == "" || len(schemas) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	gormDBViews := make([]*dboperator.GormDBView, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT
+    sc.name AS schema_name,
+    v.name AS view_name,
+    m.definition AS definition
+FROM
+    sys.views v
+JOIN
+    sys.schemas sc ON v.schema_id = sc.schema_id
+JOIN
+    sys.sql_modules m ON m.object_id = v.object_id
+WHERE
+    sc.name IN ?
+ORDER BY sc.name, v.name`, schemas).
+		Scan(&gormDBViews).Error
+	if err != nil {
+		return
+	}
+	// sql_modules中为完整的建视图语句
+	for _, view := range gormDBViews {
+		view.Definition = dboperator.TrimCreateViewPrefix(view.Definition)
+	}
+	schemaViewMap = dboperator.GroupViews(gormDBViews)
+	return
+}
+
+// CreateViews sqlserver的物化视图需借助索引视图实现，创建为普通视图
+func (s SqlServerOperator) CreateViews(ctx context.Context, dbName, schemaName string, views []*dboperator.ViewInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderViews(ctx, schemaName, views))
+}
+
+// RenderViews 视图已存在时跳过
+func (s SqlServerOperator) RenderViews(ctx context.Context, schemaName string, views []*dboperator.ViewInfo) (statements []string) {
+	for _, view := range views {
+		if view.IsMaterialized {
+			log.GetLogger(ctx).Warn("sqlserver does not support materialized view, create %s as view", view.ViewName)
+		}
+		// create view 须为批处理中的第一条语句，以exec执行
+		viewFullName := identifier.QualifiedName(schemaName, view.ViewName)
+		viewStr := fmt.Sprintf("create view %s as %s", viewFullName, identifier.QuoteExpression(view.Definition))
+		statements = append(statements, fmt.Sprintf("if object_id(N%s, N'V') is null exec(N%s)",
+			utils.QuotaString(viewFullName), utils.QuotaString(viewStr)))
+	}
 	return
 }
 
@@ -300,8 +439,8 @@ func (s SqlServerOperator) CreateSchema(ctx context.Context, dbName, schemaName,
 	if err != nil {
 		return
 	}
-	err = db.DB.WithContext(ctx).Exec(fmt.Sprintf(`IF NOT EXISTS (SELECT * FROM sys.schemas WHERE name = '%s')
-    EXEC sp_executesql N'CREATE SCHEMA %s'`, schemaName, schemaName)).Error
+	err = db.DB.WithContext(ctx).Exec(fmt.Sprintf(`IF NOT EXISTS (SELECT * FROM sys.schemas WHERE name = %s)
+    EXEC sp_executesql N%s`, utils.QuotaString(identifier.Fold(schemaName)), utils.QuotaString("CREATE SCHEMA "+identifier.Name(schemaName)))).Error
 	if err != nil {
 		return
 	}
@@ -400,7 +539,47 @@ WHERE
 	return
 }
 
-func (s SqlServerOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field) (ddlSQL string, err error) {
+func (s SqlServerOperator) GetTableIndexes(ctx context.Context, dbName string, schemaName string, tables []string) (indexInfo map[string][]*dboperator.IndexInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableIndexColumns := make([]*dboperator.TableIndexColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT
+    sc.name AS schema_name,
+    t.name AS table_name,
+    k.name AS index_name,
+    k.is_unique AS is_unique,
+    ic.key_ordinal AS column_position,
+    c.name AS column_name,
+    ic.is_descending_key AS is_desc,
+    ISNULL(k.filter_definition, '') AS filter
+FROM
+    sys.indexes k
+JOIN
+    sys.tables t ON k.object_id = t.object_id
+JOIN
+    sys.index_columns ic ON k.object_id = ic.object_id AND k.index_id = ic.index_id
+JOIN
+    sys.columns c ON ic.object_id = c.object_id AND c.column_id = ic.column_id
+JOIN
+    sys.schemas sc ON t.schema_id = sc.schema_id
+WHERE
+    k.is_primary_key = 0 AND k.is_unique_constraint = 0 AND k.type IN (1, 2) AND ic.is_included_column = 0
+    AND sc.name = ? AND t.name IN ?
+ORDER BY t.name, k.name, ic.key_ordinal`, schemaName, tables).
+		Scan(&tableIndexColumns).Error
+	if err != nil {
+		return
+	}
+	indexInfo = dboperator.GroupIndexColumns(tableIndexColumns)
+	return
+}
+
+func (s SqlServerOperator) CreateIndexes(ctx context.Context, dbName, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (ddlSQL string, err error) {
 	if dbName == "" {
 		err = errors.New("empty dnName")
 		return
@@ -409,26 +588,146 @@ func (s SqlServerOperator) ExecuteDDL(ctx context.Context, dbName, schemaName st
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderIndexes(ctx, schemaName, indexesMap))
+}
 
-	//ddlSQL := ""
+func (s SqlServerOperator) RenderIndexes(ctx context.Context, schemaName string, indexesMap map[string][]*dboperator.IndexInfo) (statements []string) {
+	indexTemplate := `
+if not exists (select * from sys.indexes where name = %s and object_id = object_id(N%s))
+create %sindex %s on %s (%s)%s;`
+	indexNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.IndexNames(indexesMap))
+	for _, tableName := range utils.SortedKeys(indexesMap) {
+		for _, index := range indexesMap[tableName] {
+			if index.IsFunctional() {
+				log.GetLogger(ctx).Warn("sqlserver does not support functional index, skip index %s on %s", index.IndexName, tableName)
+				continue
+			}
+			columns := make([]string, 0, len(index.Columns))
+			for _, column := range index.Columns {
+				columns = append(columns, identifier.Name(column.ColumnName)+utils.IsTrueOrNot(column.IsDesc, " desc", ""))
+			}
+			indexName := indexNames.Get(tableName, index.IndexName)
+			statements = append(statements, fmt.Sprintf(indexTemplate, utils.QuotaString(identifier.Fold(indexName)), utils.QuotaString(identifier.QualifiedName(schemaName, tableName)),
+				utils.IsTrueOrNot(index.IsUnique, "unique ", ""), identifier.Name(indexName),
+				identifier.QualifiedName(schemaName, tableName), strings.Join(columns, ","),
+				utils.IsTrueOrNot(index.Filter != "", " where "+index.Filter, "")))
+		}
+	}
+	return
+}
+
+func (s SqlServerOperator) GetTableForeignKeys(ctx context.Context, dbName string, schemaName string, tables []string) (foreignKeyInfo map[string][]*dboperator.ForeignKeyInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableForeignKeyColumns := make([]*dboperator.TableForeignKeyColumn, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT
+    sc.name AS schema_name,
+    t.name AS table_name,
+    fk.name AS constraint_name,
+    c.name AS column_name,
+    rsc.name AS ref_schema_name,
+    rt.name AS ref_table_name,
+    rc.name AS ref_column_name,
+    fkc.constraint_column_id AS column_position,
+    fk.delete_referential_action_desc AS on_delete,
+    fk.update_referential_action_desc AS on_update
+FROM
+    sys.foreign_keys fk
+JOIN
+    sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+JOIN
+    sys.tables t ON t.object_id = fk.parent_object_id
+JOIN
+    sys.schemas sc ON t.schema_id = sc.schema_id
+JOIN
+    sys.columns c ON c.object_id = fkc.parent_object_id AND c.column_id = fkc.parent_column_id
+JOIN
+    sys.tables rt ON rt.object_id = fk.referenced_object_id
+JOIN
+    sys.schemas rsc ON rt.schema_id = rsc.schema_id
+JOIN
+    sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+WHERE
+    sc.name = ? AND t.name IN ?
+ORDER BY t.name, fk.name, fkc.constraint_column_id`, schemaName, tables).
+		Scan(&tableForeignKeyColumns).Error
+	if err != nil {
+		return
+	}
+	foreignKeyInfo = dboperator.GroupForeignKeyColumns(tableForeignKeyColumns)
+	return
+}
+
+func (s SqlServerOperator) GetTableCheckConstraints(ctx context.Context, dbName string, schemaName string, tables []string) (checkInfo map[string][]*dboperator.CheckInfo, err error) {
+	if dbName == "" || schemaName == "" || len(tables) == 0 {
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	tableCheckConstraints := make([]*dboperator.TableCheckConstraint, 0)
+	err = db.DB.WithContext(ctx).Raw(`SELECT
+    sc.name AS schema_name,
+    t.name AS table_name,
+    cc.name AS constraint_name,
+    cc.definition AS expression
+FROM
+    sys.check_constraints cc
+JOIN
+    sys.tables t ON cc.parent_object_id = t.object_id
+JOIN
+    sys.schemas sc ON t.schema_id = sc.schema_id
+WHERE
+    cc.is_disabled = 0 AND sc.name = ? AND t.name IN ?
+ORDER BY t.name, cc.name`, schemaName, tables).
+		Scan(&tableCheckConstraints).Error
+	if err != nil {
+		return
+	}
+	checkInfo = dboperator.GroupCheckConstraints(tableCheckConstraints)
+	return
+}
+
+func (s SqlServerOperator) ExecuteDDL(ctx context.Context, dbName, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
+	if err != nil {
+		return
+	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderDDL(ctx, schemaName, primaryKeysMap, uniqueKeysMap, tableFieldsMap, tableCommentMap, foreignKeysMap, checksMap))
+}
+
+func (s SqlServerOperator) RenderDDL(ctx context.Context, schemaName string, primaryKeysMap map[string][]string, uniqueKeysMap map[string]map[string][]string, tableFieldsMap map[string][]*dboperator.Field, tableCommentMap map[string]string, foreignKeysMap map[string][]*dboperator.ForeignKeyInfo, checksMap map[string][]*dboperator.CheckInfo) (statements []string) {
 	ddlTemplate := `
-if not exists (select * from sysobjects where name = '%s' and xtype= 'U')
+if not exists (select * from sysobjects where name = %s and xtype= 'U')
 create table %s (
     %s
 );`
-	for tableName, fields := range tableFieldsMap {
+	uniqueNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.UniqueKeyNames(uniqueKeysMap))
+	checkNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.CheckNames(checksMap))
+	for _, tableName := range dboperator.TableCreationOrder(ctx, tableFieldsMap, foreignKeysMap) {
+		fields := tableFieldsMap[tableName]
 		var includeField string
 		for _, field := range fields {
 			if field == nil {
 				continue
 			}
 			dataType := s.Trans2DataType(field)
-			includeField += fmt.Sprintf("	%s %s,", utils.QuotaName(field.ColumnName), dataType) + fmt.Sprintln()
+			includeField += fmt.Sprintf("	%s %s%s,", identifier.Name(field.ColumnName), dataType, getColumnOption(field)) + fmt.Sprintln()
 		}
 		if len(primaryKeysMap) > 0 {
-			keys := primaryKeysMap[tableName]
-			for i, key := range keys {
-				keys[i] = utils.QuotaName(key)
+			keys := make([]string, 0, len(primaryKeysMap[tableName]))
+			for _, key := range primaryKeysMap[tableName] {
+				keys = append(keys, identifier.Name(key))
 			}
 			if len(keys) > 0 {
 				includeField += fmt.Sprintf("	primary key (%s),", strings.Join(keys, ",")) + fmt.Sprintln()
@@ -436,24 +735,127 @@ create table %s (
 		}
 		if len(uniqueKeysMap) > 0 {
 			uniqueKeys := uniqueKeysMap[tableName]
-			for _, columns := range uniqueKeys {
-				for i, column := range columns {
-					columns[i] = utils.QuotaName(column)
+			for _, uniqueName := range utils.SortedKeys(uniqueKeys) {
+				uniqueColumns := uniqueKeys[uniqueName]
+				columns := make([]string, 0, len(uniqueColumns))
+				for _, column := range uniqueColumns {
+					columns = append(columns, identifier.Name(column))
 				}
-				includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+				if uniqueName == "" {
+					includeField += fmt.Sprintf("	unique (%s),", strings.Join(columns, ",")) + fmt.Sprintln()
+					continue
+				}
+				constraintName := uniqueNames.Get(tableName, uniqueName)
+				includeField += fmt.Sprintf("	constraint %s unique (%s),", identifier.Name(constraintName), strings.Join(columns, ",")) + fmt.Sprintln()
 			}
 		}
 
+		for _, check := range checksMap[tableName] {
+			checkName := checkNames.Get(tableName, check.ConstraintName)
+			includeField += fmt.Sprintf("	constraint %s check (%s),", identifier.Name(checkName), identifier.QuoteExpression(check.Expression)) + fmt.Sprintln()
+		}
+
 		includeField = strings.TrimSpace(includeField)
 		includeField = strings.Trim(includeField, ",")
 
-		ddlStr := fmt.Sprintf(ddlTemplate, tableName, fmt.Sprintf("%s.%s", utils.QuotaName(schemaName), utils.QuotaName(tableName)), includeField)
-		ddlSQL += ddlStr + fmt.Sprintln()
+		statements = append(statements, fmt.Sprintf(ddlTemplate, utils.QuotaString(identifier.Fold(tableName)), identifier.QualifiedName(schemaName, tableName), includeField))
+		statements = append(statements, getCommentDDL(schemaName, tableName, tableCommentMap[tableName], fields)...)
 	}
+	foreignKeyNames := dboperator.AssignObjectNames(identifier, make(map[string]bool), dboperator.ForeignKeyNames(foreignKeysMap))
+	for _, tableName := range utils.SortedKeys(foreignKeysMap) {
+		if _, ok := tableFieldsMap[tableName]; !ok {
+			continue
+		}
+		for _, foreignKey := range foreignKeysMap[tableName] {
+			constraintName := foreignKeyNames.Get(tableName, foreignKey.ConstraintName)
+			statements = append(statements, getForeignKeyDDL(schemaName, tableName, constraintName, foreignKey))
+		}
+	}
+	return
+}
 
-	err = db.DB.WithContext(ctx).Exec(ddlSQL).Error
+func (s SqlServerOperator) ExecuteAlterations(ctx context.Context, dbName, schemaName string, alterations []*dboperator.TableAlteration) (ddlSQL string, err error) {
+	if dbName == "" {
+		err = errors.New("empty dnName")
+		return
+	}
+	db, err := dbx.GetDB(dbName)
 	if err != nil {
 		return
 	}
+	return dboperator.ExecuteStatements(ctx, db, s.RenderAlterations(ctx, schemaName, alterations))
+}
+
+// RenderAlterations alter column 未指定 null 时默认可空，故总是指定；主键名由系统生成，删除时拼接动态SQL
+func (s SqlServerOperator) RenderAlterations(ctx context.Context, schemaName string, alterations []*dboperator.TableAlteration) (statements []string) {
+	for _, alteration := range alterations {
+		tableFullName := identifier.QualifiedQuote(schemaName, alteration.TableName)
+		template := "alter table " + tableFullName + " %s;"
+		switch alteration.Action {
+		case dboperator.AlterAddColumn:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add %s %s%s", identifier.Quote(alteration.Field.ColumnName),
+				s.Trans2DataType(alteration.Field), getColumnOption(alteration.Field))))
+		case dboperator.AlterDropColumn:
+			statements = append(statements, fmt.Sprintf(template, "drop column "+identifier.Quote(alteration.ColumnName)))
+		case dboperator.AlterModifyColumn:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("alter column %s %s%s%s", identifier.Quote(alteration.Field.ColumnName),
+				s.Trans2DataType(alteration.Field), getCollateOption(alteration.Field), utils.IsTrueOrNot(alteration.Field.ISNullable, " null", " not null"))))
+		case dboperator.AlterAddPrimaryKey:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add primary key (%s)", identifier.QuoteList(alteration.Columns))))
+		case dboperator.AlterDropPrimaryKey:
+			statements = append(statements, fmt.Sprintf(`declare @sql nvarchar(max);
+select @sql = N%s + quotename(name) from sys.key_constraints where type = 'PK' and parent_object_id = object_id(N%s);
+if @sql is not null exec sp_executesql @sql;`, utils.QuotaString("alter table "+tableFullName+" drop constraint "), utils.QuotaString(tableFullName)))
+		case dboperator.AlterAddUnique:
+			statements = append(statements, fmt.Sprintf(template, fmt.Sprintf("add unique (%s)", identifier.QuoteList(alteration.Columns))))
+		case dboperator.AlterDropUnique:
+			statements = append(statements, fmt.Sprintf(template, "drop constraint "+identifier.Quote(alteration.ConstraintName)))
+		}
+	}
+	return
+}
+
+// getForeignKeyDDL 生成添加外键约束语句，已存在的外键不重复添加，sqlserver不支持restrict，按默认的no action处理
+func getForeignKeyDDL(schemaName, tableName, constraintName string, foreignKey *dboperator.ForeignKeyInfo) string {
+	columns := make([]string, 0, len(foreignKey.Columns))
+	for _, column := range foreignKey.Columns {
+		columns = append(columns, identifier.Name(column))
+	}
+	refColumns := make([]string, 0, len(foreignKey.RefColumns))
+	for _, column := range foreignKey.RefColumns {
+		refColumns = append(refColumns, identifier.Name(column))
+	}
+	foreignKeySQL := fmt.Sprintf(`if not exists (select * from sys.foreign_keys where name = N%s and schema_id = schema_id(N%s))
+alter table %s add constraint %s foreign key (%s) references %s (%s)`,
+		utils.QuotaString(identifier.Fold(constraintName)), utils.QuotaString(identifier.Fold(schemaName)),
+		identifier.QualifiedName(schemaName, tableName), identifier.Name(constraintName), strings.Join(columns, ","),
+		identifier.QualifiedName(schemaName, foreignKey.RefTableName), strings.Join(refColumns, ","))
+	if foreignKey.OnDelete != "" && foreignKey.OnDelete != dboperator.ActionRestrict {
+		foreignKeySQL += " on delete " + strings.ToLower(foreignKey.OnDelete)
+	}
+	if foreignKey.OnUpdate != "" && foreignKey.OnUpdate != dboperator.ActionRestrict {
+		foreignKeySQL += " on update " + strings.ToLower(foreignKey.OnUpdate)
+	}
+	return foreignKeySQL + ";"
+}
+
+// getCommentDDL 通过扩展属性MS_Description生成表及字段注释语句，已存在的注释不重复添加
+func getCommentDDL(schemaName, tableName, tableComment string, fields []*dboperator.Field) (commentSQLs []string) {
+	objectID := fmt.Sprintf("object_id(N%s)", utils.QuotaString(identifier.QualifiedName(schemaName, tableName)))
+	schemaName, tableName = identifier.Fold(schemaName), identifier.Fold(tableName)
+	if tableComment != "" {
+		commentSQLs = append(commentSQLs, fmt.Sprintf(`if not exists (select * from sys.extended_properties where major_id = %s and minor_id = 0 and name = N'MS_Description')
+exec sp_addextendedproperty N'MS_Description', N%s, N'SCHEMA', N%s, N'TABLE', N%s;`,
+			objectID, utils.QuotaString(tableComment), utils.QuotaString(schemaName), utils.QuotaString(tableName)))
+	}
+	for _, field := range fields {
+		if field == nil || field.Comment == "" {
+			continue
+		}
+		commentSQLs = append(commentSQLs, fmt.Sprintf(`if not exists (select * from sys.extended_properties where major_id = %s and minor_id = columnproperty(%s, N%s, 'ColumnId') and name = N'MS_Description')
+exec sp_addextendedproperty N'MS_Description', N%s, N'SCHEMA', N%s, N'TABLE', N%s, N'COLUMN', N%s;`,
+			objectID, objectID, utils.QuotaString(identifier.Fold(field.ColumnName)), utils.QuotaString(field.Comment),
+			utils.QuotaString(schemaName), utils.QuotaString(tableName), utils.QuotaString(identifier.Fold(field.ColumnName))))
+	}
 	return
 }
@@ -0,0 +1,108 @@
+package dboperator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexPatternPrefix 以此为前缀的模式按正则匹配，其余按通配符匹配
+const regexPatternPrefix = "re:"
+
+// TableFilter 表过滤条件，未配置的条件不参与过滤。
+// 模式默认为忽略大小写的通配符，如 tmp_*；以 re: 开头时为正则，如 re:^t_\d+$，需完整匹配时自行加 ^$
+type TableFilter struct {
+	Include        []string `json:"include" yaml:"include"`                 // 表名模式，命中任一即包含，为空时包含全部表
+	Exclude        []string `json:"exclude" yaml:"exclude"`                 // 表名模式，命中任一即排除，优先于 Include
+	IncludeComment []string `json:"include_comment" yaml:"include_comment"` // 表注释模式，命中任一即包含
+	ExcludeComment []string `json:"exclude_comment" yaml:"exclude_comment"` // 表注释模式，命中任一即排除
+	MinRows        *int64   `json:"min_rows" yaml:"min_rows"`               // 估算行数下限，行数未知的表不按行数过滤
+	MaxRows        *int64   `json:"max_rows" yaml:"max_rows"`               // 估算行数上限
+
+	include, exclude, includeComment, excludeComment []*namePattern
+}
+
+type namePattern struct {
+	glob string
+	reg  *regexp.Regexp
+}
+
+// Compile 校验并编译模式，直接构造过滤条件时需先调用，datasource 中使用前会自动调用
+func (f *TableFilter) Compile() (err error) {
+	if f.include, err = compilePatterns(f.Include); err != nil {
+		return
+	}
+	if f.exclude, err = compilePatterns(f.Exclude); err != nil {
+		return
+	}
+	if f.includeComment, err = compilePatterns(f.IncludeComment); err != nil {
+		return
+	}
+	if f.excludeComment, err = compilePatterns(f.ExcludeComment); err != nil {
+		return
+	}
+	if f.MinRows != nil && f.MaxRows != nil && *f.MinRows > *f.MaxRows {
+		return fmt.Errorf("min_rows %d is greater than max_rows %d", *f.MinRows, *f.MaxRows)
+	}
+	return
+}
+
+// Match 表是否满足过滤条件，filter 为 nil 时总是满足
+func (f *TableFilter) Match(table *TableInfo) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !matchAnyPattern(f.include, table.TableName) {
+		return false
+	}
+	if matchAnyPattern(f.exclude, table.TableName) {
+		return false
+	}
+	if len(f.includeComment) > 0 && !matchAnyPattern(f.includeComment, table.Comment) {
+		return false
+	}
+	if matchAnyPattern(f.excludeComment, table.Comment) {
+		return false
+	}
+	if table.RowCount < 0 {
+		return true
+	}
+	return (f.MinRows == nil || table.RowCount >= *f.MinRows) && (f.MaxRows == nil || table.RowCount <= *f.MaxRows)
+}
+
+func compilePatterns(patterns []string) (compiled []*namePattern, err error) {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if expression, ok := strings.CutPrefix(pattern, regexPatternPrefix); ok {
+			reg, compileErr := regexp.Compile(expression)
+			if compileErr != nil {
+				return nil, fmt.Errorf("invalid regex %s: %w", pattern, compileErr)
+			}
+			compiled = append(compiled, &namePattern{reg: reg})
+			continue
+		}
+		if _, matchErr := path.Match(pattern, ""); matchErr != nil {
+			return nil, fmt.Errorf("invalid glob %s", pattern)
+		}
+		compiled = append(compiled, &namePattern{glob: pattern})
+	}
+	return
+}
+
+func matchAnyPattern(patterns []*namePattern, name string) bool {
+	for _, pattern := range patterns {
+		if pattern.reg != nil {
+			if pattern.reg.MatchString(name) {
+				return true
+			}
+			continue
+		}
+		if matchGlob(pattern.glob, name) {
+			return true
+		}
+	}
+	return false
+}
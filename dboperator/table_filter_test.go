@@ -0,0 +1,46 @@
+package dboperator
+
+import (
+	"testing"
+)
+
+func TestTableFilter(t *testing.T) {
+	minRows, maxRows := int64(10), int64(1000)
+	filter := &TableFilter{
+		Include:        []string{"t_*", `re:^order_\d+$`},
+		Exclude:        []string{"*_BAK", "re:^t_tmp"},
+		ExcludeComment: []string{"*废弃*"},
+		MinRows:        &minRows,
+		MaxRows:        &maxRows,
+	}
+	if err := filter.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		table    TableInfo
+		expected bool
+	}{
+		{TableInfo{TableName: "T_USER", RowCount: 100}, true},
+		{TableInfo{TableName: "order_2024", RowCount: 100}, true},
+		{TableInfo{TableName: "order_items", RowCount: 100}, false},
+		{TableInfo{TableName: "t_user_bak", RowCount: 100}, false},
+		{TableInfo{TableName: "t_tmp_user", RowCount: 100}, false},
+		{TableInfo{TableName: "t_role", Comment: "已废弃的角色表", RowCount: 100}, false},
+		{TableInfo{TableName: "t_log", RowCount: 5}, false},
+		{TableInfo{TableName: "t_log", RowCount: 5000}, false},
+		{TableInfo{TableName: "t_log", RowCount: -1}, true},
+	}
+	for _, c := range cases {
+		if matched := filter.Match(&c.table); matched != c.expected {
+			t.Errorf("Match(%s, %s, %d) = %v, expected %v", c.table.TableName, c.table.Comment, c.table.RowCount, matched, c.expected)
+		}
+	}
+
+	var empty *TableFilter
+	if !empty.Match(&TableInfo{TableName: "any"}) {
+		t.Error("nil filter should match every table")
+	}
+	if err := (&TableFilter{Include: []string{"re:("}}).Compile(); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
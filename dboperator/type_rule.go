@@ -0,0 +1,197 @@
+package dboperator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+var typeArgsReg = regexp.MustCompile(`\([^)]*\)`)
+
+// TypeRuleSet 类型映射规则集，按顺序匹配，命中第一条规则即止
+type TypeRuleSet struct {
+	Rules []*TypeRule `json:"rules" yaml:"rules"`
+}
+
+// TypeRule 类型映射规则，未配置的条件不参与匹配；配置了长度、精度或小数位范围时，源字段的该项未知(为0，
+// 如不带参数的 NUMBER、NUMBER(*))则不匹配，小数位随精度未知。
+// TargetType 为目标库字段类型，原样用于建表；Field 为通用字段覆盖，二者可同时配置
+type TypeRule struct {
+	SourceDBType dbx.DBType     `json:"source_db_type" yaml:"source_db_type"` // 源库类型
+	TargetDBType dbx.DBType     `json:"target_db_type" yaml:"target_db_type"` // 目标库类型
+	TypePattern  string         `json:"type_pattern" yaml:"type_pattern"`     // 源字段类型名正则，忽略大小写且完整匹配，类型名不含括号参数，如 number、int unsigned
+	MinLength    *int           `json:"min_length" yaml:"min_length"`         // 长度范围
+	MaxLength    *int           `json:"max_length" yaml:"max_length"`
+	MinPrecision *int           `json:"min_precision" yaml:"min_precision"` // 精度范围
+	MaxPrecision *int           `json:"max_precision" yaml:"max_precision"`
+	MinScale     *int           `json:"min_scale" yaml:"min_scale"` // 小数位范围
+	MaxScale     *int           `json:"max_scale" yaml:"max_scale"`
+	Schema       string         `json:"schema" yaml:"schema"` // 模式名通配，忽略大小写，如 app_*
+	Table        string         `json:"table" yaml:"table"`   // 表名通配
+	Column       string         `json:"column" yaml:"column"` // 列名通配
+	TargetType   string         `json:"target_type" yaml:"target_type"`
+	Field        *FieldOverride `json:"field" yaml:"field"`
+
+	typeReg *regexp.Regexp
+}
+
+// FieldOverride 通用字段覆盖，仅覆盖已配置的属性
+type FieldOverride struct {
+	Type          FieldType `json:"type" yaml:"type"`
+	IsText        *bool     `json:"is_text" yaml:"is_text"`
+	IsFixedNumber *bool     `json:"is_fixed_number" yaml:"is_fixed_number"`
+	IsUnsigned    *bool     `json:"is_unsigned" yaml:"is_unsigned"`
+	TimeType      string    `json:"time_type" yaml:"time_type"`
+	Length        *int      `json:"length" yaml:"length"`
+	Precision     *int      `json:"precision" yaml:"precision"`
+	Scale         *int      `json:"scale" yaml:"scale"`
+}
+
+// TypeRuleColumn 参与规则匹配的源字段
+type TypeRuleColumn struct {
+	SourceDBType dbx.DBType
+	TargetDBType dbx.DBType
+	SchemaName   string
+	TableName    string
+	ColumnName   string
+	DataType     string // 源字段类型，如 NUMBER(1)、varchar(100)
+}
+
+// LoadTypeRules 读取类型映射规则文件，.json 按json解析，其余按yaml解析
+func LoadTypeRules(filePath string) (ruleSet *TypeRuleSet, err error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return
+	}
+	ruleSet = &TypeRuleSet{}
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		err = sonic.Unmarshal(content, ruleSet)
+	} else {
+		err = yaml.Unmarshal(content, ruleSet)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = ruleSet.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Compile 校验规则并编译类型名正则，直接构造规则集时需先调用，datasource 中使用前会自动调用
+func (s *TypeRuleSet) Compile() (err error) {
+	for i, rule := range s.Rules {
+		if rule == nil {
+			return fmt.Errorf("type rule #%d is empty", i+1)
+		}
+		if rule.TargetType == "" && rule.Field == nil {
+			return fmt.Errorf("type rule #%d yields neither target_type nor field", i+1)
+		}
+		if rule.TypePattern != "" {
+			rule.typeReg, err = regexp.Compile(`(?i)^(?:` + rule.TypePattern + `)$`)
+			if err != nil {
+				return fmt.Errorf("type rule #%d: %w", i+1, err)
+			}
+		}
+		for _, pattern := range []string{rule.Schema, rule.Table, rule.Column} {
+			if _, matchErr := path.Match(pattern, ""); matchErr != nil {
+				return fmt.Errorf("type rule #%d: invalid glob %s", i+1, pattern)
+			}
+		}
+	}
+	return
+}
+
+// Apply 以第一条命中的规则覆盖字段，field 为源库类型映射出的通用字段，未命中时返回 nil
+func (s *TypeRuleSet) Apply(column *TypeRuleColumn, field *Field) (rule *TypeRule) {
+	if s == nil || field == nil {
+		return nil
+	}
+	for _, rule = range s.Rules {
+		if !rule.match(column, field) {
+			continue
+		}
+		if override := rule.Field; override != nil {
+			if override.Type != "" {
+				field.Type = override.Type
+			}
+			if override.IsText != nil {
+				field.IsText = *override.IsText
+			}
+			if override.IsFixedNumber != nil {
+				field.IsFixedNumber = *override.IsFixedNumber
+			}
+			if override.IsUnsigned != nil {
+				field.IsUnsigned = *override.IsUnsigned
+			}
+			if override.TimeType != "" {
+				field.TimeType = override.TimeType
+			}
+			if override.Length != nil {
+				field.Length = *override.Length
+			}
+			if override.Precision != nil {
+				field.Precision = *override.Precision
+			}
+			if override.Scale != nil {
+				field.Scale = *override.Scale
+			}
+		}
+		field.TargetType = rule.TargetType
+		return rule
+	}
+	return nil
+}
+
+func (r *TypeRule) match(column *TypeRuleColumn, field *Field) bool {
+	if r.SourceDBType != "" && r.SourceDBType != column.SourceDBType {
+		return false
+	}
+	if r.TargetDBType != "" && r.TargetDBType != column.TargetDBType {
+		return false
+	}
+	if r.TypePattern != "" {
+		if r.typeReg == nil {
+			return false
+		}
+		if !r.typeReg.MatchString(TypeName(column.DataType)) {
+			return false
+		}
+	}
+	if !inRange(field.Length, field.Length > 0, r.MinLength, r.MaxLength) ||
+		!inRange(field.Precision, field.Precision > 0, r.MinPrecision, r.MaxPrecision) ||
+		!inRange(field.Scale, field.Precision > 0, r.MinScale, r.MaxScale) {
+		return false
+	}
+	return matchGlob(r.Schema, column.SchemaName) && matchGlob(r.Table, column.TableName) && matchGlob(r.Column, column.ColumnName)
+}
+
+// TypeName 去掉字段类型中的括号参数并转小写，如 INTERVAL DAY(2) TO SECOND(6) 为 interval day to second
+func TypeName(dataType string) string {
+	return strings.Join(strings.Fields(strings.ToLower(typeArgsReg.ReplaceAllString(dataType, " "))), " ")
+}
+
+// inRange 未配置范围时总是满足，配置了范围而 value 未知时不满足
+func inRange(value int, known bool, min, max *int) bool {
+	if min == nil && max == nil {
+		return true
+	}
+	return known && (min == nil || value >= *min) && (max == nil || value <= *max)
+}
+
+func matchGlob(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return matched
+}
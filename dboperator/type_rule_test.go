@@ -0,0 +1,75 @@
+package dboperator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestTypeRuleSet(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(filePath, []byte(`
+rules:
+  - source_db_type: oracle
+    type_pattern: number
+    max_precision: 1
+    field:
+      type: bool
+  - source_db_type: oracle
+    target_db_type: postgres
+    type_pattern: varchar2
+    min_length: 4000
+    target_type: text
+  - table: order*
+    column: extra
+    target_type: jsonb
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ruleSet, err := LoadTypeRules(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	column := &TypeRuleColumn{SourceDBType: dbx.DBTypeOracle, TargetDBType: dbx.DBTypePostgres, TableName: "ORDERS", ColumnName: "FLAG", DataType: "NUMBER(1)"}
+	field := &Field{Type: INT32, Precision: 1}
+	if rule := ruleSet.Apply(column, field); rule == nil || field.Type != BOOL {
+		t.Errorf("NUMBER(1) => %s, expected bool", field.Type)
+	}
+
+	for _, dataType := range []string{"NUMBER", "NUMBER(*)"} {
+		column.DataType, column.ColumnName = dataType, "ID"
+		field = &Field{Type: FLOAT64, IsFixedNumber: true}
+		if rule := ruleSet.Apply(column, field); rule != nil || field.Type != FLOAT64 {
+			t.Errorf("%s with unknown precision => %s, expected float64", dataType, field.Type)
+		}
+	}
+
+	column.DataType, column.ColumnName = "VARCHAR2(4000)", "REMARK"
+	field = &Field{Type: STRING, Length: 4000}
+	if ruleSet.Apply(column, field); field.TargetType != "text" {
+		t.Errorf("VARCHAR2(4000) => %q, expected text", field.TargetType)
+	}
+	field = &Field{Type: STRING, Length: 100}
+	if rule := ruleSet.Apply(column, field); rule != nil {
+		t.Errorf("VARCHAR2(100) matched rule %+v", *rule)
+	}
+
+	column.DataType, column.ColumnName = "CLOB", "EXTRA"
+	field = &Field{Type: STRING, IsText: true}
+	if ruleSet.Apply(column, field); field.TargetType != "jsonb" {
+		t.Errorf("ORDERS.EXTRA => %q, expected jsonb", field.TargetType)
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	if name := TypeName("INTERVAL DAY(2) TO SECOND(6)"); name != "interval day to second" {
+		t.Errorf("TypeName = %q", name)
+	}
+	if name := TypeName("int(10) unsigned"); name != "int unsigned" {
+		t.Errorf("TypeName = %q", name)
+	}
+}
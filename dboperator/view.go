@@ -0,0 +1,190 @@
+package dboperator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+var createViewPrefixReg = regexp.MustCompile(`(?is)^\s*create\s+(or\s+(replace|alter)\s+)?view\s+.+?\s+as\s+`)
+
+// viewKeywords 视图定义中各数据库通用的关键字
+var viewKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AS": true, "DISTINCT": true,
+	"JOIN": true, "NATURAL": true, "USING": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true, "CROSS": true, "ON": true,
+	"AND": true, "OR": true, "NOT": true, "IN": true, "IS": true, "NULL": true, "LIKE": true, "BETWEEN": true, "ESCAPE": true,
+	"EXISTS": true, "ANY": true, "SOME": true, "ALL": true,
+	"GROUP": true, "BY": true, "HAVING": true, "ORDER": true, "ASC": true, "DESC": true,
+	"UNION": true, "INTERSECT": true, "EXCEPT": true, "WITH": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+}
+
+// viewUnportableWords 各数据库写法不一致的关键字，出现即视为不可移植
+var viewUnportableWords = map[string]bool{
+	"TOP": true, "LIMIT": true, "OFFSET": true, "FETCH": true, "ROWNUM": true, "ROWID": true,
+	"CONNECT": true, "PRIOR": true, "LEVEL": true, "DUAL": true, "SYSDATE": true,
+	"OPTION": true, "CHECK": true, "SCHEMABINDING": true, "LATERAL": true, "APPLY": true,
+	"PIVOT": true, "UNPIVOT": true, "INTERVAL": true, "TRUE": true, "FALSE": true, "MINUS": true,
+}
+
+// viewFunctions 各数据库写法一致的函数
+var viewFunctions = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+	"COALESCE": true, "NULLIF": true, "UPPER": true, "LOWER": true, "ABS": true,
+}
+
+// viewTableClauseEnds 结束 from 子句的关键字
+var viewTableClauseEnds = map[string]bool{
+	"WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true, "ON": true,
+	"UNION": true, "INTERSECT": true, "EXCEPT": true,
+}
+
+// TrimCreateViewPrefix 去掉视图定义中的 create view ... as 前缀，仅保留查询语句
+func TrimCreateViewPrefix(definition string) string {
+	return strings.TrimSpace(createViewPrefixReg.ReplaceAllString(definition, ""))
+}
+
+// GroupViews 将视图按模式汇总
+func GroupViews(rows []*GormDBView) (schemaViewMap map[string][]*ViewInfo) {
+	schemaViewMap = make(map[string][]*ViewInfo)
+	for _, row := range rows {
+		schemaViewMap[row.SchemaName] = append(schemaViewMap[row.SchemaName], &ViewInfo{
+			ViewName:       row.ViewName,
+			Definition:     row.Definition,
+			IsMaterialized: row.IsMaterialized,
+		})
+	}
+	return
+}
+
+// NormalizeViewDefinition 将视图查询归一化为通用写法：标识符按源库大小写规则折叠后加双引号，
+// 去掉源模式限定，被引用的表及视图改为目标模式限定。refTables 为引用的表或视图名，用于确定创建顺序。
+// 仅支持标准查询语法及少量通用函数，其余写法返回错误，由调用方告警
+func NormalizeViewDefinition(definition string, sourceDBType dbx.DBType, sourceSchema, targetSchema string) (normalized string, refTables []string, err error) {
+	definition = strings.TrimSpace(TrimCreateViewPrefix(definition))
+	definition = strings.TrimSpace(strings.TrimSuffix(definition, ";"))
+	tokens, err := tokenizeSQL(definition)
+	if err != nil {
+		return
+	}
+	tokens = dropTypeCasts(tokens, viewKeywords)
+	if len(tokens) == 0 {
+		err = errors.New("empty definition")
+		return
+	}
+
+	for i, token := range tokens {
+		switch token.kind {
+		case sqlTokenWord:
+			upper := strings.ToUpper(token.value)
+			if viewUnportableWords[upper] {
+				err = fmt.Errorf("unsupported keyword %s", token.value)
+				return
+			}
+			if i+1 < len(tokens) && tokens[i+1].value == "(" && !viewKeywords[upper] {
+				if !viewFunctions[upper] {
+					err = fmt.Errorf("unsupported function %s", token.value)
+					return
+				}
+				tokens[i].value = upper
+				continue
+			}
+			if viewKeywords[upper] {
+				tokens[i].value = upper
+				continue
+			}
+			tokens[i] = sqlToken{kind: sqlTokenIdent, value: foldIdentifier(token.value, sourceDBType)}
+		case sqlTokenSymbol:
+			switch token.value {
+			case "!=":
+				tokens[i].value = "<>"
+			case "::", "[", "]", "||", "%":
+				err = fmt.Errorf("unsupported symbol %s", token.value)
+				return
+			}
+		}
+	}
+	if tokens[0].value != "SELECT" && tokens[0].value != "WITH" {
+		err = errors.New("definition is not a query")
+		return
+	}
+
+	// 去掉源模式限定
+	unqualified := make([]sqlToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].kind == sqlTokenIdent && strings.EqualFold(tokens[i].value, sourceSchema) &&
+			i+2 < len(tokens) && tokens[i+1].value == "." && tokens[i+2].kind == sqlTokenIdent {
+			i++
+			continue
+		}
+		unqualified = append(unqualified, tokens[i])
+	}
+	tokens = unqualified
+
+	cteNames := make(map[string]bool)
+	for i := 1; i+2 < len(tokens); i++ {
+		if tokens[i].kind == sqlTokenIdent && tokens[i+1].value == "AS" && tokens[i+2].value == "(" &&
+			(tokens[i-1].value == "WITH" || tokens[i-1].value == ",") {
+			cteNames[tokens[i].value] = true
+		}
+	}
+
+	// 标记 from/join 后的表引用，改为目标模式限定
+	result := make([]sqlToken, 0, len(tokens))
+	depth := 0
+	inFromClause := map[int]bool{}
+	expectTable := false
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.value == "(":
+			expectTable = false
+			depth++
+		case token.value == ")":
+			inFromClause[depth] = false
+			depth--
+		case token.value == "FROM":
+			inFromClause[depth] = true
+			expectTable = true
+			result = append(result, token)
+			continue
+		case token.value == "JOIN":
+			expectTable = true
+			result = append(result, token)
+			continue
+		case token.value == "," && inFromClause[depth]:
+			expectTable = true
+			result = append(result, token)
+			continue
+		case viewTableClauseEnds[token.value]:
+			inFromClause[depth] = false
+		case expectTable && token.kind == sqlTokenIdent:
+			expectTable = false
+			if i+1 < len(tokens) && tokens[i+1].value == "." {
+				err = fmt.Errorf("table qualified by %s is not under schema %s", token.value, sourceSchema)
+				return
+			}
+			if !cteNames[token.value] {
+				refTables = append(refTables, token.value)
+				result = append(result, sqlToken{kind: sqlTokenIdent, value: targetSchema}, sqlToken{kind: sqlTokenSymbol, value: "."})
+			}
+		}
+		result = append(result, token)
+	}
+	normalized = renderSQLTokens(result, viewFunctions)
+	return
+}
+
+// foldIdentifier 按源库规则折叠未加引号的标识符，postgresql转小写，oracle/dm转大写
+func foldIdentifier(identifier string, dbType dbx.DBType) string {
+	switch dbType {
+	case dbx.DBTypePostgres:
+		return strings.ToLower(identifier)
+	case dbx.DBTypeOracle, dbx.DBTypeDM:
+		return strings.ToUpper(identifier)
+	}
+	return identifier
+}
@@ -0,0 +1,53 @@
+package dboperator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jasonlabz/dbutil/dbx"
+)
+
+func TestNormalizeViewDefinition(t *testing.T) {
+	cases := []struct {
+		definition string
+		dbType     dbx.DBType
+		expected   string
+		refTables  []string
+	}{
+		{
+			definition: " SELECT o.id,\n    count(*) AS cnt\n   FROM public.orders o\n     JOIN users u ON u.id = o.user_id\n  WHERE o.status::text = 'paid'::text\n  GROUP BY o.id;",
+			dbType:     dbx.DBTypePostgres,
+			expected:   `SELECT "o"."id", COUNT(*) AS "cnt" FROM "target"."orders" "o" JOIN "target"."users" "u" ON "u"."id" = "o"."user_id" WHERE "o"."status" = 'paid' GROUP BY "o"."id"`,
+			refTables:  []string{"orders", "users"},
+		},
+		{
+			definition: "select `public`.`orders`.`id` AS `id` from `public`.`orders`, `public`.`users`",
+			dbType:     dbx.DBTypeMySQL,
+			expected:   `SELECT "orders"."id" AS "id" FROM "target"."orders", "target"."users"`,
+			refTables:  []string{"orders", "users"},
+		},
+		{
+			definition: "CREATE VIEW v_paid AS WITH paid AS (SELECT id FROM ORDERS) SELECT id FROM paid",
+			dbType:     dbx.DBTypeOracle,
+			expected:   `WITH "PAID" AS (SELECT "ID" FROM "target"."ORDERS") SELECT "ID" FROM "PAID"`,
+			refTables:  []string{"ORDERS"},
+		},
+	}
+	for _, c := range cases {
+		normalized, refTables, err := NormalizeViewDefinition(c.definition, c.dbType, "public", "target")
+		if err != nil || normalized != c.expected || !reflect.DeepEqual(refTables, c.refTables) {
+			t.Errorf("NormalizeViewDefinition(%q) = %q, %v, %v, expected %q, %v", c.definition, normalized, refTables, err, c.expected, c.refTables)
+		}
+	}
+
+	for _, definition := range []string{
+		"select top 10 id from orders",
+		"select nvl(name, 'a') from users",
+		"select id from other.orders",
+		"select id from orders limit 10",
+	} {
+		if normalized, _, err := NormalizeViewDefinition(definition, dbx.DBTypeMySQL, "public", "target"); err == nil {
+			t.Errorf("NormalizeViewDefinition(%q) = %q, expected error", definition, normalized)
+		}
+	}
+}
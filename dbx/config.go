@@ -31,7 +31,7 @@ type Config struct {
 	LogMode         LogMode       `json:"log_mode"`           // 日志级别
 	SSLMode         string        `json:"ssl_mode"`
 	TimeZone        string        `json:"time_zone"`
-	Charset         string        `json:"charset"`
+	Charset         string        `json:"charset"` // 连接字符集，由 GenDSN 生成 mysql 的 charset、postgres 的 client_encoding 参数
 }
 
 // GetLogMode _
@@ -67,6 +67,14 @@ func (c *Config) GenDSN() (dsn string) {
 			return
 		}
 		c.DSN = fmt.Sprintf(dsnTemplate, c.User, c.Password, c.Host, c.Port, dbName)
+		if c.Charset != "" {
+			switch c.DBType {
+			case DBTypeMySQL:
+				c.DSN += "&charset=" + c.Charset
+			case DBTypePostgres:
+				c.DSN += " client_encoding=" + c.Charset
+			}
+		}
 	}
 	dsn = c.DSN
 	return
@@ -4,36 +4,137 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"os"
 
 	"github.com/bytedance/sonic"
 	"github.com/jasonlabz/dbutil/core/utils"
 	"github.com/jasonlabz/dbutil/datasource"
+	"github.com/jasonlabz/dbutil/dboperator"
+	"github.com/jasonlabz/dbutil/dboperator/mysql"
 	"github.com/jasonlabz/dbutil/dbx"
 	"github.com/jasonlabz/dbutil/log"
 )
 
 type inputParam struct {
-	Source       dbx.Config `json:"source"`       // 源库配置信息
-	Target       dbx.Config `json:"target"`       // 目标库配置信息
-	SourceSchema string     `json:"sourceSchema"` // 源库schema
-	TargetSchema string     `json:"targetSchema"` // 目标库schema
-	TableList    []string   `json:"tableList"`    // 源库目标表
+	Source              dbx.Config `json:"source"`              // 源库配置信息
+	Target              dbx.Config `json:"target"`              // 目标库配置信息
+	SourceSchema        string     `json:"sourceSchema"`        // 源库schema
+	TargetSchema        string     `json:"targetSchema"`        // 目标库schema
+	TableList           []string   `json:"tableList"`           // 源库目标表
+	IncludeTables       []string   `json:"includeTables"`       // 包含的表名模式，通配符或 re: 开头的正则
+	ExcludeTables       []string   `json:"excludeTables"`       // 排除的表名模式，优先于 includeTables
+	IncludeComments     []string   `json:"includeComments"`     // 包含的表注释模式
+	ExcludeComments     []string   `json:"excludeComments"`     // 排除的表注释模式
+	MinRows             *int64     `json:"minRows"`             // 估算行数下限
+	MaxRows             *int64     `json:"maxRows"`             // 估算行数上限
+	CreateViews         bool       `json:"createViews"`         // 是否在目标库创建视图，仅创建定义为通用SQL的视图
+	ViewList            []string   `json:"viewList"`            // 源库目标视图，为空时为模式下全部视图
+	TinyIntAsBool       *bool      `json:"tinyIntAsBool"`       // mysql源库是否将tinyint(1)视为布尔类型，默认是
+	TypeRuleFile        string     `json:"typeRuleFile"`        // 类型映射规则文件，yaml或json格式
+	ReportFile          string     `json:"reportFile"`          // 类型兼容性报告保存位置，json格式，默认不保存
+	IdentifierCase      string     `json:"identifierCase"`      // 目标库新建对象名称的大小写，lower、upper或preserve，默认保持源库大小写
+	AlwaysQuote         bool       `json:"alwaysQuote"`         // 目标库标识符是否总是加引号，默认仅在需要时加引号
+	MaxIdentifierLength int        `json:"maxIdentifierLength"` // 目标库标识符最大字节数，超出时截断并追加哈希，默认按目标库限制
+	MappingFile         string     `json:"mappingFile"`         // 表、列、约束名称对应关系保存位置，json格式，默认不保存
+	RenameFile          string     `json:"renameFile"`          // 表名、列名重命名规则文件，yaml或json格式
+	SnapshotFile        string     `json:"snapshotFile"`        // 源库模式快照保存位置，.json为json格式，其余为yaml格式，默认不保存
+	DiffFile            string     `json:"diffFile"`            // 源库与目标库模式差异保存位置，.json为json格式，其余为文本格式；配置时仅比较不建表
+	SourceSnapshot      string     `json:"sourceSnapshot"`      // 比较、仅生成脚本或生成模型时以该快照文件代替源库
+	TargetSnapshot      string     `json:"targetSnapshot"`      // 比较时以该快照文件代替目标库；仅生成脚本且迁移时作为目标库现状
+	SourceDDL           string     `json:"sourceDDL"`           // 以该DDL脚本文件代替源库生成表，脚本写法按源库dbType解析
+	ModelFile           string     `json:"modelFile"`           // 源库表对应的gorm模型保存位置；配置时仅生成模型不建表
+	ModelPackage        string     `json:"modelPackage"`        // gorm模型的包名，默认model
+	ModelNullStyle      string     `json:"modelNullStyle"`      // gorm模型可空列的写法，pointer或sql，默认pointer
+	ModelJSONTag        bool       `json:"modelJsonTag"`        // gorm模型是否生成json标签
+	JSONSchemaFile      string     `json:"jsonSchemaFile"`      // 源库表的JSON Schema保存位置，.json为json格式，其余为yaml格式；配置时仅导出不建表
+	OpenAPIFile         string     `json:"openAPIFile"`         // 源库表的OpenAPI组件模式保存位置，格式同上；配置时仅导出不建表
+	ProtoFile           string     `json:"protoFile"`           // 源库表的proto3消息保存位置；配置时仅导出不建表
+	ProtoPackage        string     `json:"protoPackage"`        // proto3的包名，默认不声明
+	ProtoGoPackage      string     `json:"protoGoPackage"`      // proto3的go_package选项，默认不声明
+	MarkdownFile        string     `json:"markdownFile"`        // 源库表的Markdown数据字典保存位置；配置时仅导出不建表
+	HTMLFile            string     `json:"htmlFile"`            // 源库表的HTML数据字典保存位置；配置时仅导出不建表
+	DiagramFile         string     `json:"diagramFile"`         // 源库表的ER图保存位置；配置时仅导出不建表
+	DiagramFormat       string     `json:"diagramFormat"`       // ER图格式，mermaid、plantuml或dot，默认按diagramFile扩展名推断
+	Migrate             bool       `json:"migrate"`             // 目标库已存在的表是否按差异生成alter语句修改，默认跳过已存在的表
+	AllowDestructive    bool       `json:"allowDestructive"`    // 迁移时是否执行删除列、删除键、收窄类型等可能丢失数据的修改，默认跳过并告警
+	DryRun              bool       `json:"dryRun"`              // 仅生成DDL脚本而不连接目标库，目标库只需配置dbType
+	DDLFile             string     `json:"ddlFile"`             // ddl语句保存位置，优先于 -p，仅生成脚本且均未配置时输出到标准输出
+}
+
+// generateOnly 是否仅由源库的表生成模型或导出结构定义，此时不建表
+func (i inputParam) generateOnly() bool {
+	return i.ModelFile != "" || i.exportOnly()
+}
+
+// exportOnly 是否导出JSON Schema、OpenAPI、proto3、数据字典或ER图
+func (i inputParam) exportOnly() bool {
+	return i.JSONSchemaFile != "" || i.OpenAPIFile != "" || i.ProtoFile != "" || i.MarkdownFile != "" || i.HTMLFile != "" || i.DiagramFile != ""
+}
+
+// sourceFromSnapshot 比较、仅生成脚本、生成模型或导出时是否以快照代替源库
+func (i inputParam) sourceFromSnapshot() bool {
+	return i.SourceSnapshot != "" && (i.DiffFile != "" || i.DryRun || i.generateOnly())
+}
+
+// sourceOffline 是否以快照或DDL脚本代替源库，此时无需源库连接信息
+func (i inputParam) sourceOffline() bool {
+	return i.sourceFromSnapshot() || i.SourceDDL != ""
 }
 
 func (i inputParam) validateParam() error {
-	if i.SourceSchema == "" {
+	// 以快照或DDL脚本代替的源库、比较模式下以快照代替的目标库、仅生成脚本时的目标库无需连接信息
+	sourceOffline := i.sourceOffline()
+	targetFromSnapshot := i.DiffFile != "" && i.TargetSnapshot != ""
+	targetUnused := i.generateOnly() && i.DiffFile == ""
+	if i.SourceDDL != "" {
+		switch {
+		case i.Source.DBType == "":
+			return errors.New("使用sourceDDL时请配置源库dbType")
+		case i.DiffFile != "":
+			return errors.New("比较时不支持sourceDDL，请使用sourceSnapshot")
+		case i.sourceFromSnapshot():
+			return errors.New("sourceDDL与sourceSnapshot不能同时配置")
+		case i.CreateViews && !i.generateOnly():
+			return errors.New("使用sourceDDL时不支持createViews")
+		}
+	}
+	if i.SourceSchema == "" && !sourceOffline {
 		return errors.New("请配置sourceSchema")
 	}
-	if i.TargetSchema == "" {
+	if i.TargetSchema == "" && !targetFromSnapshot && !targetUnused {
 		return errors.New("请配置targetSchema")
 	}
-	if i.Source.DSN == "" && i.Source.Host == "" {
+	if i.Source.DSN == "" && i.Source.Host == "" && !sourceOffline {
 		return errors.New("请配置源库DSN或者host")
 	}
-	if i.Target.DSN == "" && i.Target.Host == "" {
+	if i.Target.DSN == "" && i.Target.Host == "" && !targetFromSnapshot && !i.DryRun && !targetUnused {
 		return errors.New("请配置目标库DSN或者host")
 	}
+	if i.DryRun && i.Target.DBType == "" {
+		return errors.New("仅生成脚本时请配置目标库dbType")
+	}
+	switch dboperator.IdentifierCase(i.IdentifierCase) {
+	case "", dboperator.IdentifierCasePreserve, dboperator.IdentifierCaseLower, dboperator.IdentifierCaseUpper:
+	default:
+		return errors.New("identifierCase仅支持lower、upper或preserve")
+	}
+	switch dboperator.DiagramFormat(i.DiagramFormat) {
+	case "", dboperator.DiagramMermaid, dboperator.DiagramPlantUML, dboperator.DiagramDOT:
+	default:
+		return errors.New("diagramFormat仅支持mermaid、plantuml或dot")
+	}
+	switch dboperator.NullStyle(i.ModelNullStyle) {
+	case "", dboperator.NullStylePointer, dboperator.NullStyleSQL:
+	default:
+		return errors.New("modelNullStyle仅支持pointer或sql")
+	}
+	if i.AllowDestructive && !i.Migrate {
+		return errors.New("allowDestructive需同时配置migrate")
+	}
+	if i.MaxIdentifierLength < 0 || (i.MaxIdentifierLength > 0 && i.MaxIdentifierLength < 16) {
+		return errors.New("maxIdentifierLength不能小于16")
+	}
 	return nil
 }
 
@@ -71,20 +172,175 @@ func main() {
 		log.DefaultLogger().WithError(err).Fatal("解析参数失败")
 	}
 
-	ddlSQL, err := datasource.GenTable(ctx, paramStruct.Source, paramStruct.Target, paramStruct.SourceSchema, paramStruct.TargetSchema, paramStruct.TableList)
+	if paramStruct.TinyIntAsBool != nil {
+		mysql.TinyIntAsBool = *paramStruct.TinyIntAsBool
+	}
+
+	identifier := dboperator.GetIdentifierPolicy(paramStruct.Target.DBType)
+	if paramStruct.IdentifierCase != "" {
+		identifier.Case = dboperator.IdentifierCase(paramStruct.IdentifierCase)
+	}
+	identifier.AlwaysQuote = paramStruct.AlwaysQuote
+	if paramStruct.MaxIdentifierLength > 0 {
+		identifier.MaxLength = paramStruct.MaxIdentifierLength
+	}
+
+	options := &datasource.GenOptions{
+		ReportFile:       paramStruct.ReportFile,
+		MappingFile:      paramStruct.MappingFile,
+		Migrate:          paramStruct.Migrate,
+		AllowDestructive: paramStruct.AllowDestructive,
+		SourceDDL:        paramStruct.SourceDDL,
+	}
+	if paramStruct.TypeRuleFile != "" {
+		options.TypeRules, err = dboperator.LoadTypeRules(paramStruct.TypeRuleFile)
+		if err != nil {
+			log.DefaultLogger().WithError(err).Fatal("加载类型映射规则失败")
+		}
+	}
+	options.Filter = &dboperator.TableFilter{
+		Include:        paramStruct.IncludeTables,
+		Exclude:        paramStruct.ExcludeTables,
+		IncludeComment: paramStruct.IncludeComments,
+		ExcludeComment: paramStruct.ExcludeComments,
+		MinRows:        paramStruct.MinRows,
+		MaxRows:        paramStruct.MaxRows,
+	}
+	err = options.Filter.Compile()
+	if err != nil {
+		log.DefaultLogger().WithError(err).Fatal("解析表过滤条件失败")
+	}
+	if paramStruct.RenameFile != "" {
+		options.Rename, err = dboperator.LoadRenameSpec(paramStruct.RenameFile)
+		if err != nil {
+			log.DefaultLogger().WithError(err).Fatal("加载重命名规则失败")
+		}
+	}
+
+	if paramStruct.DiffFile != "" {
+		diff, diffErr := datasource.DiffSchema(ctx, paramStruct.Source, paramStruct.Target, paramStruct.SourceSchema, paramStruct.TargetSchema,
+			&datasource.DiffOptions{SourceSnapshot: paramStruct.SourceSnapshot, TargetSnapshot: paramStruct.TargetSnapshot})
+		if diffErr != nil {
+			log.DefaultLogger().WithError(diffErr).Fatal("diff schema error")
+		}
+		diffErr = diff.WriteFile(paramStruct.DiffFile)
+		if diffErr != nil {
+			log.DefaultLogger().WithError(diffErr).Fatal("write diff error")
+		}
+		return
+	}
+
+	if paramStruct.SnapshotFile != "" && !paramStruct.sourceFromSnapshot() {
+		var schema *dboperator.Schema
+		var snapshotErr error
+		if paramStruct.SourceDDL != "" {
+			schema, snapshotErr = dboperator.LoadDDL(paramStruct.SourceDDL, paramStruct.Source.DBType, paramStruct.SourceSchema)
+		} else {
+			schema, snapshotErr = datasource.SnapshotSchema(ctx, paramStruct.Source, paramStruct.SourceSchema)
+		}
+		if snapshotErr != nil {
+			log.DefaultLogger().WithError(snapshotErr).Fatal("snapshot schema error")
+		}
+		snapshotErr = schema.WriteFile(paramStruct.SnapshotFile)
+		if snapshotErr != nil {
+			log.DefaultLogger().WithError(snapshotErr).Fatal("write snapshot error")
+		}
+	}
+
+	if paramStruct.generateOnly() {
+		if paramStruct.sourceFromSnapshot() {
+			options.SourceSnapshot = paramStruct.SourceSnapshot
+		}
+		generateFiles(ctx, paramStruct, options)
+		return
+	}
+
+	if paramStruct.DDLFile != "" {
+		ddlSavePath = paramStruct.DDLFile
+	}
+	if paramStruct.DryRun {
+		if paramStruct.sourceFromSnapshot() {
+			options.SourceSnapshot = paramStruct.SourceSnapshot
+		}
+		options.TargetSnapshot = paramStruct.TargetSnapshot
+		ddlSQL, _, _, renderErr := datasource.RenderTable(ctx, paramStruct.Source, paramStruct.Target.DBType, paramStruct.SourceSchema, paramStruct.TargetSchema, paramStruct.TableList, options)
+		if renderErr != nil {
+			log.DefaultLogger().WithError(renderErr).Fatal("render table error")
+		}
+		if paramStruct.CreateViews {
+			viewSQL, viewErr := datasource.RenderView(ctx, paramStruct.Source, paramStruct.Target.DBType, paramStruct.SourceSchema, paramStruct.TargetSchema, paramStruct.ViewList, options)
+			if viewErr != nil {
+				log.DefaultLogger().WithError(viewErr).Fatal("render view error")
+			}
+			ddlSQL += viewSQL
+		}
+		if ddlSavePath == "" {
+			fmt.Print(ddlSQL)
+			return
+		}
+		saveDDL(ddlSavePath, ddlSQL)
+		return
+	}
+
+	ddlSQL, _, _, err := datasource.GenTable(ctx, paramStruct.Source, paramStruct.Target, paramStruct.SourceSchema, paramStruct.TargetSchema, paramStruct.TableList, options)
 	if err != nil {
 		log.DefaultLogger().WithError(err).Fatal("gen table error")
 	}
+	if paramStruct.CreateViews {
+		viewSQL, genErr := datasource.GenView(ctx, paramStruct.Source, paramStruct.Target, paramStruct.SourceSchema, paramStruct.TargetSchema, paramStruct.ViewList, options)
+		if genErr != nil {
+			log.DefaultLogger().WithError(genErr).Fatal("gen view error")
+		}
+		ddlSQL += viewSQL
+	}
 	//if ddlSavePath != "" && utils.IsExist(ddlSavePath) {
 	if ddlSavePath != "" {
-		f, openErr := os.OpenFile(ddlSavePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if openErr != nil {
-			log.DefaultLogger().WithError(openErr).Fatal("openErr error")
+		saveDDL(ddlSavePath, ddlSQL)
+	}
+}
+
+// generateFiles 由源库的表生成gorm模型，导出JSON Schema、OpenAPI、proto3、数据字典及ER图
+func generateFiles(ctx context.Context, paramStruct inputParam, options *datasource.GenOptions) {
+	if paramStruct.ModelFile != "" {
+		models, modelErr := datasource.GenModel(ctx, paramStruct.Source, paramStruct.SourceSchema, paramStruct.TableList, options, &dboperator.ModelOptions{
+			PackageName: paramStruct.ModelPackage,
+			NullStyle:   dboperator.NullStyle(paramStruct.ModelNullStyle),
+			JSONTag:     paramStruct.ModelJSONTag,
+		})
+		if modelErr != nil {
+			log.DefaultLogger().WithError(modelErr).Fatal("gen model error")
+		}
+		modelErr = os.WriteFile(paramStruct.ModelFile, models, 0644)
+		if modelErr != nil {
+			log.DefaultLogger().WithError(modelErr).Fatal("write model error")
 		}
-		defer f.Close()
-		_, writeErr := f.WriteString(ddlSQL)
-		if writeErr != nil {
-			log.DefaultLogger().WithError(writeErr).Fatal("writeErr error")
+	}
+	if paramStruct.exportOnly() {
+		exportErr := datasource.ExportSchema(ctx, paramStruct.Source, paramStruct.SourceSchema, paramStruct.TableList, options, &datasource.ExportOptions{
+			JSONSchemaFile: paramStruct.JSONSchemaFile,
+			OpenAPIFile:    paramStruct.OpenAPIFile,
+			ProtoFile:      paramStruct.ProtoFile,
+			ProtoOptions:   &dboperator.ProtoOptions{Package: paramStruct.ProtoPackage, GoPackage: paramStruct.ProtoGoPackage},
+			MarkdownFile:   paramStruct.MarkdownFile,
+			HTMLFile:       paramStruct.HTMLFile,
+			DiagramFile:    paramStruct.DiagramFile,
+			DiagramFormat:  dboperator.DiagramFormat(paramStruct.DiagramFormat),
+		})
+		if exportErr != nil {
+			log.DefaultLogger().WithError(exportErr).Fatal("export schema error")
 		}
 	}
 }
+
+// saveDDL 将ddl语句追加写入文件
+func saveDDL(ddlSavePath, ddlSQL string) {
+	f, openErr := os.OpenFile(ddlSavePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		log.DefaultLogger().WithError(openErr).Fatal("openErr error")
+	}
+	defer f.Close()
+	_, writeErr := f.WriteString(ddlSQL)
+	if writeErr != nil {
+		log.DefaultLogger().WithError(writeErr).Fatal("writeErr error")
+	}
+}